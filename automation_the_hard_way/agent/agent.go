@@ -11,6 +11,10 @@ import (
 var (
 	addr     = flag.String("addr", "localhost:8080", "address to listen on")
 	qotdAddr = flag.String("qotdAddr", ":17", "the addrss to run the qotd service on")
+	keep     = flag.Int("keepVersions", 3, "how many versions of each package to keep, including the current one")
+	keys     = flag.String("trustedKeys", "", "directory of PEM ed25519 public keys that packages must be signed with, defaults to ~/sa/trusted_keys/")
+	unsigned = flag.Bool("allowUnsigned", false, "allow installing packages that aren't signed, only for testing")
+	remote   = flag.Bool("allowRemoteExec", false, "allow running commands and writing files over the domain socket, requires -tags ds")
 )
 
 func main() {
@@ -22,6 +26,10 @@ func main() {
 		log.Fatalf("unable to create agent: %s", err)
 	}
 	agent.QOTDAddr = *qotdAddr
+	agent.KeepVersions = *keep
+	agent.TrustedKeys = *keys
+	agent.AllowUnsigned = *unsigned
+	agent.AllowRemoteExec = *remote
 	if err := agent.Start(); err != nil {
 		log.Fatalf("unable to start agent: %s", err)
 	}
@@ -0,0 +1,45 @@
+// Package auth provides helpers for getting SSH authorization to connect to remote machines.
+package auth
+
+import (
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Agent returns an ssh.AuthMethod that uses the keys in the SSH agent listening on
+// SSH_AUTH_SOCK.
+func Agent() (ssh.AuthMethod, error) {
+	conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+	if err != nil {
+		return nil, err
+	}
+
+	client := agent.NewClient(conn)
+	return ssh.PublicKeysCallback(client.Signers), nil
+}
+
+// PublicKey returns an ssh.AuthMethod that uses the private key stored in privateKeyFile.
+func PublicKey(privateKeyFile string) (ssh.AuthMethod, error) {
+	k, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.PublicKeys(signer), nil
+}
+
+// FromKeyFile returns PublicKey(keyFile) if keyFile is set, otherwise Agent().
+func FromKeyFile(keyFile string) (ssh.AuthMethod, error) {
+	if keyFile != "" {
+		return PublicKey(keyFile)
+	}
+	return Agent()
+}
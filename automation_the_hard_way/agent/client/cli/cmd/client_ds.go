@@ -0,0 +1,20 @@
+//go:build ds
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// newClient connects to the agent at endpoint over SSH.
+func newClient(endpoint string) (*client.Client, error) {
+	auth, err := getAuthFromFlags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH authorizaion: %w", err)
+	}
+	return client.New(endpoint, []ssh.AuthMethod{auth})
+}
@@ -0,0 +1,10 @@
+//go:build !ds
+
+package cmd
+
+import "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client"
+
+// newClient connects to the agent at endpoint.
+func newClient(endpoint string) (*client.Client, error) {
+	return client.New(endpoint)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client"
+
+	"github.com/spf13/cobra"
+)
+
+// genkeyCmd represents the genkey command
+var genkeyCmd = &cobra.Command{
+	Use:   "genkey [private key file] [public key file]",
+	Short: "Generates a key for signing packages",
+	Long: `Genkey writes a new ed25519 private key for signing packages with "install --sign_key" and its
+public key. Copy the public key into the trusted keys directory of each agent, ~/sa/trusted_keys/ by default.
+
+An usage example:
+	cli genkey ./sign.pem ./sign.pub
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			log.Println("Error: command must be 2 args, [private key file] [public key file]")
+			os.Exit(1)
+		}
+
+		priv, pub, err := client.GenerateKey()
+		if err != nil {
+			log.Println("Error: ", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(args[0], priv, 0600); err != nil {
+			log.Println("Error: could not write private key: ", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(args[1], pub, 0644); err != nil {
+			log.Println("Error: could not write public key: ", err)
+			os.Exit(1)
+		}
+		fmt.Println("Done")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(genkeyCmd)
+}
@@ -66,7 +66,8 @@ An usage example:
 			os.Exit(1)
 		}
 
-		b, err := os.ReadFile(args[2])
+		// The package is streamed from disk, so we only read it here for its digest.
+		digest, err := client.FileDigest(args[2])
 		if err != nil {
 			log.Println("Error: could not read package file: ", err)
 			os.Exit(1)
@@ -81,14 +82,26 @@ An usage example:
 			os.Exit(1)
 		}
 
-		err = c.Install(
-			context.Background(),
-			&msgs.InstallReq{
-				Name:    args[1],
-				Package: b,
-				Binary:  args[3],
-			},
-		)
+		req := &msgs.InstallReq{
+			Name:    args[1],
+			Version: installVersion,
+			SHA256:  digest,
+			Binary:  args[3],
+		}
+		if signKey != "" {
+			key, err := client.ReadPrivateKey(signKey)
+			if err != nil {
+				log.Println("Error: ", err)
+				os.Exit(1)
+			}
+			if err := client.Sign(req, key); err != nil {
+				log.Println("Error: ", err)
+				os.Exit(1)
+			}
+		}
+
+		err = c.Install(context.Background(), req, client.FromFile(args[2]), client.Progress(printProgress))
+		fmt.Fprintln(os.Stderr)
 		if err != nil {
 			log.Println("Error: ", err)
 			os.Exit(1)
@@ -99,6 +112,8 @@ An usage example:
 
 func init() {
 	rootCmd.AddCommand(installCmd)
+	installCmd.Flags().StringVar(&installVersion, "version", "", "the version of the package, defaults to the time of the install")
+	installCmd.Flags().StringVar(&signKey, "sign_key", "", "a PEM ed25519 private key file to sign the package with, see genkey")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list [remote endpoint]",
+	Short: "Lists the packages installed on a remote machine",
+	Long: `List shows the packages installed by the system agent and the state of their programs.
+
+An usage example:
+	cli list 22.47.60.3:22
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Println("Error: command must be 1 arg, [remote endpoint]")
+			os.Exit(1)
+		}
+
+		c, err := newClient(args[0])
+		if err != nil {
+			log.Println("Error: problem connecting to agent: ", err)
+			os.Exit(1)
+		}
+		defer c.Close()
+
+		pkgs, err := c.List(context.Background())
+		if err != nil {
+			log.Println("Error: ", err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tVERSION\tBINARY\tSTATE\tPID\tRESTARTS\tLAST EXIT\tVERSIONS")
+		for _, p := range pkgs {
+			state, pid, restarts, exit := "not running", "", "", ""
+			if p.Program != nil {
+				state = string(p.Program.State)
+				restarts = fmt.Sprint(p.Program.Restarts)
+				exit = fmt.Sprint(p.Program.LastExitCode)
+				if p.Program.PID != 0 {
+					pid = fmt.Sprint(p.Program.PID)
+				}
+			}
+			bin := strings.TrimSpace(p.Binary + " " + strings.Join(p.Args, " "))
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				p.Name, p.Version, bin, state, pid, restarts, exit, strings.Join(p.Versions, ","),
+			)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
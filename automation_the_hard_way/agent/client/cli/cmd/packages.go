@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client"
+
+	"github.com/spf13/cobra"
+)
+
+var stopCmd = packageCmd(
+	"stop",
+	"Stops the program of an installed package",
+	"Stop gracefully stops the program of a package. It stays installed and can be started again.",
+	(*client.Client).Stop,
+)
+
+var startCmd = packageCmd(
+	"start",
+	"Starts the program of an installed package",
+	"Start starts the program of a package that was stopped or exited.",
+	(*client.Client).Start,
+)
+
+var restartCmd = packageCmd(
+	"restart",
+	"Restarts the program of an installed package",
+	"Restart stops the program of a package, if it is running, and starts it again.",
+	(*client.Client).Restart,
+)
+
+var uninstallCmd = packageCmd(
+	"uninstall",
+	"Stops and removes an installed package",
+	"Uninstall stops the program of a package and removes the package from the machine.",
+	(*client.Client).Uninstall,
+)
+
+// packageCmd returns a command called name that calls op on the agent for a package.
+func packageCmd(name, short, long string, op func(*client.Client, context.Context, string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   name + " [remote endpoint] [package name]",
+		Short: short,
+		Long: fmt.Sprintf(`%s
+
+An usage example:
+	cli %s 22.47.60.3:22 helloworld
+`, long, name),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				log.Println("Error: command must be 2 args, [remote endpoint] [package name]")
+				os.Exit(1)
+			}
+
+			c, err := newClient(args[0])
+			if err != nil {
+				log.Println("Error: problem connecting to agent: ", err)
+				os.Exit(1)
+			}
+			defer c.Close()
+
+			if err := op(c, context.Background(), args[1]); err != nil {
+				log.Println("Error: ", err)
+				os.Exit(1)
+			}
+			fmt.Println("Done")
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd, startCmd, restartCmd, uninstallCmd)
+}
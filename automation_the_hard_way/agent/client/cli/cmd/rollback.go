@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [remote endpoint] [package name] [version(optional)]",
+	Short: "Switches an installed package back to an older version",
+	Long: `Rollback makes an older installed version of a package the current version. Without a version,
+this is the version installed before the current one. If the package's program is running, it is
+restarted with that version.
+
+An usage example:
+	cli rollback 22.47.60.3:22 helloworld
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 && len(args) != 3 {
+			log.Println("Error: command must be 2 or 3 args, [remote endpoint] [package name] [version(optional)]")
+			os.Exit(1)
+		}
+		version := ""
+		if len(args) == 3 {
+			version = args[2]
+		}
+
+		c, err := newClient(args[0])
+		if err != nil {
+			log.Println("Error: problem connecting to agent: ", err)
+			os.Exit(1)
+		}
+		defer c.Close()
+
+		v, err := c.Rollback(context.Background(), args[1], version)
+		if err != nil {
+			log.Println("Error: ", err)
+			os.Exit(1)
+		}
+		fmt.Println("Done, current version: ", v)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+}
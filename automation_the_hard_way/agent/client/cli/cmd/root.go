@@ -34,8 +34,24 @@ var (
 	cfgFile  string
 	endpoint string
 	keyFile  string
+
+	installVersion string
+	signKey        string
 )
 
+// lastProgress is the percentage printProgress last printed.
+var lastProgress int64 = -1
+
+// printProgress prints how much of a package has been uploaded on a single line.
+func printProgress(sent, total int64) {
+	pct := sent * 100 / max(total, 1)
+	if pct == lastProgress {
+		return
+	}
+	lastProgress = pct
+	fmt.Fprintf(os.Stderr, "\ruploaded %d/%d bytes (%d%%)", sent, total, pct)
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "cli",
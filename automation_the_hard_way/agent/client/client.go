@@ -10,10 +10,13 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"reflect"
 
 	"github.com/johnsiilver/serveonssh"
@@ -38,8 +41,23 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// Install installs a package on the remote machine and runs it.
-func (c *Client) Install(ctx context.Context, req *msgs.InstallReq) error {
+// Install installs a package on the remote machine and runs it. If req.SHA256 isn't set,
+// it is set to the digest of the package. Agents require packages to be signed, see Sign().
+// Large packages should be streamed from disk with the FromFile() option.
+func (c *Client) Install(ctx context.Context, req *msgs.InstallReq, options ...InstallOption) error {
+	opts := installOptions{chunkSize: defaultChunkSize}
+	for _, o := range options {
+		o(&opts)
+	}
+	if opts.file != "" {
+		return c.upload(ctx, req, opts)
+	}
+
+	if req.SHA256 == "" {
+		sum := sha256.Sum256(req.Package)
+		req.SHA256 = hex.EncodeToString(sum[:])
+	}
+
 	b, err := json.Marshal(req)
 	if err != nil {
 		return fmt.Errorf("had problem marshaling install request: %w", err)
@@ -72,3 +90,134 @@ func (c *Client) Install(ctx context.Context, req *msgs.InstallReq) error {
 	}
 	return nil
 }
+
+// Stop stops the program of the installed package name.
+func (c *Client) Stop(ctx context.Context, name string) error {
+	return c.packageOp(ctx, "stop", name)
+}
+
+// Start starts the program of the installed package name, which must not be running.
+func (c *Client) Start(ctx context.Context, name string) error {
+	return c.packageOp(ctx, "start", name)
+}
+
+// Restart restarts the program of the installed package name. If it isn't running, it is
+// started.
+func (c *Client) Restart(ctx context.Context, name string) error {
+	return c.packageOp(ctx, "restart", name)
+}
+
+// Uninstall stops the program of the installed package name and removes the package.
+func (c *Client) Uninstall(ctx context.Context, name string) error {
+	return c.packageOp(ctx, "uninstall", name)
+}
+
+// Rollback switches the installed package name back to version, or to the version that
+// was installed before the current one if version is empty. If the package's program is
+// running, it is restarted. It returns the version that is now current.
+func (c *Client) Rollback(ctx context.Context, name, version string) (string, error) {
+	resp := &msgs.RollbackResp{}
+	if err := c.call(ctx, "rollback", &msgs.RollbackReq{Name: name, Version: version}, resp); err != nil {
+		return "", err
+	}
+	if resp.ErrMsg != "" {
+		return "", fmt.Errorf("rollback failed: %s", resp.ErrMsg)
+	}
+	return resp.Version, nil
+}
+
+// List returns the packages installed on the remote machine.
+func (c *Client) List(ctx context.Context) ([]msgs.Package, error) {
+	resp := &msgs.ListResp{}
+	if err := c.get(ctx, "list", resp); err != nil {
+		return nil, err
+	}
+	if resp.ErrMsg != "" {
+		return nil, fmt.Errorf("list failed: %s", resp.ErrMsg)
+	}
+	return resp.Packages, nil
+}
+
+// packageOp calls method for the installed package name.
+func (c *Client) packageOp(ctx context.Context, method, name string) error {
+	resp := &msgs.PackageResp{}
+	if err := c.call(ctx, method, &msgs.PackageReq{Name: name}, resp); err != nil {
+		return err
+	}
+	if resp.ErrMsg != "" {
+		return fmt.Errorf("%s failed: %s", method, resp.ErrMsg)
+	}
+	return nil
+}
+
+// Programs returns the status of all the programs the agent is running.
+func (c *Client) Programs(ctx context.Context) ([]msgs.Program, error) {
+	resp := &msgs.ProgramsResp{}
+	if err := c.get(ctx, "programs", resp); err != nil {
+		return nil, err
+	}
+	if resp.ErrMsg != "" {
+		return nil, fmt.Errorf("programs failed: %s", resp.ErrMsg)
+	}
+	return resp.Programs, nil
+}
+
+// Program returns the status of the program the agent is running for package name.
+func (c *Client) Program(ctx context.Context, name string) (msgs.Program, error) {
+	resp := &msgs.ProgramResp{}
+	if err := c.get(ctx, "programs/"+url.PathEscape(name), resp); err != nil {
+		return msgs.Program{}, err
+	}
+	if resp.ErrMsg != "" {
+		return msgs.Program{}, fmt.Errorf("program failed: %s", resp.ErrMsg)
+	}
+	return resp.Program, nil
+}
+
+// call sends req to the /api/v1.0.0/<method> endpoint and decodes the reply into resp.
+// resp is decoded for any status code, as the agent returns ErrMsg in the body.
+func (c *Client) call(ctx context.Context, method string, req, resp any) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("had problem marshaling %s request: %w", method, err)
+	}
+
+	u := fmt.Sprintf("http://%s/api/v1.0.0/%s", c.endpoint, method)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("had problem creating http request for %s: %w", method, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	return c.do(method, httpReq, resp)
+}
+
+// get does a GET of the /api/v1.0.0/<path> endpoint and decodes the reply into resp.
+func (c *Client) get(ctx context.Context, path string, resp any) error {
+	u := fmt.Sprintf("http://%s/api/v1.0.0/%s", c.endpoint, path)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("had problem creating http request for %s: %w", path, err)
+	}
+	return c.do(path, httpReq, resp)
+}
+
+// do sends httpReq for method and decodes the reply into resp.
+func (c *Client) do(method string, httpReq *http.Request, resp any) error {
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("had problem with %s HTTP request: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	b, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("had problem reading %s HTTP response: %w", method, err)
+	}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return fmt.Errorf("had problem unmarshaling %s HTTP response(status %d): %w", method, httpResp.StatusCode, err)
+	}
+	return nil
+}
@@ -3,6 +3,9 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -10,6 +13,8 @@ import (
 
 	"github.com/johnsiilver/serveonssh"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
 )
 
 // New creates a new Client that connects to a remote endpoint via SSH and then
@@ -23,7 +28,12 @@ func New(endpoint string, auth []ssh.AuthMethod) (*Client, error) {
 		Timeout:         5 * time.Second,
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 	}
+	return NewWithConfig(endpoint, config)
+}
 
+// NewWithConfig is like New(), but uses config to connect with SSH. This allows
+// setting the user and how host keys are verified.
+func NewWithConfig(endpoint string, config *ssh.ClientConfig) (*Client, error) {
 	remoteSocket := filepath.Join("/home", config.User, "/sa/socket/sa.sock")
 
 	p, err := serveonssh.New(endpoint, remoteSocket, config)
@@ -39,3 +49,44 @@ func New(endpoint string, auth []ssh.AuthMethod) (*Client, error) {
 		p:        p,
 	}, nil
 }
+
+// Exec runs cmd with "/bin/sh -c" on the remote machine and returns the combined
+// stdout and stderr. If the command fails, the output is still returned. The agent
+// only allows this on its domain socket and when run with -allowRemoteExec, as do
+// WriteFile() and Stat().
+func (c *Client) Exec(ctx context.Context, cmd string) ([]byte, error) {
+	resp := &msgs.ExecResp{}
+	if err := c.call(ctx, "exec", &msgs.ExecReq{Cmd: cmd}, resp); err != nil {
+		return nil, err
+	}
+	if resp.ErrMsg != "" {
+		return resp.Output, fmt.Errorf("exec failed: %s", resp.ErrMsg)
+	}
+	return resp.Output, nil
+}
+
+// WriteFile writes content to the file at path on the remote machine. If the file
+// exists it is truncated, otherwise it is created with perm.
+func (c *Client) WriteFile(ctx context.Context, path string, content []byte, perm fs.FileMode) error {
+	req := &msgs.WriteFileReq{Path: path, Content: content, Mode: uint32(perm)}
+	resp := &msgs.WriteFileResp{}
+	if err := c.call(ctx, "writeFile", req, resp); err != nil {
+		return err
+	}
+	if resp.ErrMsg != "" {
+		return fmt.Errorf("writeFile failed: %s", resp.ErrMsg)
+	}
+	return nil
+}
+
+// Stat returns information about the file at path on the remote machine.
+func (c *Client) Stat(ctx context.Context, path string) (msgs.StatResp, error) {
+	resp := &msgs.StatResp{}
+	if err := c.call(ctx, "stat", &msgs.StatReq{Path: path}, resp); err != nil {
+		return msgs.StatResp{}, err
+	}
+	if resp.ErrMsg != "" {
+		return msgs.StatResp{}, fmt.Errorf("stat failed: %s", resp.ErrMsg)
+	}
+	return *resp, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// Sign signs req with key. The signature covers the SHA256 digest of the package and the
+// fields that say how it is run (see msgs.InstallReq.SignedBytes()), so all of them must
+// be set before calling Sign. If req.SHA256 isn't set, it is set to the digest of
+// req.Package. For a package in a file, set req.SHA256 with FileDigest() first. The agent
+// must have the public part of key in its trusted keys.
+func Sign(req *msgs.InstallReq, key ed25519.PrivateKey) error {
+	if req.SHA256 == "" {
+		sum := sha256.Sum256(req.Package)
+		req.SHA256 = hex.EncodeToString(sum[:])
+	}
+	sum, err := hex.DecodeString(req.SHA256)
+	if err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("req.SHA256(%s) is not a hex encoded SHA-256 digest", req.SHA256)
+	}
+	b, err := req.SignedBytes()
+	if err != nil {
+		return err
+	}
+	req.Signature = ed25519.Sign(key, b)
+	return nil
+}
+
+// FileDigest returns the hex encoded SHA-256 digest of the file at p.
+func FileDigest(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not read file(%s): %w", p, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadPrivateKey reads a PEM encoded ed25519 private key from the file at p, such as one
+// made by GenerateKey() or "openssl genpkey -algorithm ed25519".
+func ReadPrivateKey(p string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("file(%s) is not a PEM encoded private key", p)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("file(%s) has a bad private key: %w", p, err)
+	}
+	priv, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("file(%s) has a %T, not an ed25519 key", p, k)
+	}
+	return priv, nil
+}
+
+// GenerateKey makes a new ed25519 key for signing packages. It returns the PEM encoded
+// private key and public key. The public key goes in the agent's trusted keys.
+func GenerateKey() (priv, pub []byte, err error) {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	pub = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return priv, pub, nil
+}
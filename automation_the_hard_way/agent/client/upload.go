@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+const (
+	// defaultChunkSize is the size of the chunks a package is uploaded in.
+	defaultChunkSize = 8 * 1024 * 1024 // 8MB
+	// maxChunkTries is how many times we try to send a chunk before giving up.
+	maxChunkTries = 5
+)
+
+// InstallOption is an optional setting for Install().
+type InstallOption func(o *installOptions)
+
+type installOptions struct {
+	file      string
+	chunkSize int64
+	progress  func(sent, total int64)
+}
+
+// FromFile streams the package from the file at p instead of sending req.Package, which
+// should be empty. The package is uploaded in chunks, so it never has to be held in
+// memory, and chunks that fail are resumed from what the agent received.
+func FromFile(p string) InstallOption {
+	return func(o *installOptions) {
+		o.file = p
+	}
+}
+
+// ChunkSize sets the size of the chunks that FromFile() uploads. Defaults to 8MiB.
+func ChunkSize(n int64) InstallOption {
+	return func(o *installOptions) {
+		if n > 0 {
+			o.chunkSize = n
+		}
+	}
+}
+
+// Progress has Install() call f as the package is uploaded with how many bytes the agent
+// has and the size of the package. This is only used with FromFile().
+func Progress(f func(sent, total int64)) InstallOption {
+	return func(o *installOptions) {
+		o.progress = f
+	}
+}
+
+// upload installs the package in opts.file by uploading it in chunks.
+func (c *Client) upload(ctx context.Context, req *msgs.InstallReq, opts installOptions) error {
+	if len(req.Package) != 0 {
+		return fmt.Errorf("req.Package must be empty when using FromFile()")
+	}
+
+	f, err := os.Open(opts.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+
+	if req.SHA256 == "" {
+		req.SHA256, err = FileDigest(opts.file)
+		if err != nil {
+			return err
+		}
+	}
+
+	uresp := &msgs.UploadResp{}
+	if err := c.call(ctx, "uploads", &msgs.UploadReq{Manifest: *req, Size: size}, uresp); err != nil {
+		return err
+	}
+	if uresp.ErrMsg != "" {
+		return fmt.Errorf("upload failed: %s", uresp.ErrMsg)
+	}
+
+	var offset int64
+	for tries := 0; ; {
+		n := size - offset
+		if n > opts.chunkSize {
+			n = opts.chunkSize
+		}
+		if opts.progress != nil {
+			opts.progress(offset, size)
+		}
+
+		var body io.Reader = io.NewSectionReader(f, offset, n)
+		if opts.progress != nil {
+			body = &progressReader{r: body, sent: offset, total: size, f: opts.progress}
+		}
+
+		resp, retry, err := c.sendChunk(ctx, uresp.ID, offset, n, body)
+		if err != nil {
+			tries++
+			if !retry || tries >= maxChunkTries || ctx.Err() != nil {
+				return err
+			}
+			// The agent may have some of the chunk, so we resume from what it has.
+			if resp == nil {
+				resp = &msgs.ChunkResp{}
+				if serr := c.get(ctx, "uploads/"+url.PathEscape(uresp.ID), resp); serr != nil || resp.ErrMsg != "" {
+					return err
+				}
+			}
+			offset = resp.Offset
+			continue
+		}
+		tries = 0
+
+		offset = resp.Offset
+		if resp.Installed {
+			if opts.progress != nil {
+				opts.progress(offset, size)
+			}
+			return nil
+		}
+	}
+}
+
+// sendChunk sends the n bytes in body starting at offset to the upload with id. If the
+// chunk can be retried after an error, retry is true. resp is set if the agent told us
+// where to resume from.
+func (c *Client) sendChunk(ctx context.Context, id string, offset, n int64, body io.Reader) (resp *msgs.ChunkResp, retry bool, err error) {
+	u := fmt.Sprintf("http://%s/api/v1.0.0/uploads/%s", c.endpoint, url.PathEscape(id))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, u, body)
+	if err != nil {
+		return nil, false, fmt.Errorf("had problem creating http request for upload chunk: %w", err)
+	}
+	httpReq.ContentLength = n
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+	httpReq.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, true, fmt.Errorf("had problem with upload chunk HTTP request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	resp = &msgs.ChunkResp{}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return nil, true, fmt.Errorf("had problem unmarshaling upload chunk HTTP response(status %d): %w", httpResp.StatusCode, err)
+	}
+	if resp.ErrMsg != "" {
+		// A conflict is a chunk that didn't start where the agent is.
+		return resp, httpResp.StatusCode == http.StatusConflict, fmt.Errorf("upload failed: %s", resp.ErrMsg)
+	}
+	return resp, false, nil
+}
+
+// progressReader calls f as it is read with how much of total has been sent.
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	f     func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.f(p.sent, p.total)
+	}
+	return n, err
+}
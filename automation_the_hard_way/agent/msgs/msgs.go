@@ -1,46 +1,408 @@
 package msgs
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	// validName matches package names. Names are used in file paths on the agent.
+	validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+	// validVersion matches package versions, which are also used in file paths.
+	validVersion = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// ValidateName validates a package name.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if !validName.MatchString(name) {
+		return fmt.Errorf("name(%s) can only have ASCII letters, numbers, - and _", name)
+	}
+	return nil
+}
+
+// ValidateVersion validates a package version.
+func ValidateVersion(version string) error {
+	if version == "" {
+		return fmt.Errorf("version cannot be empty")
+	}
+	if !validVersion.MatchString(version) || version == "current" {
+		return fmt.Errorf("version(%s) can only have ASCII letters, numbers, ., - and _ and cannot be 'current'", version)
+	}
+	return nil
+}
+
+// ValidateBinary validates the name of a package's binary, which must be a file directly
+// in the package.
+func ValidateBinary(binary string) error {
+	if binary == "" {
+		return fmt.Errorf("binary cannot be empty")
+	}
+	if filepath.Base(binary) != binary || !filepath.IsLocal(binary) || binary == "." {
+		return fmt.Errorf("binary(%s) must be the name of a file in the package, not a path", binary)
+	}
+	return nil
+}
 
 // InstallReq is the request to install a package.
 type InstallReq struct {
 	// Name is the name of the package. It can contain no spaces and only
-	// ASCII letters and numbers. This will be installed in the user's home
-	// directory with the following path: ~/sa/<name>/.
+	// ASCII letters, numbers, - and _. This will be installed in the user's home
+	// directory with the following path: ~/sa/packages/<name>/<version>/.
 	Name string
+	// Version is the version of the package. If empty, a version is made from the time
+	// of the install. Installing a version that is already installed replaces it, unless
+	// it is the current version.
+	Version string
 	// Package is the package directory to be installed at <name>. It is a
 	// gzipped directory with our binary in it.
 	Package []byte
-	// Binary is the name of the binary to run. It must be directly in <name>.
-	// Only ASCII letters and numbers are allowed.
+	// SHA256 is the hex encoded SHA-256 digest of Package.
+	SHA256 string
+	// Signature is the ed25519 signature of SignedBytes(), which has the digest of Package
+	// and everything that says how it is run, made with a key that the agent trusts. The
+	// agent may allow packages that aren't signed.
+	Signature []byte
+	// Binary is the name of the binary to run. It must be a file directly in the
+	// package, not a path.
 	Binary string
 	// Args are the arguments to pass to the binary.
 	Args []string
+	// Restart is when the agent restarts the binary after it exits. Defaults to RestartAlways.
+	Restart RestartPolicy
 
 	unzipped []byte
 }
 
 // Validate validates the InstallReq.
 func (i *InstallReq) Validate() error {
-	if i.Name == "" {
-		return fmt.Errorf("name cannot be empty")
-	}
-	if i.Binary == "" {
-		return fmt.Errorf("binary cannot be empty")
-	}
 	if len(i.Package) == 0 {
 		return fmt.Errorf("package cannot be empty")
 	}
+	return i.validateManifest()
+}
+
+// SignedBytes returns what Signature signs: the package's SHA256 digest with the Name,
+// Version, Binary, Args and Restart that say how it is installed and run. This is a JSON
+// encoding of those fields in a fixed order.
+func (i *InstallReq) SignedBytes() ([]byte, error) {
+	// No args can be nil or empty, depending on how the request was made.
+	var args []string
+	if len(i.Args) > 0 {
+		args = i.Args
+	}
+	return json.Marshal(
+		struct {
+			Name    string
+			Version string
+			SHA256  string
+			Binary  string
+			Args    []string
+			Restart RestartPolicy
+		}{i.Name, i.Version, i.SHA256, i.Binary, args, i.Restart},
+	)
+}
+
+// validateManifest validates everything in the InstallReq except Package.
+func (i *InstallReq) validateManifest() error {
+	if err := ValidateName(i.Name); err != nil {
+		return err
+	}
+	if i.Version != "" {
+		if err := ValidateVersion(i.Version); err != nil {
+			return err
+		}
+	}
+	if err := ValidateBinary(i.Binary); err != nil {
+		return err
+	}
+	if len(i.SHA256) != 2*sha256.Size {
+		return fmt.Errorf("sha256 must be a hex encoded SHA-256 digest")
+	}
+	if err := i.Restart.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// RestartPolicy says when a program is restarted after it exits.
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the program whenever it exits.
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the program only if it exits with an error.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever never restarts the program.
+	RestartNever RestartPolicy = "never"
+)
+
+// Validate validates the RestartPolicy. An empty RestartPolicy is valid and means RestartAlways.
+func (r RestartPolicy) Validate() error {
+	switch r {
+	case "", RestartAlways, RestartOnFailure, RestartNever:
+		return nil
+	}
+	return fmt.Errorf("restart policy(%s) is not valid", r)
+}
+
+// ProgramState is the state of a program the agent is running.
+type ProgramState string
+
+const (
+	// StateRunning means the program is running.
+	StateRunning ProgramState = "running"
+	// StateBackoff means the program exited and is waiting to be restarted.
+	StateBackoff ProgramState = "backoff"
+	// StateExited means the program exited without an error and won't be restarted.
+	StateExited ProgramState = "exited"
+	// StateFailed means the program exited with an error and won't be restarted.
+	StateFailed ProgramState = "failed"
+	// StateStopped means the program was stopped by the agent.
+	StateStopped ProgramState = "stopped"
+)
+
+// Program is the status of a program the agent is running.
+type Program struct {
+	// Name is the name of the package the program is from.
+	Name string
+	// Binary is the path to the binary.
+	Binary string
+	// Args are the arguments the binary is run with.
+	Args []string
+	// Restart is the program's restart policy.
+	Restart RestartPolicy
+	// State is the state of the program.
+	State ProgramState
+	// PID is the process ID of the program. It is only set when the program is running.
+	PID int
+	// Restarts is the number of times the program has been restarted.
+	Restarts int
+	// LastExitCode is the exit code of the last time the program exited. It is -1 if the
+	// program was killed by a signal or could not be started.
+	LastExitCode int
+	// StartTime is when the program was last started in unix nanoseconds.
+	StartTime int64
+	// Stdout and Stderr are the paths of the program's log files.
+	Stdout, Stderr string
+}
+
+// PackageReq is the request to stop, start, restart or uninstall an installed package.
+type PackageReq struct {
+	// Name is the name of the package.
+	Name string
+}
+
+// Validate validates the PackageReq.
+func (p *PackageReq) Validate() error {
+	return ValidateName(p.Name)
+}
+
+// PackageResp is the response to a PackageReq.
+type PackageResp struct {
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// RollbackReq is the request to switch a package back to an older installed version.
+type RollbackReq struct {
+	// Name is the name of the package.
+	Name string
+	// Version is the version to switch to. If empty, this is the version that was
+	// installed before the current one.
+	Version string
+}
+
+// Validate validates the RollbackReq.
+func (r *RollbackReq) Validate() error {
+	if err := ValidateName(r.Name); err != nil {
+		return err
+	}
+	if r.Version != "" {
+		return ValidateVersion(r.Version)
+	}
+	return nil
+}
+
+// RollbackResp is the response to a RollbackReq.
+type RollbackResp struct {
+	// Version is the version that is now current.
+	Version string
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// Package is an installed package.
+type Package struct {
+	// Name is the name of the package.
+	Name string
+	// Version is the version of the package that is current.
+	Version string
+	// Installed is when the version was installed in unix nanoseconds.
+	Installed int64
+	// Versions are the installed versions of the package, from oldest to newest install.
+	// This is only set when listing packages.
+	Versions []string
+	// Binary is the name of the binary in the package that is run.
+	Binary string
+	// Args are the arguments the binary is run with.
+	Args []string
+	// Restart is the restart policy of the program.
+	Restart RestartPolicy
+	// Program is the status of the package's program. It is nil if the agent has not
+	// run it since the agent started.
+	Program *Program
+}
+
+// ListResp is the response to listing the installed packages.
+type ListResp struct {
+	Packages []Package
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// ProgramsResp is the response to listing the programs the agent is running.
+type ProgramsResp struct {
+	Programs []Program
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// ProgramResp is the response to getting a program the agent is running.
+type ProgramResp struct {
+	Program Program
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
 // InstallResp is the response to install a package.
 type InstallResp struct {
 	// ErrMsg is error message that was returned. If empty, no error occurred.
 	ErrMsg string
 }
 
+// UploadReq starts an upload of a package that is too large to send in an InstallReq.
+// The package is then sent in chunks, which the agent unpacks as they are received, and
+// installed once the last chunk is received. So that it can be unpacked in order, files
+// in the package that aren't compressed must have their sizes before them, which most
+// zip tools do. An upload can be resumed from where it stopped.
+type UploadReq struct {
+	// Manifest is the install request for the package, without Package. SHA256 must be
+	// set and Signature is required by most agents.
+	Manifest InstallReq
+	// Size is the size of the package in bytes.
+	Size int64
+}
+
+// Validate validates the UploadReq.
+func (u *UploadReq) Validate() error {
+	if len(u.Manifest.Package) != 0 {
+		return fmt.Errorf("manifest cannot have a package, it is uploaded in chunks")
+	}
+	if u.Size <= 0 {
+		return fmt.Errorf("size must be greater than 0")
+	}
+	return u.Manifest.validateManifest()
+}
+
+// UploadResp is the response to an UploadReq.
+type UploadResp struct {
+	// ID is the ID of the upload, which is used to send chunks.
+	ID string
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// ChunkResp is the response to sending a chunk of an upload or asking for its status.
+type ChunkResp struct {
+	// Offset is how many bytes of the package the agent has. The next chunk must start here.
+	Offset int64
+	// Installed is true when the whole package was received and installed.
+	Installed bool
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// ExecReq is the request to run a command on the machine.
+type ExecReq struct {
+	// Cmd is the command to run. It is run with "/bin/sh -c".
+	Cmd string
+}
+
+// Validate validates the ExecReq.
+func (e *ExecReq) Validate() error {
+	if e.Cmd == "" {
+		return fmt.Errorf("cmd cannot be empty")
+	}
+	return nil
+}
+
+// ExecResp is the response to running a command.
+type ExecResp struct {
+	// Output is the combined stdout and stderr of the command.
+	Output []byte
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// WriteFileReq is the request to write a file on the machine.
+type WriteFileReq struct {
+	// Path is the absolute path of the file to write. If it exists, it is truncated.
+	Path string
+	// Content is the content of the file.
+	Content []byte
+	// Mode is the permission bits of the file if it is created.
+	Mode uint32
+}
+
+// Validate validates the WriteFileReq.
+func (w *WriteFileReq) Validate() error {
+	if !filepath.IsAbs(w.Path) {
+		return fmt.Errorf("path(%s) must be absolute", w.Path)
+	}
+	return nil
+}
+
+// WriteFileResp is the response to writing a file.
+type WriteFileResp struct {
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
+// StatReq is the request to get information about a file on the machine.
+type StatReq struct {
+	// Path is the absolute path of the file.
+	Path string
+}
+
+// Validate validates the StatReq.
+func (s *StatReq) Validate() error {
+	if !filepath.IsAbs(s.Path) {
+		return fmt.Errorf("path(%s) must be absolute", s.Path)
+	}
+	return nil
+}
+
+// StatResp is the response to a StatReq.
+type StatResp struct {
+	// Exists is true if the file exists. If false, the other fields are not set.
+	Exists bool
+	// Size is the size of the file in bytes.
+	Size int64
+	// Mode is the file's mode bits.
+	Mode uint32
+	// ModTime is the modification time of the file in unix nanoseconds.
+	ModTime int64
+	// ErrMsg is error message that was returned. If empty, no error occurred.
+	ErrMsg string
+}
+
 // CPUPerfs is a list of CPU performance metrics.
 type CPUPerfs struct {
 	// ResolutionSecs is the number of seconds between each metric.
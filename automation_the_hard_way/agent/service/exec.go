@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// maxWriteFileSize is the maximum size of a WriteFileReq.
+const maxWriteFileSize = 1 * 1024 * 1024 * 1024 // 1GB
+
+// validator is implemented by all our request messages.
+type validator interface {
+	Validate() error
+}
+
+// readReq reads a JSON request of up to max bytes from the request body into req
+// and validates it.
+func readReq(r *http.Request, max int64, req validator) error {
+	lr := io.LimitedReader{
+		R: r.Body,
+		N: max,
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(&lr)
+	if err != nil {
+		return fmt.Errorf("unable to read message body: %s", err)
+	}
+	if err := json.Unmarshal(b, req); err != nil {
+		return fmt.Errorf("unable to unmarshal message body: %s", err)
+	}
+	return req.Validate()
+}
+
+// Exec runs a command on the machine and returns its output. This is used by
+// tools like our rollout orchestration to control the machine through the agent.
+func (a *Agent) Exec(c *gin.Context) {
+	req := &msgs.ExecReq{}
+	if err := readReq(c.Request, 1024*1024, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.ExecResp{ErrMsg: err.Error()})
+		return
+	}
+
+	cmd := exec.CommandContext(c.Request.Context(), "/bin/sh", "-c", req.Cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.IndentedJSON(http.StatusOK, msgs.ExecResp{Output: out, ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.ExecResp{Output: out})
+}
+
+// WriteFile writes a file on the machine.
+func (a *Agent) WriteFile(c *gin.Context) {
+	req := &msgs.WriteFileReq{}
+	if err := readReq(c.Request, maxWriteFileSize, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.WriteFileResp{ErrMsg: err.Error()})
+		return
+	}
+
+	f, err := os.OpenFile(req.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fs.FileMode(req.Mode).Perm())
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.WriteFileResp{ErrMsg: err.Error()})
+		return
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, bytes.NewReader(req.Content)); err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, msgs.WriteFileResp{ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.WriteFileResp{})
+}
+
+// Stat returns information about a file on the machine.
+func (a *Agent) Stat(c *gin.Context) {
+	req := &msgs.StatReq{}
+	if err := readReq(c.Request, 1024*1024, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.StatResp{ErrMsg: err.Error()})
+		return
+	}
+
+	fi, err := os.Stat(req.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.IndentedJSON(http.StatusOK, msgs.StatResp{})
+			return
+		}
+		c.IndentedJSON(http.StatusInternalServerError, msgs.StatResp{ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(
+		http.StatusOK,
+		msgs.StatResp{
+			Exists:  true,
+			Size:    fi.Size(),
+			Mode:    uint32(fi.Mode()),
+			ModTime: fi.ModTime().UnixNano(),
+		},
+	)
+}
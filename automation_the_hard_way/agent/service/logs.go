@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+const (
+	// logDir is the directory in the Agent user's home where program output is written.
+	logDir = "sa/logs/"
+
+	// maxLogSize is the size a log file can grow to before it is rotated.
+	maxLogSize = 10 * 1024 * 1024 // 10MB
+	// keepLogs is the number of rotated log files that are kept, named <file>.1 to <file>.<keepLogs>.
+	keepLogs = 3
+)
+
+// rotatingFile is an io.WriteCloser that appends to a file. When the file reaches
+// maxSize it is renamed to <path>.1, any older files are shifted up by one and a new
+// file is started. If the file can't be rotated, writes continue to the current file and
+// rotation is tried again once another maxSize has been written.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	keep    int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	// limit is the size at which we rotate the file. This is maxSize unless rotation failed.
+	limit int64
+}
+
+// openRotating opens the log file at path, creating it if it does not exist.
+func openRotating(path string, maxSize int64, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, keep: keep, limit: maxSize}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	if err != nil {
+		return fmt.Errorf("could not open log file(%s): %w", r.path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+// Write implements io.Writer.
+func (r *rotatingFile) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(b)) > r.limit {
+		if err := r.rotate(); err != nil {
+			// Failing the write could kill the program with a SIGPIPE, so we keep the
+			// output in the current file.
+			log.Println(err)
+			r.limit = r.size + r.maxSize
+		} else {
+			r.limit = r.maxSize
+		}
+	}
+	n, err := r.f.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate moves the current file to <path>.1 and opens a new one. If it fails, r.f is
+// still the current file. r.mu must be held.
+func (r *rotatingFile) rotate() error {
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return fmt.Errorf("could not rotate log file(%s): %w", r.path, err)
+	}
+	old := r.f
+	if err := r.open(); err != nil {
+		// Put the file back, as that is where we keep writing.
+		os.Rename(r.path+".1", r.path)
+		return fmt.Errorf("could not rotate log file(%s): %w", r.path, err)
+	}
+	old.Close()
+	return nil
+}
+
+// Close implements io.Closer.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileSize returns the size of the file at p.
+func fileSize(t *testing.T, p string) int64 {
+	t.Helper()
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestRotate(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "prog.stdout.log")
+
+	// Output from before a restart is kept and counts toward the size.
+	if err := os.WriteFile(p, []byte("line 01\n"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	r, err := openRotating(p, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for i := 2; i <= 4; i++ {
+		if _, err := fmt.Fprintf(r, "line %02d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A write larger than maxSize to an empty file is not rotated.
+	big := strings.Repeat("x", 20)
+	empty := filepath.Join(t.TempDir(), "prog.stderr.log")
+	r2, err := openRotating(empty, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	if _, err := r2.Write([]byte(big)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc string
+		file string
+		want string
+	}{
+		{desc: "current file", file: p, want: "line 04\n"},
+		{desc: "newest rotated file", file: p + ".1", want: "line 03\n"},
+		{desc: "oldest rotated file", file: p + ".2", want: "line 02\n"},
+		{desc: "rotated files past keep are removed", file: p + ".3"},
+		{desc: "large write", file: empty, want: big},
+		{desc: "large write not rotated", file: empty + ".1"},
+	}
+
+	for _, test := range tests {
+		b, err := os.ReadFile(test.file)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("TestRotate(%s): file(%s) exists, want it not to", test.desc, test.file)
+		case test.want != "" && err != nil:
+			t.Errorf("TestRotate(%s): got err == %s, want err == nil", test.desc, err)
+		case string(b) != test.want:
+			t.Errorf("TestRotate(%s): got %q, want %q", test.desc, b, test.want)
+		}
+	}
+}
+
+func TestRotateFailure(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "prog.stdout.log")
+	r, err := openRotating(p, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	line := []byte("1234567\n")
+	if _, err := r.Write(line); err != nil {
+		t.Fatal(err)
+	}
+
+	// A non-empty directory where the rotated file goes can't be removed or replaced.
+	if err := os.MkdirAll(filepath.Join(p+".1", "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	n, err := r.Write(line)
+	if err != nil {
+		t.Fatalf("TestRotateFailure: got err == %s, want err == nil", err)
+	}
+	if n != len(line) {
+		t.Errorf("TestRotateFailure: got %d bytes written, want %d", n, len(line))
+	}
+	if got := fileSize(t, p); got != 16 {
+		t.Errorf("TestRotateFailure: got log size %d, want 16", got)
+	}
+
+	// Rotation is tried again after another maxSize of output.
+	if err := os.RemoveAll(p + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write(line); err != nil {
+		t.Fatalf("TestRotateFailure: got err == %s, want err == nil", err)
+	}
+	if got := fileSize(t, p+".1"); got != 16 {
+		t.Errorf("TestRotateFailure: got rotated log size %d, want 16", got)
+	}
+	if got := fileSize(t, p); got != 8 {
+		t.Errorf("TestRotateFailure: got log size %d, want 8", got)
+	}
+}
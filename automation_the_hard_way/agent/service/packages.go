@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// StopPackage stops the program of an installed package.
+func (a *Agent) StopPackage(c *gin.Context) {
+	a.packageOp(c, a.sup.stop)
+}
+
+// StartPackage starts the program of an installed package that is not running.
+func (a *Agent) StartPackage(c *gin.Context) {
+	a.packageOp(
+		c,
+		func(name string) error {
+			if a.sup.running(name) {
+				return fmt.Errorf("package(%s) is already running", name)
+			}
+			pkg, err := a.loadPackage(name)
+			if err != nil {
+				return err
+			}
+			return a.startProgram(pkg)
+		},
+	)
+}
+
+// RestartPackage stops the program of an installed package, if it is running, and starts it.
+func (a *Agent) RestartPackage(c *gin.Context) {
+	a.packageOp(
+		c,
+		func(name string) error {
+			pkg, err := a.loadPackage(name)
+			if err != nil {
+				return err
+			}
+			return a.startProgram(pkg)
+		},
+	)
+}
+
+// Rollback makes an older installed version of a package current. If the package's
+// program is running, it is restarted with that version.
+func (a *Agent) Rollback(c *gin.Context) {
+	req := &msgs.RollbackReq{}
+	if err := readReq(c.Request, 1024*1024, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.RollbackResp{ErrMsg: err.Error()})
+		return
+	}
+
+	a.pkgMu.Lock()
+	defer a.pkgMu.Unlock()
+
+	version, err := a.rollback(req.Name, req.Version)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errNotFound) {
+			status = http.StatusNotFound
+		}
+		c.IndentedJSON(status, msgs.RollbackResp{ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.RollbackResp{Version: version})
+}
+
+// rollback switches package name to version, or the version installed before the
+// current one if version is empty. It returns the version switched to. If the program
+// was running and version can't be started, the current version is restored.
+func (a *Agent) rollback(name, version string) (string, error) {
+	cur, err := a.currentVersion(name)
+	if err != nil {
+		return "", err
+	}
+	if cur == "" {
+		return "", fmt.Errorf("package(%s): %w", name, errNotFound)
+	}
+	pkgs, err := a.versions(name)
+	if err != nil {
+		return "", err
+	}
+
+	var to *msgs.Package
+	for i := range pkgs {
+		switch {
+		case version == "" && pkgs[i].Version == cur:
+			if i == 0 {
+				return "", fmt.Errorf("package(%s) has no version older than version(%s)", name, cur)
+			}
+			to = &pkgs[i-1]
+		case version != "" && pkgs[i].Version == version:
+			to = &pkgs[i]
+		}
+	}
+	if to == nil {
+		return "", fmt.Errorf("package(%s) version(%s): %w", name, version, errNotFound)
+	}
+	if to.Version == cur {
+		return "", fmt.Errorf("package(%s) version(%s) is already current", name, cur)
+	}
+
+	running := a.sup.running(name)
+	if err := a.sup.stop(name); err != nil && !errors.Is(err, errNotFound) {
+		return "", err
+	}
+	if err := a.setCurrent(name, to.Version); err != nil {
+		return "", err
+	}
+	log.Printf("rolled back package(%s) from version(%s) to version(%s)", name, cur, to.Version)
+	if running {
+		if err := a.startProgram(*to); err != nil {
+			a.restore(name, cur, true)
+			return "", err
+		}
+	}
+	return to.Version, nil
+}
+
+// Uninstall stops the program of an installed package and removes all its versions. Its
+// logs are kept.
+func (a *Agent) Uninstall(c *gin.Context) {
+	a.packageOp(
+		c,
+		func(name string) error {
+			dir := a.pkgPath(name)
+			if _, err := os.Stat(dir); err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("package(%s): %w", name, errNotFound)
+				}
+				return err
+			}
+			a.sup.remove(name)
+			return os.RemoveAll(dir)
+		},
+	)
+}
+
+// List returns the installed packages and the status of their programs.
+func (a *Agent) List(c *gin.Context) {
+	a.pkgMu.Lock()
+	defer a.pkgMu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(a.homePath, pkgDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.IndentedJSON(http.StatusInternalServerError, msgs.ListResp{ErrMsg: err.Error()})
+		return
+	}
+
+	resp := msgs.ListResp{Packages: []msgs.Package{}}
+	for _, e := range entries {
+		// This skips files and our temporary install directories.
+		if !e.IsDir() || msgs.ValidateName(e.Name()) != nil {
+			continue
+		}
+		// Packages installed before we kept versions only have a name.
+		pkg, err := a.loadPackage(e.Name())
+		if err != nil {
+			pkg = msgs.Package{Name: e.Name()}
+		}
+		vers, err := a.versions(e.Name())
+		if err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, msgs.ListResp{ErrMsg: err.Error()})
+			return
+		}
+		for _, v := range vers {
+			pkg.Versions = append(pkg.Versions, v.Version)
+		}
+		if p, ok := a.sup.get(e.Name()); ok {
+			pkg.Program = &p
+		}
+		resp.Packages = append(resp.Packages, pkg)
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// packageOp reads a msgs.PackageReq and calls op with the package name while holding
+// a.pkgMu. It responds with a msgs.PackageResp.
+func (a *Agent) packageOp(c *gin.Context, op func(name string) error) {
+	req := &msgs.PackageReq{}
+	if err := readReq(c.Request, 1024*1024, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.PackageResp{ErrMsg: err.Error()})
+		return
+	}
+
+	a.pkgMu.Lock()
+	defer a.pkgMu.Unlock()
+
+	if err := op(req.Name); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errNotFound) {
+			status = http.StatusNotFound
+		}
+		c.IndentedJSON(status, msgs.PackageResp{ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.PackageResp{})
+}
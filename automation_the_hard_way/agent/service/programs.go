@@ -0,0 +1,25 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// Programs returns the status of all the programs the agent is running.
+func (a *Agent) Programs(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, msgs.ProgramsResp{Programs: a.sup.list()})
+}
+
+// Program returns the status of the program named in the URL.
+func (a *Agent) Program(c *gin.Context) {
+	name := c.Param("name")
+	p, ok := a.sup.get(name)
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, msgs.ProgramResp{ErrMsg: fmt.Sprintf("program(%s) not found", name)})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.ProgramResp{Program: p})
+}
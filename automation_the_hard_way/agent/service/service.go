@@ -3,19 +3,20 @@ package service
 import (
 	"archive/zip"
 	"bytes"
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gin-contrib/expvar"
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,11 @@ const (
 	pkgDir = "sa/packages/"
 
 	maxInstallSize = 1 * 1024 * 1024 * 1024 // 1GB
+
+	// maxUnpackSize is the most a package can unpack to and maxUnpackFiles is the most
+	// files it can have. These protect us from zip bombs.
+	maxUnpackSize  = 4 * 1024 * 1024 * 1024 // 4GB
+	maxUnpackFiles = 10000
 )
 
 // Agent provides a simple System Agent using REST to install and run programs.
@@ -45,7 +51,32 @@ type Agent struct {
 	// memData is the atomic pointer to the memory data.
 	memData atomic.Pointer[msgs.MemPerf]
 
+	// sup runs the programs we install.
+	sup *supervisor
+	// pkgMu is held while changing an installed package, so that only one change
+	// happens at a time.
+	pkgMu sync.Mutex
+
+	// uploadMu protects uploading, which has the IDs of uploads receiving a chunk, and
+	// receivers, which are unpacking uploads.
+	uploadMu  sync.Mutex
+	uploading map[string]bool
+	receivers map[string]*receiver
+
 	QOTDAddr string
+	// KeepVersions is how many versions of each package are kept, including the current
+	// one. Defaults to 3.
+	KeepVersions int
+	// TrustedKeys is the directory of public keys that packages must be signed with.
+	// Defaults to ~/sa/trusted_keys/.
+	TrustedKeys string
+	// AllowUnsigned allows installing packages without a signature. Packages that are
+	// signed must still be signed by a trusted key. This is only for testing.
+	AllowUnsigned bool
+	// AllowRemoteExec allows the exec, writeFile and stat APIs, which let tools like our
+	// rollout orchestration run any command as the agent's user. These are only served
+	// on the domain socket, so the agent must be compiled with "go build -tags ds".
+	AllowRemoteExec bool
 }
 
 // New creates a new Agent. If addr is empty, it will default to localhost:8080.
@@ -69,9 +100,12 @@ func New(router *gin.Engine, addr string) (*Agent, error) {
 	}
 
 	agent := &Agent{
-		homePath: homePath,
-		router:   router,
-		addr:     addr,
+		homePath:  homePath,
+		router:    router,
+		addr:      addr,
+		sup:       newSupervisor(filepath.Join(homePath, logDir)),
+		uploading: map[string]bool{},
+		receivers: map[string]*receiver{},
 	}
 
 	if err := agent.perfLoop(); err != nil {
@@ -80,37 +114,77 @@ func New(router *gin.Engine, addr string) (*Agent, error) {
 
 	router.GET("/debug/vars", expvar.Handler())
 	router.POST("/api/v1.0.0/install", agent.Install)
+	router.GET("/api/v1.0.0/programs", agent.Programs)
+	router.GET("/api/v1.0.0/programs/:name", agent.Program)
+	router.POST("/api/v1.0.0/stop", agent.StopPackage)
+	router.POST("/api/v1.0.0/start", agent.StartPackage)
+	router.POST("/api/v1.0.0/restart", agent.RestartPackage)
+	router.POST("/api/v1.0.0/uninstall", agent.Uninstall)
+	router.GET("/api/v1.0.0/list", agent.List)
+	router.POST("/api/v1.0.0/rollback", agent.Rollback)
+	router.POST("/api/v1.0.0/uploads", agent.StartUpload)
+	router.PUT("/api/v1.0.0/uploads/:id", agent.UploadChunk)
+	router.GET("/api/v1.0.0/uploads/:id", agent.UploadStatus)
+	router.DELETE("/api/v1.0.0/uploads/:id", agent.CancelUpload)
+	agent.remoteRoutes()
 	return agent, nil
 }
 
-// Install installs a package on the machine and starts it.
+// Install installs a package on the machine as a new version and starts it.
 func (a *Agent) Install(c *gin.Context) {
 	req, err := a.getInstallReq(*c.Request)
 	if err != nil {
 		sendInstallError(c, http.StatusBadRequest, err)
 		return
 	}
+	if status, err := a.install(req, bytes.NewReader(req.Package), int64(len(req.Package))); err != nil {
+		sendInstallError(c, status, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, msgs.InstallResp{})
+}
+
+// install verifies the package in pkg, which is size bytes, and installs it as req says.
+// req.Package is not used, so pkg can be in a file. If there is an error, this returns
+// the HTTP status code for it.
+func (a *Agent) install(req *msgs.InstallReq, pkg io.ReaderAt, size int64) (int, error) {
+	if err := a.verifyPackage(io.NewSectionReader(pkg, 0, size), req); err != nil {
+		return http.StatusForbidden, err
+	}
 
-	from, err := a.unpack(req)
+	from, err := a.unpack(req.Name, pkg, size)
+	// If the install works, from was moved and this does nothing.
+	defer os.RemoveAll(from)
 	if err != nil {
-		sendInstallError(c, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest, err
+	}
+	return a.activate(req, from)
+}
+
+// activate moves the verified package unpacked in directory from into place as the
+// version in req and starts it.
+func (a *Agent) activate(req *msgs.InstallReq, from string) (int, error) {
+	if req.Version == "" {
+		req.Version = time.Now().UTC().Format(versionFormat)
 	}
+
+	a.pkgMu.Lock()
+	defer a.pkgMu.Unlock()
+
 	if err := a.migrate(req, from); err != nil {
-		sendInstallError(c, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest, err
 	}
-	if err := a.startProgram(c.Request.Context(), req); err != nil {
-		sendInstallError(c, http.StatusBadRequest, err)
-		return
+	if err := a.prune(req.Name); err != nil {
+		log.Printf("could not remove old versions of package(%s): %s", req.Name, err)
 	}
-
-	c.IndentedJSON(http.StatusOK, msgs.InstallResp{})
+	return http.StatusOK, nil
 }
 
 // getInstallReq gets the msgs.InstallReq from the request body. It will return
 // an error if the body is larger than maxInstallSize (1 GiB). Also runs the
-// validation on the message.
+// validation on the message. Large packages should use StartUpload() instead, which
+// doesn't need the package in memory.
 func (a *Agent) getInstallReq(r http.Request) (*msgs.InstallReq, error) {
 	lr := io.LimitedReader{
 		R: r.Body,
@@ -131,108 +205,213 @@ func (a *Agent) getInstallReq(r http.Request) (*msgs.InstallReq, error) {
 	return req, req.Validate()
 }
 
-// unpack unpacks the zip file into a temp directory and returns the directory location.
-func (a *Agent) unpack(req *msgs.InstallReq) (string, error) {
-	dir, err := os.MkdirTemp("", fmt.Sprintf("sa_install_%s_*", req.Name))
-	if err != nil {
+// unpack unpacks the zip file in pkg for package name into a temp directory and returns
+// the directory location. The directory is in our package directory so that it can be
+// renamed into place. If there is an error after the directory is made, it is still
+// returned for cleanup.
+func (a *Agent) unpack(name string, pkg io.ReaderAt, size int64) (string, error) {
+	root := filepath.Join(a.homePath, pkgDir)
+	if err := os.MkdirAll(root, 0770); err != nil {
 		return "", err
 	}
-	r, err := zip.NewReader(bytes.NewReader(req.Package), int64(len(req.Package)))
+	dir, err := os.MkdirTemp(root, fmt.Sprintf(".sa_install_%s_*", name))
 	if err != nil {
 		return "", err
 	}
+	r, err := zip.NewReader(pkg, size)
+	if err != nil {
+		return dir, err
+	}
+
+	if len(r.File) > maxUnpackFiles {
+		return dir, fmt.Errorf("package has %d files, more than the %d allowed", len(r.File), maxUnpackFiles)
+	}
 
 	// Iterate through the files in the archive, writing the files into our
 	// temp directory.
+	var total int64
 	for _, f := range r.File {
-		if err := a.writeFile(f, dir); err != nil {
-			return "", err
+		n, err := a.writeFile(f, dir, maxUnpackSize-total)
+		if err != nil {
+			return dir, err
 		}
+		total += n
 	}
 	return dir, nil
 }
 
 // writeFile writes a zip file under the root directory dir.
-func (a *Agent) writeFile(z *zip.File, dir string) error {
-	if z.FileInfo().IsDir() {
-		err := os.Mkdir(
-			filepath.Join(dir, filepath.FromSlash(z.Name)),
-			z.Mode(),
-		)
-		return err
+// It writes at most max bytes and returns the number of bytes written. Files must stay
+// under dir and can only be regular files or directories.
+func (a *Agent) writeFile(z *zip.File, dir string, max int64) (int64, error) {
+	// The header's size can lie, but a package that says it is too large is rejected early.
+	if z.UncompressedSize64 > uint64(max) {
+		return 0, fmt.Errorf("package unpacks to more than %d bytes", maxUnpackSize)
 	}
 
 	rc, err := z.Open()
 	if err != nil {
-		return fmt.Errorf("could not open file %q: %w", z.Name, err)
+		return 0, fmt.Errorf("could not open file %q: %w", z.Name, err)
 	}
 	defer rc.Close()
 
-	nf, err := os.OpenFile(
-		filepath.Join(dir, filepath.FromSlash(z.Name)),
-		os.O_CREATE|os.O_WRONLY,
-		z.Mode(),
-	)
+	return writeEntry(dir, z.Name, z.Mode(), rc, max)
+}
+
+// writeEntry writes the file called name in a package, with mode and content read from r,
+// under the root directory dir. It is writeFile() for packages that aren't read with
+// archive/zip.
+func writeEntry(dir, name string, mode fs.FileMode, r io.Reader, max int64) (int64, error) {
+	// This rejects absolute paths and paths with ".." that would escape dir.
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return 0, fmt.Errorf("file %q in package is outside the package directory", name)
+	}
+	p := filepath.Join(dir, filepath.FromSlash(name))
+
+	switch {
+	case mode.IsDir():
+		// We need to be able to write the directory's files.
+		return 0, os.MkdirAll(p, mode.Perm()|0700)
+	case !mode.IsRegular():
+		return 0, fmt.Errorf("file %q in package is not a regular file or directory", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0770); err != nil {
+		return 0, err
+	}
+
+	// O_EXCL rejects packages that have the same file twice.
+	nf, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
 	if err != nil {
-		return fmt.Errorf("could not open file in temp diretory: %w", err)
+		return 0, fmt.Errorf("could not open file in temp diretory: %w", err)
 	}
 	defer nf.Close()
 
-	_, err = io.Copy(nf, rc)
+	n, err := io.Copy(nf, io.LimitReader(r, max+1))
 	if err != nil {
-		return fmt.Errorf("file copy error: %w", err)
+		return n, fmt.Errorf("file copy error: %w", err)
 	}
-	return nil
+	if n > max {
+		return n, fmt.Errorf("package unpacks to more than %d bytes", maxUnpackSize)
+	}
+	return n, nil
 }
 
-// migrate shuts down any existing job that is running and migrates our files
-// from the temp location to the final location.
+// migrate migrates our files from the temp location to the directory for the new
+// version, shuts down the existing version, makes the new version current and starts
+// it. If the new version can't be started, the previous version is restored. If there was
+// no previous version, no version is left current.
 func (a *Agent) migrate(req *msgs.InstallReq, from string) error {
-	to := filepath.Join(a.homePath, pkgDir, req.Name)
+	prev, err := a.currentVersion(req.Name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case prev == req.Version:
+		return fmt.Errorf("package(%s) version(%s) is the current version", req.Name, req.Version)
+	case prev == "":
+		// Packages installed before we kept versions have their files directly in the
+		// package directory. Like any failed first install, these are replaced.
+		if err := os.RemoveAll(a.pkgPath(req.Name)); err != nil {
+			return err
+		}
+	}
+
+	to := a.versionPath(req.Name, req.Version)
 	if err := os.MkdirAll(filepath.Dir(to), 0770); err != nil {
 		return err
 	}
-	// We can only have one program running at a time.
-	// Note: I did not implement something to stop any existing programs that are running.
-	// This is trivial to implement.
-	if _, err := os.Stat(to); err == nil {
-		os.RemoveAll(to)
+	if err := os.RemoveAll(to); err != nil {
+		return err
 	}
 	log.Println("from: ", from)
 	log.Println("to: ", to)
 	if err := os.Rename(from, to); err != nil {
 		return err
 	}
+
+	pkg := msgs.Package{
+		Name:      req.Name,
+		Version:   req.Version,
+		Installed: time.Now().UnixNano(),
+		Binary:    req.Binary,
+		Args:      req.Args,
+		Restart:   req.Restart,
+	}
+	if err := a.savePackage(pkg); err != nil {
+		return err
+	}
+
+	// We can only have one program running at a time, so we gracefully stop the old
+	// version before switching to the new one.
+	running := a.sup.running(req.Name)
+	if err := a.sup.stop(req.Name); err != nil && !errors.Is(err, errNotFound) {
+		return err
+	}
+	if err := a.setCurrent(req.Name, req.Version); err != nil {
+		return err
+	}
+	if err := a.startProgram(pkg); err != nil {
+		if prev == "" {
+			os.Remove(filepath.Join(a.pkgPath(req.Name), currentLink))
+		} else {
+			a.restore(req.Name, prev, running)
+		}
+		return err
+	}
 	return nil
 }
 
-// startProgram starts our program manually.
+// restore makes version of package name current again after a failed install or rollback.
+// If start is set, its program is started.
+func (a *Agent) restore(name, version string, start bool) {
+	log.Printf("restoring package(%s) version(%s) after the switch from it failed", name, version)
+	if err := a.setCurrent(name, version); err != nil {
+		log.Println(err)
+		return
+	}
+	if !start {
+		return
+	}
+	pkg, err := a.loadVersion(name, version)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := a.startProgram(pkg); err != nil {
+		log.Println(err)
+	}
+}
+
+// startProgram starts the program in pkg and supervises it, restarting it as pkg.Restart
+// says. If it is already running, it is restarted. Its output is written to log files
+// under ~/sa/logs/.
 // Note: You generally want to use a process manager like systemd, upstart, etc to manage
 //
-//	your programs. This is for illustration purposes only. You would also want something
-//	to restart the program if it dies by monitoring the process.
-func (a *Agent) startProgram(ctx context.Context, req *msgs.InstallReq) error {
+//	your programs. This is for illustration purposes only. Programs are not restarted if
+//	the agent itself is restarted.
+func (a *Agent) startProgram(pkg msgs.Package) error {
 	if a.QOTDAddr == "" {
 		a.QOTDAddr = ":17"
 	}
 
-	p := filepath.Join(a.homePath, pkgDir)
-	cmdStr := fmt.Sprintf(
-		"%s %s --addr %s &",
-		filepath.Join(p, req.Name, req.Binary),
-		strings.Join(req.Args, " "),
-		a.QOTDAddr,
-	)
-	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmdStr)
-
-	cmd.Stderr = nil
-	cmd.Stdout = nil
-	if err := cmd.Start(); err != nil {
+	// Packages are checked when installed, but this is also read from disk.
+	if err := msgs.ValidateBinary(pkg.Binary); err != nil {
 		return err
 	}
-	log.Println("Started program: ", cmd.Process.Pid)
-	log.Println("waiting: ", cmd.Wait())
-	return nil
+
+	dir := a.versionPath(pkg.Name, pkg.Version)
+	args := append([]string{}, pkg.Args...)
+	args = append(args, "--addr", a.QOTDAddr)
+
+	return a.sup.start(
+		programConfig{
+			name:    pkg.Name,
+			binary:  filepath.Join(dir, pkg.Binary),
+			args:    args,
+			dir:     dir,
+			restart: pkg.Restart,
+		},
+	)
 }
 
 func sendInstallError(c *gin.Context, status int, err error) {
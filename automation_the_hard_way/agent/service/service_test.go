@@ -0,0 +1,202 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// zipFile is a file to put in a test package.
+type zipFile struct {
+	name string
+	mode fs.FileMode
+	body string
+}
+
+// makeZip returns a zip file holding files.
+func makeZip(t *testing.T, files []zipFile) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, f := range files {
+		fh := &zip.FileHeader{Name: f.name, Method: zip.Deflate}
+		mode := f.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		fh.SetMode(mode)
+		fw, err := w.CreateHeader(fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testAgent returns an Agent whose home is a temporary directory.
+func testAgent(t *testing.T) *Agent {
+	t.Helper()
+
+	home := t.TempDir()
+	return &Agent{
+		homePath:  home,
+		sup:       newSupervisor(filepath.Join(home, logDir)),
+		uploading: map[string]bool{},
+		receivers: map[string]*receiver{},
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	manyFiles := make([]zipFile, maxUnpackFiles+1)
+	for i := range manyFiles {
+		manyFiles[i] = zipFile{name: fmt.Sprintf("f%d", i)}
+	}
+
+	tests := []struct {
+		desc    string
+		files   []zipFile
+		pkg     []byte // Used instead of files if set.
+		wantErr bool
+	}{
+		{
+			desc: "good package",
+			files: []zipFile{
+				{name: "svc", mode: 0755, body: "#!/bin/sh\n"},
+				{name: "conf/", mode: fs.ModeDir | 0755},
+				{name: "conf/svc.json", body: "{}"},
+				{name: "data/file", body: "parent directory is made"},
+			},
+		},
+		{
+			desc:    "parent directory",
+			files:   []zipFile{{name: "../evil", body: "x"}},
+			wantErr: true,
+		},
+		{
+			desc:    "parent directory in the middle",
+			files:   []zipFile{{name: "conf/../../evil", body: "x"}},
+			wantErr: true,
+		},
+		{
+			desc:    "absolute path",
+			files:   []zipFile{{name: "/tmp/evil", body: "x"}},
+			wantErr: true,
+		},
+		{
+			desc:    "symlink",
+			files:   []zipFile{{name: "evil", mode: fs.ModeSymlink | 0777, body: "/etc/passwd"}},
+			wantErr: true,
+		},
+		{
+			desc:    "device",
+			files:   []zipFile{{name: "evil", mode: fs.ModeDevice | 0600}},
+			wantErr: true,
+		},
+		{
+			desc:    "same file twice",
+			files:   []zipFile{{name: "svc", body: "a"}, {name: "svc", body: "b"}},
+			wantErr: true,
+		},
+		{
+			desc:    "too many files",
+			files:   manyFiles,
+			wantErr: true,
+		},
+		{
+			desc:    "not a zip",
+			pkg:     []byte("this is not a zip file"),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := testAgent(t)
+		pkg := test.pkg
+		if pkg == nil {
+			pkg = makeZip(t, test.files)
+		}
+
+		dir, err := a.unpack("pkg", bytes.NewReader(pkg), int64(len(pkg)))
+
+		// Nothing can be written outside of the package directory.
+		for _, p := range []string{filepath.Join(a.homePath, pkgDir, "evil"), "/tmp/evil"} {
+			if _, err := os.Lstat(p); err == nil {
+				os.Remove(p)
+				t.Errorf("TestUnpack(%s): file(%s) was written outside the package directory", test.desc, p)
+			}
+		}
+
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestUnpack(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestUnpack(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		for _, f := range test.files {
+			p := filepath.Join(dir, filepath.FromSlash(f.name))
+			fi, err := os.Stat(p)
+			if err != nil {
+				t.Errorf("TestUnpack(%s): file(%s) was not unpacked: %s", test.desc, f.name, err)
+				continue
+			}
+			if fi.IsDir() {
+				continue
+			}
+			b, _ := os.ReadFile(p)
+			if string(b) != f.body {
+				t.Errorf("TestUnpack(%s): file(%s): got content %q, want %q", test.desc, f.name, b, f.body)
+			}
+			if f.mode&0100 != 0 && fi.Mode()&0100 == 0 {
+				t.Errorf("TestUnpack(%s): file(%s): got mode %v, want it to be executable", test.desc, f.name, fi.Mode())
+			}
+		}
+	}
+}
+
+func TestWriteFileLimit(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 1000)
+	pkg := makeZip(t, []zipFile{{name: "big", body: string(body)}})
+	r, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc    string
+		max     int64
+		wantErr bool
+	}{
+		{desc: "under the limit", max: 2000},
+		{desc: "at the limit", max: 1000},
+		{desc: "over the limit", max: 999, wantErr: true},
+	}
+
+	for _, test := range tests {
+		a := testAgent(t)
+		n, err := a.writeFile(r.File[0], t.TempDir(), test.max)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestWriteFileLimit(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestWriteFileLimit(%s): got err == %s, want err == nil", test.desc, err)
+		case err == nil && n != int64(len(body)):
+			t.Errorf("TestWriteFileLimit(%s): got %d bytes written, want %d", test.desc, n, len(body))
+		}
+	}
+}
@@ -3,12 +3,19 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+
+	"github.com/gin-gonic/gin"
 )
 
+// socketKey is the context key set on requests that arrived on the domain socket.
+type socketKey struct{}
+
 // Start starts the agent on a domain socket instead of an IP. To use this method,
 // you must compile with "go build -tags ds".
 func (a *Agent) Start() error {
@@ -24,7 +31,42 @@ func (a *Agent) Start() error {
 		return fmt.Errorf("could not connect to socket: %w", err)
 	}
 
-	a.router.Run(a.addr)
+	// Requests on the socket are marked, so that remoteAuth() can tell them apart
+	// from requests on a.addr.
+	srv := &http.Server{
+		Handler: a.router.Handler(),
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, socketKey{}, true)
+		},
+	}
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- a.router.Run(a.addr) }()
+	go func() { errCh <- srv.Serve(l) }()
+	return <-errCh
+}
 
-	return a.router.RunListener(l)
+// remoteRoutes registers the exec, writeFile and stat APIs. These run any command and
+// write any file as the agent's user, so they are only served on the domain socket,
+// which can only be reached by logging in as that user with SSH, and only if
+// AllowRemoteExec is set.
+func (a *Agent) remoteRoutes() {
+	g := a.router.Group("/api/v1.0.0", a.remoteAuth)
+	g.POST("/exec", a.Exec)
+	g.POST("/writeFile", a.WriteFile)
+	g.POST("/stat", a.Stat)
+}
+
+// remoteAuth rejects requests to the remote APIs unless they are allowed and arrived
+// on the domain socket.
+func (a *Agent) remoteAuth(c *gin.Context) {
+	if !a.AllowRemoteExec {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ErrMsg": "remote exec is not allowed on this agent"})
+		return
+	}
+	if ok, _ := c.Request.Context().Value(socketKey{}).(bool); !ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ErrMsg": "remote exec is only allowed on the domain socket"})
+		return
+	}
+	c.Next()
 }
@@ -2,6 +2,10 @@
 
 package service
 
+// remoteRoutes does nothing, as the exec, writeFile and stat APIs are only served on
+// the domain socket. See startDomainSocket.go.
+func (a *Agent) remoteRoutes() {}
+
 // Start starts the agent.
 func (a *Agent) Start() error {
 	return a.router.Run(a.addr)
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// Each package is stored in ~/sa/packages/<name>/ with a directory for each installed
+// version. A "current" symlink points to the version that is run. Switching versions
+// replaces the symlink with a rename, so the switch is atomic.
+const (
+	// currentLink is the symlink in a package's directory to its current version.
+	currentLink = "current"
+	// packageFile is the file in a version's directory that records how to run it.
+	packageFile = ".package.json"
+	// versionFormat is the format of the versions made for installs without one.
+	versionFormat = "20060102T150405.000000000Z"
+	// defaultKeepVersions is how many versions of a package are kept if KeepVersions isn't set.
+	defaultKeepVersions = 3
+)
+
+// pkgPath returns the directory package name is stored in.
+func (a *Agent) pkgPath(name string) string {
+	return filepath.Join(a.homePath, pkgDir, name)
+}
+
+// versionPath returns the directory version of package name is stored in.
+func (a *Agent) versionPath(name, version string) string {
+	return filepath.Join(a.pkgPath(name), version)
+}
+
+// currentVersion returns the current version of package name. If the package isn't
+// installed, this returns "".
+func (a *Agent) currentVersion(name string) (string, error) {
+	v, err := os.Readlink(filepath.Join(a.pkgPath(name), currentLink))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return v, nil
+}
+
+// setCurrent atomically makes version the current version of package name.
+func (a *Agent) setCurrent(name, version string) error {
+	tmp := filepath.Join(a.pkgPath(name), "."+currentLink+".tmp")
+	os.Remove(tmp)
+
+	// The link is relative so that the package directory can be moved.
+	if err := os.Symlink(version, tmp); err != nil {
+		return fmt.Errorf("could not create link to package(%s) version(%s): %w", name, version, err)
+	}
+	if err := os.Rename(tmp, filepath.Join(a.pkgPath(name), currentLink)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("could not switch package(%s) to version(%s): %w", name, version, err)
+	}
+	return nil
+}
+
+// savePackage records how to run pkg in its version's directory.
+func (a *Agent) savePackage(pkg msgs.Package) error {
+	pkg.Program, pkg.Versions = nil, nil
+	b, err := json.Marshal(pkg)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(a.versionPath(pkg.Name, pkg.Version), packageFile)
+	if err := os.WriteFile(p, b, 0660); err != nil {
+		return fmt.Errorf("could not save package(%s) info: %w", pkg.Name, err)
+	}
+	return nil
+}
+
+// loadPackage reads how to run the current version of package name.
+func (a *Agent) loadPackage(name string) (msgs.Package, error) {
+	return a.loadVersion(name, currentLink)
+}
+
+// loadVersion reads how to run version of package name.
+func (a *Agent) loadVersion(name, version string) (msgs.Package, error) {
+	p := filepath.Join(a.versionPath(name, version), packageFile)
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return msgs.Package{}, fmt.Errorf("package(%s) version(%s): %w", name, version, errNotFound)
+		}
+		return msgs.Package{}, err
+	}
+
+	pkg := msgs.Package{}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return msgs.Package{}, fmt.Errorf("package(%s) version(%s) info is corrupt: %w", name, version, err)
+	}
+	return pkg, nil
+}
+
+// versions returns the installed versions of package name, from oldest to newest install.
+func (a *Agent) versions(name string) ([]msgs.Package, error) {
+	entries, err := os.ReadDir(a.pkgPath(name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var pkgs []msgs.Package
+	for _, e := range entries {
+		// This skips the current link and our temporary files.
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		pkg, err := a.loadVersion(name, e.Name())
+		if err != nil {
+			log.Printf("skipping package(%s) version(%s): %s", name, e.Name(), err)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Installed < pkgs[j].Installed })
+	return pkgs, nil
+}
+
+// prune removes the oldest versions of package name so that only a.KeepVersions are
+// kept. The current version is never removed.
+func (a *Agent) prune(name string) error {
+	keep := a.KeepVersions
+	if keep < 1 {
+		keep = defaultKeepVersions
+	}
+
+	cur, err := a.currentVersion(name)
+	if err != nil {
+		return err
+	}
+	pkgs, err := a.versions(name)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(pkgs) && len(pkgs)-i > keep; i++ {
+		if pkgs[i].Version == cur {
+			keep--
+			continue
+		}
+		log.Printf("removing old package(%s) version(%s)", name, pkgs[i].Version)
+		if err := os.RemoveAll(a.versionPath(name, pkgs[i].Version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// sleeper is a binary that runs until it is stopped.
+const sleeper = "#!/bin/sh\nexec sleep 30\n"
+
+// installVersion puts version of package "pkg" in the store without making it current.
+// Its binary "svc" has body. Versions are ordered by installed.
+func installVersion(t *testing.T, a *Agent, version string, installed int64, body string) msgs.Package {
+	t.Helper()
+
+	dir := a.versionPath("pkg", version)
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "svc"), []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pkg := msgs.Package{Name: "pkg", Version: version, Installed: installed, Binary: "svc", Restart: msgs.RestartNever}
+	if err := a.savePackage(pkg); err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// currentOf returns the current version of package "pkg".
+func currentOf(t *testing.T, a *Agent) string {
+	t.Helper()
+
+	v, err := a.currentVersion("pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestRollbackStartFailure(t *testing.T) {
+	a := testAgent(t)
+	t.Cleanup(func() { a.sup.remove("pkg") })
+
+	installVersion(t, a, "1", 1, sleeper)
+	v2 := installVersion(t, a, "2", 2, sleeper)
+	if err := a.setCurrent("pkg", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.startProgram(v2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Version 1 can't be started.
+	if err := os.Remove(filepath.Join(a.versionPath("pkg", "1"), "svc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.rollback("pkg", ""); err == nil {
+		t.Fatalf("TestRollbackStartFailure: got err == nil, want err != nil")
+	}
+
+	if got := currentOf(t, a); got != "2" {
+		t.Errorf("TestRollbackStartFailure: got current version %q, want %q", got, "2")
+	}
+	if !a.sup.running("pkg") {
+		t.Errorf("TestRollbackStartFailure: version 2 was not restarted")
+	}
+}
+
+func TestMigrateFirstStartFailure(t *testing.T) {
+	a := testAgent(t)
+	t.Cleanup(func() { a.sup.remove("pkg") })
+
+	// The package doesn't have its binary, so it can't be started.
+	from := t.TempDir()
+	req := &msgs.InstallReq{Name: "pkg", Version: "1", Binary: "svc", Restart: msgs.RestartNever}
+	if err := a.migrate(req, from); err == nil {
+		t.Fatalf("TestMigrateFirstStartFailure: got err == nil, want err != nil")
+	}
+
+	if got := currentOf(t, a); got != "" {
+		t.Errorf("TestMigrateFirstStartFailure: got current version %q, want none", got)
+	}
+	if _, err := a.loadPackage("pkg"); err == nil {
+		t.Errorf("TestMigrateFirstStartFailure: loadPackage(): got err == nil, want err != nil")
+	}
+}
+
+func TestSetCurrent(t *testing.T) {
+	a := testAgent(t)
+
+	if got := currentOf(t, a); got != "" {
+		t.Errorf("TestSetCurrent: got current version %q before install, want none", got)
+	}
+
+	installVersion(t, a, "1", 1, sleeper)
+	installVersion(t, a, "2", 2, sleeper)
+	for _, version := range []string{"1", "2", "1"} {
+		if err := a.setCurrent("pkg", version); err != nil {
+			t.Fatalf("TestSetCurrent(%s): got err == %s, want err == nil", version, err)
+		}
+		if got := currentOf(t, a); got != version {
+			t.Errorf("TestSetCurrent(%s): got current version %q, want %q", version, got, version)
+		}
+		pkg, err := a.loadPackage("pkg")
+		if err != nil {
+			t.Fatalf("TestSetCurrent(%s): loadPackage(): got err == %s", version, err)
+		}
+		if pkg.Version != version {
+			t.Errorf("TestSetCurrent(%s): loadPackage(): got version %q, want %q", version, pkg.Version, version)
+		}
+	}
+}
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		desc    string
+		current string // If "", the package isn't installed.
+		version string
+		want    string
+		wantErr bool
+		wantIs  error // If set, the error must wrap this.
+	}{
+		{desc: "previous version", current: "3", want: "2"},
+		{desc: "previous of a rolled back version", current: "2", want: "1"},
+		{desc: "specific version", current: "3", version: "1", want: "1"},
+		{desc: "newer version", current: "1", version: "3", want: "3"},
+		{desc: "no older version", current: "1", wantErr: true},
+		{desc: "already current", current: "2", version: "2", wantErr: true},
+		{desc: "version not installed", current: "3", version: "4", wantErr: true, wantIs: errNotFound},
+		{desc: "package not installed", wantErr: true, wantIs: errNotFound},
+	}
+
+	for _, test := range tests {
+		a := testAgent(t)
+		if test.current != "" {
+			for i, v := range []string{"1", "2", "3"} {
+				installVersion(t, a, v, int64(i), sleeper)
+			}
+			if err := a.setCurrent("pkg", test.current); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := a.rollback("pkg", test.version)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestRollback(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestRollback(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			if test.wantIs != nil && !errors.Is(err, test.wantIs) {
+				t.Errorf("TestRollback(%s): got err == %s, want it to wrap %s", test.desc, err, test.wantIs)
+			}
+			if test.current != "" && currentOf(t, a) != test.current {
+				t.Errorf("TestRollback(%s): got current version %q after an error, want %q", test.desc, currentOf(t, a), test.current)
+			}
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("TestRollback(%s): got version %q, want %q", test.desc, got, test.want)
+		}
+		if cur := currentOf(t, a); cur != test.want {
+			t.Errorf("TestRollback(%s): got current version %q, want %q", test.desc, cur, test.want)
+		}
+		// The program wasn't running, so it isn't started.
+		if a.sup.running("pkg") {
+			t.Errorf("TestRollback(%s): program was started, want it not to be", test.desc)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		desc    string
+		keep    int
+		current string
+		want    []string
+	}{
+		{desc: "default keeps 3", current: "5", want: []string{"3", "4", "5"}},
+		{desc: "keep 2", keep: 2, current: "5", want: []string{"4", "5"}},
+		{desc: "current is never removed", keep: 2, current: "1", want: []string{"1", "5"}},
+		{desc: "current counts toward keep", keep: 3, current: "2", want: []string{"2", "4", "5"}},
+		{desc: "keep more than installed", keep: 10, current: "5", want: []string{"1", "2", "3", "4", "5"}},
+	}
+
+	for _, test := range tests {
+		a := testAgent(t)
+		a.KeepVersions = test.keep
+		for i, v := range []string{"1", "2", "3", "4", "5"} {
+			installVersion(t, a, v, int64(i), sleeper)
+		}
+		if err := a.setCurrent("pkg", test.current); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := a.prune("pkg"); err != nil {
+			t.Errorf("TestPrune(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		}
+
+		pkgs, err := a.versions("pkg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, p := range pkgs {
+			got = append(got, p.Version)
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("TestPrune(%s): got versions %v, want %v", test.desc, got, test.want)
+		}
+		if cur := currentOf(t, a); cur != test.current {
+			t.Errorf("TestPrune(%s): got current version %q, want %q", test.desc, cur, test.current)
+		}
+	}
+}
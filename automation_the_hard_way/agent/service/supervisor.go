@@ -0,0 +1,389 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+const (
+	// minBackoff and maxBackoff are the shortest and longest we wait before restarting a
+	// program. The wait doubles each time the program exits soon after starting.
+	minBackoff = 1 * time.Second
+	maxBackoff = 1 * time.Minute
+	// stableRun is how long a program must run before we consider it healthy and reset
+	// its backoff.
+	stableRun = 1 * time.Minute
+	// stopGrace is how long a program has to exit after SIGTERM before it is killed.
+	stopGrace = 10 * time.Second
+)
+
+var (
+	// errStopping is returned when starting a program that is being stopped.
+	errStopping = errors.New("program is being stopped")
+	// errNotFound is returned when a program or package doesn't exist.
+	errNotFound = errors.New("not found")
+)
+
+// supervisor runs our programs and restarts them according to their RestartPolicy.
+// This replaces needing a process manager like systemd for the programs we install.
+type supervisor struct {
+	// logPath is the directory program output is written to.
+	logPath string
+
+	mu    sync.Mutex
+	progs map[string]*program
+}
+
+func newSupervisor(logPath string) *supervisor {
+	return &supervisor{logPath: logPath, progs: map[string]*program{}}
+}
+
+// start starts the program described by pc. If a program with the same name is
+// running, it is stopped first.
+func (s *supervisor) start(pc programConfig) error {
+	s.remove(pc.name)
+
+	p, err := newProgram(pc, s.logPath)
+	if err != nil {
+		return err
+	}
+	if err := p.launch(); err != nil {
+		p.closeLogs()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.progs[pc.name]; ok {
+		// p's process was started, so it must be run to be stopped and reaped.
+		go p.run()
+		go p.stop()
+		return fmt.Errorf("program(%s) was started by another request while starting", pc.name)
+	}
+	s.progs[pc.name] = p
+	go p.run()
+	return nil
+}
+
+// stop stops the program called name. It is still listed, in the stopped state.
+func (s *supervisor) stop(name string) error {
+	s.mu.Lock()
+	p, ok := s.progs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("program(%s): %w", name, errNotFound)
+	}
+	p.stop()
+	return nil
+}
+
+// running returns true if the program called name is running or will be restarted.
+func (s *supervisor) running(name string) bool {
+	p, ok := s.get(name)
+	if !ok {
+		return false
+	}
+	return p.State == msgs.StateRunning || p.State == msgs.StateBackoff
+}
+
+// remove stops the program called name and stops supervising it. If there is no such
+// program, this does nothing.
+func (s *supervisor) remove(name string) {
+	s.mu.Lock()
+	p, ok := s.progs[name]
+	delete(s.progs, name)
+	s.mu.Unlock()
+
+	if ok {
+		p.stop()
+	}
+}
+
+// list returns the status of all our programs, sorted by name.
+func (s *supervisor) list() []msgs.Program {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := make([]msgs.Program, 0, len(s.progs))
+	for _, p := range s.progs {
+		l = append(l, p.status())
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l
+}
+
+// get returns the status of the program called name.
+func (s *supervisor) get(name string) (msgs.Program, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.progs[name]
+	if !ok {
+		return msgs.Program{}, false
+	}
+	return p.status(), true
+}
+
+// programConfig describes a program to run.
+type programConfig struct {
+	// name is the name of the package the program is from.
+	name string
+	// binary is the path of the binary to run.
+	binary string
+	// args are the arguments to the binary.
+	args []string
+	// dir is the working directory of the program.
+	dir string
+	// restart is when to restart the program.
+	restart msgs.RestartPolicy
+}
+
+// program is a program being run by the supervisor.
+type program struct {
+	programConfig
+
+	stdout, stderr *rotatingFile
+
+	// stopCh is closed when the program is being stopped.
+	stopCh chan struct{}
+	// done is closed when run() has returned.
+	done chan struct{}
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd // The running process, nil if it isn't running.
+	stopping  bool
+	state     msgs.ProgramState
+	restarts  int
+	lastExit  int
+	startTime time.Time
+}
+
+func newProgram(pc programConfig, logPath string) (*program, error) {
+	if pc.restart == "" {
+		pc.restart = msgs.RestartAlways
+	}
+	if err := os.MkdirAll(logPath, 0770); err != nil {
+		return nil, fmt.Errorf("could not create log directory(%s): %w", logPath, err)
+	}
+
+	stdout, err := openRotating(filepath.Join(logPath, pc.name+".stdout.log"), maxLogSize, keepLogs)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := openRotating(filepath.Join(logPath, pc.name+".stderr.log"), maxLogSize, keepLogs)
+	if err != nil {
+		stdout.Close()
+		return nil, err
+	}
+
+	return &program{
+		programConfig: pc,
+		stdout:        stdout,
+		stderr:        stderr,
+		stopCh:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}, nil
+}
+
+// launch starts the program's process.
+func (p *program) launch() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopping {
+		return errStopping
+	}
+
+	cmd := exec.Command(p.binary, p.args...)
+	cmd.Dir = p.dir
+	cmd.Stdout = p.stdout
+	cmd.Stderr = p.stderr
+	if err := cmd.Start(); err != nil {
+		p.cmd, p.lastExit, p.state = nil, -1, msgs.StateFailed
+		return fmt.Errorf("could not start program(%s): %w", p.name, err)
+	}
+
+	p.cmd, p.state, p.startTime = cmd, msgs.StateRunning, time.Now()
+	log.Printf("started program(%s): pid %d", p.name, cmd.Process.Pid)
+	return nil
+}
+
+// run waits for the program to exit and restarts it as its RestartPolicy says, until
+// the program is stopped or is not to be restarted. launch() must have succeeded first.
+func (p *program) run() {
+	defer close(p.done)
+	defer p.closeLogs()
+
+	bo := &backoff{}
+	for {
+		ran := p.wait()
+
+		p.mu.Lock()
+		code, stopping := p.lastExit, p.stopping
+		p.mu.Unlock()
+
+		if stopping {
+			p.setState(msgs.StateStopped)
+			return
+		}
+		if !p.shouldRestart(code) {
+			if code == 0 {
+				p.setState(msgs.StateExited)
+			} else {
+				p.setState(msgs.StateFailed)
+			}
+			log.Printf("program(%s) exited(%d) and will not be restarted", p.name, code)
+			return
+		}
+
+		wait := bo.next(ran)
+		p.setState(msgs.StateBackoff)
+		log.Printf("program(%s) exited(%d), restarting in %v", p.name, code, wait)
+
+		select {
+		case <-p.stopCh:
+			p.setState(msgs.StateStopped)
+			return
+		case <-time.After(wait):
+		}
+
+		p.mu.Lock()
+		p.restarts++
+		p.mu.Unlock()
+
+		if err := p.launch(); err != nil && !errors.Is(err, errStopping) {
+			log.Println(err)
+		}
+	}
+}
+
+// wait waits for the running process to exit, records its exit code and returns how
+// long it ran. If the process isn't running, it returns immediately.
+func (p *program) wait() time.Duration {
+	p.mu.Lock()
+	cmd, start := p.cmd, p.startTime
+	p.mu.Unlock()
+
+	if cmd == nil {
+		return 0
+	}
+	// An error here is either the exit code, which we get from ProcessState, or
+	// a problem copying the output, which we can't do anything about.
+	cmd.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cmd = nil
+	p.lastExit = cmd.ProcessState.ExitCode()
+	return time.Since(start)
+}
+
+// backoff tracks how long to wait before restarting a program that keeps exiting.
+type backoff struct {
+	wait time.Duration
+}
+
+// next returns how long to wait before restarting a program that ran for ran.
+func (b *backoff) next(ran time.Duration) time.Duration {
+	if b.wait == 0 || ran >= stableRun {
+		b.wait = minBackoff
+	}
+	wait := b.wait
+	b.wait *= 2
+	if b.wait > maxBackoff {
+		b.wait = maxBackoff
+	}
+	return wait
+}
+
+// shouldRestart returns true if the program should be restarted after exiting with code.
+func (p *program) shouldRestart(code int) bool {
+	switch p.restart {
+	case msgs.RestartNever:
+		return false
+	case msgs.RestartOnFailure:
+		return code != 0
+	}
+	return true
+}
+
+// stop stops the program, sending SIGTERM and then killing it if it hasn't exited
+// after stopGrace. It returns once the program will no longer be restarted.
+func (p *program) stop() {
+	defer p.setState(msgs.StateStopped)
+
+	p.mu.Lock()
+	if p.stopping {
+		p.mu.Unlock()
+		<-p.done
+		return
+	}
+	p.stopping = true
+	close(p.stopCh)
+	if p.cmd != nil {
+		p.cmd.Process.Signal(syscall.SIGTERM)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return
+	case <-time.After(stopGrace):
+	}
+
+	p.mu.Lock()
+	if p.cmd != nil {
+		log.Printf("program(%s) did not exit after SIGTERM, killing it", p.name)
+		p.cmd.Process.Kill()
+	}
+	p.mu.Unlock()
+	<-p.done
+}
+
+func (p *program) setState(state msgs.ProgramState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state = state
+}
+
+func (p *program) closeLogs() {
+	p.stdout.Close()
+	p.stderr.Close()
+}
+
+// status returns the program's status.
+func (p *program) status() msgs.Program {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st := msgs.Program{
+		Name:         p.name,
+		Binary:       p.binary,
+		Args:         p.args,
+		Restart:      p.restart,
+		State:        p.state,
+		Restarts:     p.restarts,
+		LastExitCode: p.lastExit,
+		StartTime:    p.startTime.UnixNano(),
+		Stdout:       p.stdout.path,
+		Stderr:       p.stderr.path,
+	}
+	if p.cmd != nil {
+		st.PID = p.cmd.Process.Pid
+	}
+	return st
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// testProgram writes a shell script with body and returns a config to run it as program "prog".
+func testProgram(t *testing.T, body string, restart msgs.RestartPolicy) programConfig {
+	t.Helper()
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "prog")
+	if err := os.WriteFile(bin, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return programConfig{name: "prog", binary: bin, dir: dir, restart: restart}
+}
+
+// waitFor waits for ok to return true for the status of program name and returns the status.
+func waitFor(t *testing.T, s *supervisor, name string, ok func(p msgs.Program) bool) msgs.Program {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p, found := s.get(name)
+		if found && ok(p) {
+			return p
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("program(%s) never reached the wanted status, last status: %+v", name, p)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		desc string
+		// ran is how long the program ran before each exit.
+		ran  []time.Duration
+		want []time.Duration
+	}{
+		{
+			desc: "doubles up to the max",
+			ran:  []time.Duration{0, 0, 0, 0, 0, 0, 0, 0},
+			want: []time.Duration{minBackoff, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, maxBackoff, maxBackoff},
+		},
+		{
+			desc: "a stable run resets the backoff",
+			ran:  []time.Duration{0, 0, 0, stableRun, 0},
+			want: []time.Duration{minBackoff, 2 * time.Second, 4 * time.Second, minBackoff, 2 * time.Second},
+		},
+		{
+			desc: "a short run doesn't reset the backoff",
+			ran:  []time.Duration{0, stableRun - time.Second},
+			want: []time.Duration{minBackoff, 2 * time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		bo := &backoff{}
+		for i, ran := range test.ran {
+			if got := bo.next(ran); got != test.want[i] {
+				t.Errorf("TestBackoff(%s): exit %d: got wait %v, want %v", test.desc, i, got, test.want[i])
+			}
+		}
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	tests := []struct {
+		desc        string
+		restart     msgs.RestartPolicy
+		body        string
+		wantRestart bool
+		// wantState is the program's state if it isn't restarted.
+		wantState msgs.ProgramState
+	}{
+		{
+			desc:        "always restarts after success",
+			restart:     msgs.RestartAlways,
+			body:        "#!/bin/sh\nexit 0\n",
+			wantRestart: true,
+		},
+		{
+			desc:        "always restarts after failure",
+			restart:     msgs.RestartAlways,
+			body:        "#!/bin/sh\nexit 1\n",
+			wantRestart: true,
+		},
+		{
+			desc:        "default is always",
+			body:        "#!/bin/sh\nexit 0\n",
+			wantRestart: true,
+		},
+		{
+			desc:      "on-failure doesn't restart after success",
+			restart:   msgs.RestartOnFailure,
+			body:      "#!/bin/sh\nexit 0\n",
+			wantState: msgs.StateExited,
+		},
+		{
+			desc:        "on-failure restarts after failure",
+			restart:     msgs.RestartOnFailure,
+			body:        "#!/bin/sh\nexit 3\n",
+			wantRestart: true,
+		},
+		{
+			desc:      "never doesn't restart after failure",
+			restart:   msgs.RestartNever,
+			body:      "#!/bin/sh\nexit 3\n",
+			wantState: msgs.StateFailed,
+		},
+	}
+
+	for _, test := range tests {
+		s := newSupervisor(t.TempDir())
+		if err := s.start(testProgram(t, test.body, test.restart)); err != nil {
+			t.Fatalf("TestRestartPolicy(%s): got err == %s, want err == nil", test.desc, err)
+		}
+
+		if test.wantRestart {
+			// The first restart comes after minBackoff.
+			waitFor(t, s, "prog", func(p msgs.Program) bool { return p.Restarts > 0 })
+		} else {
+			p := waitFor(t, s, "prog", func(p msgs.Program) bool {
+				return p.State != msgs.StateRunning && p.State != msgs.StateBackoff
+			})
+			if p.State != test.wantState {
+				t.Errorf("TestRestartPolicy(%s): got state %s, want %s", test.desc, p.State, test.wantState)
+			}
+			if p.Restarts != 0 {
+				t.Errorf("TestRestartPolicy(%s): got %d restarts, want 0", test.desc, p.Restarts)
+			}
+		}
+		s.remove("prog")
+	}
+}
+
+func TestStopProgram(t *testing.T) {
+	s := newSupervisor(t.TempDir())
+	defer s.remove("prog")
+
+	if err := s.stop("prog"); !errors.Is(err, errNotFound) {
+		t.Errorf("TestStopProgram: stop() of a missing program: got err == %v, want errNotFound", err)
+	}
+
+	pc := testProgram(t, sleeper, msgs.RestartAlways)
+	if err := s.start(pc); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, s, "prog", func(p msgs.Program) bool { return p.PID != 0 })
+
+	// A stopped program is not restarted, even though its policy is to always restart.
+	if err := s.stop("prog"); err != nil {
+		t.Fatalf("TestStopProgram: got err == %s, want err == nil", err)
+	}
+	p, ok := s.get("prog")
+	if !ok {
+		t.Fatalf("TestStopProgram: program is no longer listed after stop()")
+	}
+	if p.State != msgs.StateStopped || p.PID != 0 {
+		t.Errorf("TestStopProgram: got state %s pid %d, want %s pid 0", p.State, p.PID, msgs.StateStopped)
+	}
+	if s.running("prog") {
+		t.Errorf("TestStopProgram: got running == true after stop(), want false")
+	}
+
+	// Stopping twice is fine.
+	if err := s.stop("prog"); err != nil {
+		t.Errorf("TestStopProgram: second stop(): got err == %s, want err == nil", err)
+	}
+
+	if err := s.start(pc); err != nil {
+		t.Fatalf("TestStopProgram: start() after stop(): got err == %s, want err == nil", err)
+	}
+	if !s.running("prog") {
+		t.Errorf("TestStopProgram: got running == false after start(), want true")
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archive/zip needs the directory at the end of a zip file before it can read any of it,
+// so uploads are unpacked with unzipStream(), which reads each file from the header that
+// comes before it. This lets us unpack an upload as its chunks are received.
+const (
+	localHeaderSig = 0x04034b50
+	dataDescSig    = 0x08074b50
+	directorySig   = 0x02014b50
+	endSig         = 0x06054b50
+
+	// zip64ExtraID is the extra field holding sizes too large for a header.
+	zip64ExtraID = 0x0001
+
+	flagEncrypted  = 0x1
+	flagDataDesc   = 0x8
+	uint32Overflow = math.MaxUint32
+)
+
+// localHeader is the header before each file in a zip, after its signature.
+type localHeader struct {
+	Version          uint16
+	Flags            uint16
+	Method           uint16
+	ModTime          uint16
+	ModDate          uint16
+	CRC32            uint32
+	CompressedSize   uint32
+	UncompressedSize uint32
+	NameLen          uint16
+	ExtraLen         uint16
+}
+
+// streamFile is a file that unzipStream() unpacked.
+type streamFile struct {
+	name string
+	crc  uint32
+	size uint64
+}
+
+// unzipStream unpacks the zip file read from r into dir, stopping at the zip's directory.
+// It has the same limits as unpack(). Files are written without execute permissions, as
+// a zip only has modes in its directory; setModes() must be called once the whole zip
+// is received.
+func unzipStream(r io.Reader, dir string) ([]streamFile, error) {
+	br := bufio.NewReader(r)
+
+	var (
+		files []streamFile
+		total int64
+	)
+	for {
+		var sig uint32
+		if err := binary.Read(br, binary.LittleEndian, &sig); err != nil {
+			return files, fmt.Errorf("could not read zip file: %w", err)
+		}
+		switch sig {
+		case localHeaderSig:
+		case directorySig, endSig:
+			return files, nil
+		default:
+			return files, errors.New("package is not a zip file")
+		}
+
+		if len(files) == maxUnpackFiles {
+			return files, fmt.Errorf("package has more than the %d files allowed", maxUnpackFiles)
+		}
+		f, n, err := unzipFile(br, dir, maxUnpackSize-total)
+		if err != nil {
+			return files, err
+		}
+		total += n
+		files = append(files, f)
+	}
+}
+
+// unzipFile unpacks the file after a local file header signature in br into dir. It
+// writes at most max bytes and returns the number of bytes written.
+func unzipFile(br *bufio.Reader, dir string, max int64) (streamFile, int64, error) {
+	h := localHeader{}
+	if err := binary.Read(br, binary.LittleEndian, &h); err != nil {
+		return streamFile{}, 0, fmt.Errorf("could not read zip file header: %w", err)
+	}
+	b := make([]byte, int(h.NameLen)+int(h.ExtraLen))
+	if _, err := io.ReadFull(br, b); err != nil {
+		return streamFile{}, 0, fmt.Errorf("could not read zip file header: %w", err)
+	}
+	f := streamFile{name: string(b[:h.NameLen]), crc: h.CRC32, size: uint64(h.UncompressedSize)}
+	csize := uint64(h.CompressedSize)
+	if h.UncompressedSize == uint32Overflow || h.CompressedSize == uint32Overflow {
+		f.size, csize = zip64Sizes(b[h.NameLen:], f.size, csize)
+	}
+
+	isDir := strings.HasSuffix(f.name, "/")
+	hasDesc := h.Flags&flagDataDesc != 0
+	switch {
+	case h.Flags&flagEncrypted != 0:
+		return f, 0, fmt.Errorf("file %q in package is encrypted", f.name)
+	case h.Method != zip.Store && h.Method != zip.Deflate:
+		return f, 0, fmt.Errorf("file %q in package uses unsupported compression method %d", f.name, h.Method)
+	case h.Method == zip.Store && hasDesc && !isDir:
+		// Without its size, there is no way to find the end of a file that isn't compressed.
+		return f, 0, fmt.Errorf("file %q in package is stored without its size, it must be compressed", f.name)
+	case !hasDesc && f.size > uint64(max):
+		return f, 0, fmt.Errorf("package unpacks to more than %d bytes", maxUnpackSize)
+	}
+
+	// If the file has a data descriptor, its compressed size comes after it. flate reads
+	// exactly the compressed data from an io.ByteReader, which tells us where it ends.
+	cr := &countReader{r: br}
+	var (
+		src io.Reader = cr
+		lr  *io.LimitedReader
+	)
+	if !hasDesc {
+		lr = &io.LimitedReader{R: cr, N: int64(csize)}
+		src = lr
+	}
+	if h.Method == zip.Deflate {
+		fr := flate.NewReader(src)
+		defer fr.Close()
+		src = fr
+	}
+	crc := crc32.NewIEEE()
+	src = io.TeeReader(src, crc)
+
+	mode := fs.FileMode(0600)
+	if isDir {
+		mode = fs.ModeDir | 0700
+	}
+	n, err := writeEntry(dir, f.name, mode, src, max)
+	if err != nil {
+		return f, n, err
+	}
+	if isDir {
+		if m, _ := io.CopyN(io.Discard, src, 1); m > 0 {
+			return f, n, fmt.Errorf("directory %q in package has content", f.name)
+		}
+	}
+	if lr != nil {
+		if _, err := io.Copy(io.Discard, lr); err != nil {
+			return f, n, fmt.Errorf("could not read file %q: %w", f.name, err)
+		}
+	}
+
+	if hasDesc {
+		if f.crc, f.size, csize, err = readDataDesc(br, cr.n, uint64(n)); err != nil {
+			return f, n, fmt.Errorf("file %q: %w", f.name, err)
+		}
+	}
+	if cr.n != csize || uint64(n) != f.size || crc.Sum32() != f.crc {
+		return f, n, fmt.Errorf("file %q in package is corrupt", f.name)
+	}
+	return f, n, nil
+}
+
+// readDataDesc reads the data descriptor after a file in a zip and returns the CRC-32 and
+// uncompressed and compressed sizes in it. Its sizes are 64 bits if the file needed them,
+// which we know from the compressed and uncompressed sizes we read, cSize and uSize.
+func readDataDesc(br *bufio.Reader, cSize, uSize uint64) (crc uint32, size, csize uint64, err error) {
+	// The signature is optional.
+	sig, err := br.Peek(4)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not read data descriptor: %w", err)
+	}
+	if binary.LittleEndian.Uint32(sig) == dataDescSig {
+		br.Discard(4)
+	}
+
+	if err := binary.Read(br, binary.LittleEndian, &crc); err != nil {
+		return 0, 0, 0, fmt.Errorf("could not read data descriptor: %w", err)
+	}
+	if cSize >= uint32Overflow || uSize >= uint32Overflow {
+		sizes := [2]uint64{}
+		if err := binary.Read(br, binary.LittleEndian, &sizes); err != nil {
+			return 0, 0, 0, fmt.Errorf("could not read data descriptor: %w", err)
+		}
+		return crc, sizes[1], sizes[0], nil
+	}
+	sizes := [2]uint32{}
+	if err := binary.Read(br, binary.LittleEndian, &sizes); err != nil {
+		return 0, 0, 0, fmt.Errorf("could not read data descriptor: %w", err)
+	}
+	return crc, uint64(sizes[1]), uint64(sizes[0]), nil
+}
+
+// zip64Sizes returns the uncompressed and compressed sizes of a file from the zip64 field
+// in its header's extra fields. Only sizes that didn't fit in the header, which are
+// uint32Overflow, are in it.
+func zip64Sizes(extra []byte, size, csize uint64) (uint64, uint64) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		n := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if n > len(extra) {
+			break
+		}
+		field := extra[:n]
+		extra = extra[n:]
+		if id != zip64ExtraID {
+			continue
+		}
+
+		if size == uint32Overflow && len(field) >= 8 {
+			size = binary.LittleEndian.Uint64(field)
+			field = field[8:]
+		}
+		if csize == uint32Overflow && len(field) >= 8 {
+			csize = binary.LittleEndian.Uint64(field)
+		}
+		break
+	}
+	return size, csize
+}
+
+// setModes checks the files that unzipStream() unpacked into dir against the directory of
+// the zip in pkg, which is size bytes, and sets the files' modes from it. Like unpack(),
+// it only allows regular files and directories.
+func setModes(pkg io.ReaderAt, size int64, dir string, files []streamFile) error {
+	r, err := zip.NewReader(pkg, size)
+	if err != nil {
+		return err
+	}
+	if len(r.File) != len(files) {
+		return fmt.Errorf("package directory has %d files, but the package has %d", len(r.File), len(files))
+	}
+
+	for i, z := range r.File {
+		f := files[i]
+		if z.Name != f.name || z.CRC32 != f.crc || z.UncompressedSize64 != f.size {
+			return fmt.Errorf("package directory does not match file %q in the package", f.name)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(z.Name))
+		mode := z.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.Chmod(p, mode.Perm()|0700); err != nil {
+				return err
+			}
+		case !mode.IsRegular():
+			return fmt.Errorf("file %q in package is not a regular file or directory", z.Name)
+		default:
+			if err := os.Chmod(p, mode.Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// countReader counts the bytes read from a bufio.Reader. It is an io.ByteReader, so that
+// flate doesn't read past the end of a file.
+type countReader struct {
+	r *bufio.Reader
+	n uint64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func (c *countReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeRawZip returns a zip file holding files, stored without compression and with their
+// sizes in the header before them, as zip tools that can seek do.
+func makeRawZip(t *testing.T, files []zipFile) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, f := range files {
+		fh := &zip.FileHeader{
+			Name:               f.name,
+			Method:             zip.Store,
+			CRC32:              crc32.ChecksumIEEE([]byte(f.body)),
+			CompressedSize64:   uint64(len(f.body)),
+			UncompressedSize64: uint64(len(f.body)),
+		}
+		fh.SetMode(0644)
+		fw, err := w.CreateRaw(fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// makeStoredZip returns a zip file holding files, stored without compression and with
+// their sizes after them, as archive/zip writes them.
+func makeStoredZip(t *testing.T, files []zipFile) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, f := range files {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUnzipStream(t *testing.T) {
+	manyFiles := make([]zipFile, maxUnpackFiles+1)
+	for i := range manyFiles {
+		manyFiles[i] = zipFile{name: fmt.Sprintf("f%d", i)}
+	}
+
+	good := []zipFile{
+		{name: "svc", mode: 0755, body: "#!/bin/sh\n"},
+		{name: "conf/", mode: fs.ModeDir | 0755},
+		{name: "conf/svc.json", body: "{}"},
+		{name: "empty"},
+	}
+	corrupt := makeRawZip(t, []zipFile{{name: "svc", body: "#!/bin/sh\n"}})
+	corrupt[bytes.Index(corrupt, []byte("#!"))] = 'X'
+
+	tests := []struct {
+		desc  string
+		pkg   []byte
+		files []zipFile // The files we expect to be unpacked.
+		// wantErr is set if unzipStream() fails and wantModeErr if setModes() does.
+		wantErr     bool
+		wantModeErr bool
+	}{
+		{
+			desc:  "compressed with sizes after files",
+			pkg:   makeZip(t, good),
+			files: good,
+		},
+		{
+			desc:  "stored with sizes before files",
+			pkg:   makeRawZip(t, []zipFile{{name: "svc", body: "#!/bin/sh\n"}, {name: "empty"}}),
+			files: []zipFile{{name: "svc", body: "#!/bin/sh\n"}, {name: "empty"}},
+		},
+		{
+			desc:    "stored with sizes after files",
+			pkg:     makeStoredZip(t, []zipFile{{name: "svc", body: "#!/bin/sh\n"}}),
+			wantErr: true,
+		},
+		{
+			desc:    "parent directory",
+			pkg:     makeZip(t, []zipFile{{name: "../evil", body: "x"}}),
+			wantErr: true,
+		},
+		{
+			desc:    "parent directory in the middle",
+			pkg:     makeRawZip(t, []zipFile{{name: "conf/../../evil", body: "x"}}),
+			wantErr: true,
+		},
+		{
+			desc:    "absolute path",
+			pkg:     makeZip(t, []zipFile{{name: "/tmp/evil", body: "x"}}),
+			wantErr: true,
+		},
+		{
+			desc:        "symlink",
+			pkg:         makeZip(t, []zipFile{{name: "evil", mode: fs.ModeSymlink | 0777, body: "/etc/passwd"}}),
+			wantModeErr: true,
+		},
+		{
+			desc:    "same file twice",
+			pkg:     makeZip(t, []zipFile{{name: "svc", body: "a"}, {name: "svc", body: "b"}}),
+			wantErr: true,
+		},
+		{
+			desc:    "too many files",
+			pkg:     makeZip(t, manyFiles),
+			wantErr: true,
+		},
+		{
+			desc:    "file does not match its checksum",
+			pkg:     corrupt,
+			wantErr: true,
+		},
+		{
+			desc:    "package is cut short",
+			pkg:     makeZip(t, good)[:60],
+			wantErr: true,
+		},
+		{
+			desc:    "not a zip",
+			pkg:     []byte("this is not a zip file"),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		dir := filepath.Join(t.TempDir(), "files")
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := unzipStream(bytes.NewReader(test.pkg), dir)
+		if _, err := os.Lstat(filepath.Join(filepath.Dir(dir), "evil")); err == nil {
+			t.Errorf("TestUnzipStream(%s): a file was written outside the package directory", test.desc)
+		}
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestUnzipStream(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestUnzipStream(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		err = setModes(bytes.NewReader(test.pkg), int64(len(test.pkg)), dir, files)
+		switch {
+		case err == nil && test.wantModeErr:
+			t.Errorf("TestUnzipStream(%s): setModes(): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantModeErr:
+			t.Errorf("TestUnzipStream(%s): setModes(): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		for _, f := range test.files {
+			p := filepath.Join(dir, filepath.FromSlash(f.name))
+			fi, err := os.Stat(p)
+			if err != nil {
+				t.Errorf("TestUnzipStream(%s): file(%s) was not unpacked: %s", test.desc, f.name, err)
+				continue
+			}
+			if fi.IsDir() {
+				continue
+			}
+			b, _ := os.ReadFile(p)
+			if string(b) != f.body {
+				t.Errorf("TestUnzipStream(%s): file(%s): got content %q, want %q", test.desc, f.name, b, f.body)
+			}
+			if f.mode != 0 && fi.Mode().Perm() != f.mode.Perm() {
+				t.Errorf("TestUnzipStream(%s): file(%s): got mode %v, want %v", test.desc, f.name, fi.Mode().Perm(), f.mode.Perm())
+			}
+		}
+	}
+}
+
+func TestSetModesMismatch(t *testing.T) {
+	pkg := makeZip(t, []zipFile{{name: "svc", body: "a"}, {name: "conf", body: "b"}})
+	dir := t.TempDir()
+	files, err := unzipStream(bytes.NewReader(pkg), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc  string
+		files []streamFile
+	}{
+		{desc: "file is missing", files: files[:1]},
+		{desc: "files are in a different order", files: []streamFile{files[1], files[0]}},
+		{desc: "file has a different size", files: []streamFile{files[0], {name: "conf", crc: files[1].crc, size: 2}}},
+	}
+
+	for _, test := range tests {
+		if err := setModes(bytes.NewReader(pkg), int64(len(pkg)), dir, test.files); err == nil {
+			t.Errorf("TestSetModesMismatch(%s): got err == nil, want err != nil", test.desc)
+		}
+	}
+}
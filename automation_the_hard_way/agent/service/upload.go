@@ -0,0 +1,503 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// Packages that are too large to send in an InstallReq are uploaded in chunks. An upload
+// is started with its manifest, which is everything in an InstallReq except the package.
+// Each chunk is appended to a file on disk, so the package is never held in memory, and
+// an upload that is interrupted can be resumed from what the agent has. As each chunk is
+// received it is unpacked into the upload's directory and added to the package's digest.
+// Once the last chunk is received, the digest and signature are verified and the
+// unpacked files are moved into place. Nothing that is unpacked runs before then.
+const (
+	// uploadDir is the directory in the Agent user's home where uploads are kept until
+	// they are installed.
+	uploadDir = "sa/uploads/"
+	// uploadManifest and uploadPackage are the files in an upload's directory.
+	uploadManifest = "manifest.json"
+	uploadPackage  = "package.zip"
+	// uploadFiles is the directory in an upload's directory the package is unpacked in.
+	uploadFiles = "files"
+
+	// maxUploadSize is the largest package that can be uploaded.
+	maxUploadSize = 4 * 1024 * 1024 * 1024 // 4GB
+	// maxChunkSize is the largest chunk that can be sent in one request.
+	maxChunkSize = 64 * 1024 * 1024 // 64MB
+	// uploadTTL is how long an upload that hasn't received a chunk is kept.
+	uploadTTL = 24 * time.Hour
+
+	// offsetHeader is the header that holds where in the package a chunk starts.
+	offsetHeader = "Upload-Offset"
+)
+
+// errUploadStopped is returned by a receiver whose upload was removed.
+var errUploadStopped = errors.New("upload was stopped")
+
+// validUploadID matches the IDs we make for uploads.
+var validUploadID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// StartUpload starts the upload of a package that will be sent in chunks.
+func (a *Agent) StartUpload(c *gin.Context) {
+	req := &msgs.UploadReq{}
+	if err := readReq(c.Request, 1024*1024, req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.UploadResp{ErrMsg: err.Error()})
+		return
+	}
+	if req.Size > maxUploadSize {
+		c.IndentedJSON(
+			http.StatusBadRequest,
+			msgs.UploadResp{ErrMsg: fmt.Sprintf("package is larger than the %d bytes allowed", int64(maxUploadSize))},
+		)
+		return
+	}
+
+	a.cleanUploads()
+
+	id, err := newUploadID()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, msgs.UploadResp{ErrMsg: err.Error()})
+		return
+	}
+	if err := a.createUpload(id, req); err != nil {
+		os.RemoveAll(a.uploadPath(id))
+		c.IndentedJSON(http.StatusInternalServerError, msgs.UploadResp{ErrMsg: err.Error()})
+		return
+	}
+	log.Printf("started upload(%s) of package(%s): %d bytes", id, req.Manifest.Name, req.Size)
+	c.IndentedJSON(http.StatusOK, msgs.UploadResp{ID: id})
+}
+
+// UploadChunk appends the chunk in the request body to an upload. The Upload-Offset
+// header must be where the chunk starts, which must be the end of what we have. Once
+// the whole package is received, it is installed.
+func (a *Agent) UploadChunk(c *gin.Context) {
+	id := c.Param("id")
+	if !a.lockUpload(id) {
+		c.IndentedJSON(http.StatusConflict, msgs.ChunkResp{ErrMsg: fmt.Sprintf("upload(%s) is receiving another chunk", id)})
+		return
+	}
+	defer a.unlockUpload(id)
+	defer c.Request.Body.Close()
+
+	req, offset, err := a.loadUpload(id)
+	if err != nil {
+		sendChunkError(c, offset, err)
+		return
+	}
+
+	start, err := strconv.ParseInt(c.GetHeader(offsetHeader), 10, 64)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.ChunkResp{Offset: offset, ErrMsg: offsetHeader + " header must be set"})
+		return
+	}
+	if start != offset {
+		c.IndentedJSON(
+			http.StatusConflict,
+			msgs.ChunkResp{Offset: offset, ErrMsg: fmt.Sprintf("chunk starts at %d, but we have %d bytes", start, offset)},
+		)
+		return
+	}
+
+	r, err := a.receiverFor(id, offset)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, msgs.ChunkResp{Offset: offset, ErrMsg: err.Error()})
+		return
+	}
+
+	offset, err = a.appendChunk(id, c.Request.Body, offset, req.Size)
+	// What we kept of the chunk is unpacked, even if the rest of it was cut short.
+	if offset > start {
+		if err := r.write(start, offset); err != nil {
+			// A package that can't be unpacked will never install, so we stop the upload now.
+			a.removeUpload(id)
+			c.IndentedJSON(
+				http.StatusBadRequest,
+				msgs.ChunkResp{Offset: offset, ErrMsg: fmt.Sprintf("could not unpack package: %s", err)},
+			)
+			return
+		}
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, msgs.ChunkResp{Offset: offset, ErrMsg: err.Error()})
+		return
+	}
+	if offset < req.Size {
+		c.IndentedJSON(http.StatusOK, msgs.ChunkResp{Offset: offset})
+		return
+	}
+
+	// We have the whole package. Whether or not it installs, the upload is done.
+	defer a.removeUpload(id)
+
+	if status, err := a.installUpload(id, req, r); err != nil {
+		c.IndentedJSON(status, msgs.ChunkResp{Offset: offset, ErrMsg: err.Error()})
+		return
+	}
+	log.Printf("installed upload(%s) of package(%s)", id, req.Manifest.Name)
+	c.IndentedJSON(http.StatusOK, msgs.ChunkResp{Offset: offset, Installed: true})
+}
+
+// UploadStatus returns how much of an upload we have, so that it can be resumed.
+func (a *Agent) UploadStatus(c *gin.Context) {
+	_, offset, err := a.loadUpload(c.Param("id"))
+	if err != nil {
+		sendChunkError(c, offset, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.ChunkResp{Offset: offset})
+}
+
+// CancelUpload stops an upload and removes what we have of it.
+func (a *Agent) CancelUpload(c *gin.Context) {
+	id := c.Param("id")
+	if !a.lockUpload(id) {
+		c.IndentedJSON(http.StatusConflict, msgs.ChunkResp{ErrMsg: fmt.Sprintf("upload(%s) is receiving a chunk", id)})
+		return
+	}
+	defer a.unlockUpload(id)
+
+	if _, _, err := a.loadUpload(id); err != nil {
+		sendChunkError(c, 0, err)
+		return
+	}
+	if err := a.removeUpload(id); err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, msgs.ChunkResp{ErrMsg: err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, msgs.ChunkResp{})
+}
+
+// installUpload installs the package of the upload with id, which r has unpacked.
+// If there is an error, this returns the HTTP status code for it.
+func (a *Agent) installUpload(id string, req *msgs.UploadReq, r *receiver) (int, error) {
+	files, sum, err := r.close()
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("could not unpack package: %w", err)
+	}
+
+	if err := verifyDigest(req.Manifest.SHA256, sum); err != nil {
+		return http.StatusForbidden, err
+	}
+	if err := a.verifySignature(&req.Manifest); err != nil {
+		return http.StatusForbidden, err
+	}
+
+	f, err := os.Open(filepath.Join(a.uploadPath(id), uploadPackage))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer f.Close()
+
+	from := filepath.Join(a.uploadPath(id), uploadFiles)
+	if err := setModes(f, req.Size, from, files); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return a.activate(&req.Manifest, from)
+}
+
+// removeUpload stops unpacking the upload with id and removes it.
+func (a *Agent) removeUpload(id string) error {
+	a.uploadMu.Lock()
+	r := a.receivers[id]
+	delete(a.receivers, id)
+	a.uploadMu.Unlock()
+
+	if r != nil {
+		r.stop()
+	}
+	return os.RemoveAll(a.uploadPath(id))
+}
+
+// receiverFor returns the receiver unpacking the upload with id. If there isn't one, such
+// as after the agent restarts, one is started from the offset bytes we have.
+func (a *Agent) receiverFor(id string, offset int64) (*receiver, error) {
+	a.uploadMu.Lock()
+	defer a.uploadMu.Unlock()
+
+	if r, ok := a.receivers[id]; ok {
+		return r, nil
+	}
+	r, err := newReceiver(a.uploadPath(id), offset)
+	if err != nil {
+		return nil, err
+	}
+	a.receivers[id] = r
+	return r, nil
+}
+
+// receiver unpacks an upload's package as its chunks are received.
+type receiver struct {
+	f *os.File
+	// chunks sends the receiver the chunks to unpack. Once it has unpacked all it can of a
+	// chunk, it replies on unpacked.
+	chunks   chan io.Reader
+	unpacked chan struct{}
+	stopped  chan struct{}
+	done     chan struct{}
+
+	// cur is the chunk being unpacked. Only the receiver's goroutine uses it.
+	cur io.Reader
+
+	// These are set before done is closed.
+	files []streamFile
+	sum   []byte
+	err   error
+}
+
+// newReceiver starts unpacking the package of the upload in dir into its files
+// directory. The first offset bytes of the package, which we already have, are read
+// from disk. The rest must be sent with write().
+func newReceiver(dir string, offset int64) (*receiver, error) {
+	files := filepath.Join(dir, uploadFiles)
+	if err := os.RemoveAll(files); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(files, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, uploadPackage))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &receiver{
+		f:        f,
+		chunks:   make(chan io.Reader),
+		unpacked: make(chan struct{}),
+		stopped:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+
+		h := sha256.New()
+		in := io.TeeReader(io.MultiReader(io.NewSectionReader(f, 0, offset), r), h)
+		r.files, r.err = unzipStream(in, files)
+		if r.err == nil {
+			// The zip's directory isn't unpacked, but it is part of the digest.
+			_, r.err = io.Copy(io.Discard, in)
+		}
+		r.sum = h.Sum(nil)
+	}()
+	return r, nil
+}
+
+// Read implements io.Reader for the receiver's goroutine, reading the chunks sent with
+// write(). It only asks for the next chunk once the last one is used up, which is
+// when we have unpacked all we can of it.
+func (r *receiver) Read(p []byte) (int, error) {
+	for {
+		if r.cur != nil {
+			n, err := r.cur.Read(p)
+			if n > 0 {
+				return n, nil
+			}
+			if err != io.EOF {
+				return 0, err
+			}
+			r.cur = nil
+			r.unpacked <- struct{}{}
+		}
+
+		select {
+		case c, ok := <-r.chunks:
+			if !ok {
+				return 0, io.EOF
+			}
+			r.cur = c
+		case <-r.stopped:
+			return 0, errUploadStopped
+		}
+	}
+}
+
+// write unpacks bytes [start, end) of the package, which have been written to disk. It
+// returns once they are unpacked, with an error if the package can't be.
+func (r *receiver) write(start, end int64) error {
+	select {
+	case r.chunks <- io.NewSectionReader(r.f, start, end-start):
+	case <-r.done:
+		return r.err
+	}
+	select {
+	case <-r.unpacked:
+		return nil
+	case <-r.done:
+		return r.err
+	}
+}
+
+// close is called when the whole package has been written. It returns the files that were
+// unpacked and the package's SHA-256 digest.
+func (r *receiver) close() ([]streamFile, []byte, error) {
+	close(r.chunks)
+	<-r.done
+	r.f.Close()
+	return r.files, r.sum, r.err
+}
+
+// stop stops unpacking the package.
+func (r *receiver) stop() {
+	close(r.stopped)
+	<-r.done
+	r.f.Close()
+}
+
+// uploadPath returns the directory the upload with id is kept in.
+func (a *Agent) uploadPath(id string) string {
+	return filepath.Join(a.homePath, uploadDir, id)
+}
+
+// createUpload creates the directory for the upload with id, holding its manifest and
+// an empty package.
+func (a *Agent) createUpload(id string, req *msgs.UploadReq) error {
+	dir := a.uploadPath(id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, uploadManifest), b, 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, uploadPackage), nil, 0600)
+}
+
+// loadUpload reads the manifest of the upload with id and returns it with how many bytes
+// of the package we have.
+func (a *Agent) loadUpload(id string) (*msgs.UploadReq, int64, error) {
+	if !validUploadID.MatchString(id) {
+		return nil, 0, fmt.Errorf("upload(%s): %w", id, errNotFound)
+	}
+	dir := a.uploadPath(id)
+
+	b, err := os.ReadFile(filepath.Join(dir, uploadManifest))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, fmt.Errorf("upload(%s): %w", id, errNotFound)
+		}
+		return nil, 0, err
+	}
+	req := &msgs.UploadReq{}
+	if err := json.Unmarshal(b, req); err != nil {
+		return nil, 0, fmt.Errorf("upload(%s) manifest is corrupt: %w", id, err)
+	}
+
+	fi, err := os.Stat(filepath.Join(dir, uploadPackage))
+	if err != nil {
+		return nil, 0, err
+	}
+	return req, fi.Size(), nil
+}
+
+// appendChunk appends the chunk in r to the package of the upload with id, which has
+// offset of its size bytes. It returns the new offset. If the chunk is cut short, what
+// was received is kept so that the upload can resume from there. A chunk that is too
+// large is not kept.
+func (a *Agent) appendChunk(id string, r io.Reader, offset, size int64) (int64, error) {
+	f, err := os.OpenFile(filepath.Join(a.uploadPath(id), uploadPackage), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+
+	limit := size - offset
+	if limit > maxChunkSize {
+		limit = maxChunkSize
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit))
+	offset += n
+	if err != nil {
+		return offset, fmt.Errorf("chunk was cut short: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return offset, err
+	}
+
+	// Anything after the limit is past the end of the package or too large a chunk, so
+	// none of the chunk is kept.
+	if m, _ := r.Read(make([]byte, 1)); m > 0 {
+		start := offset - n
+		if err := f.Truncate(start); err != nil {
+			return offset, err
+		}
+		return start, fmt.Errorf("chunk is larger than %d bytes or goes past the package's size", limit)
+	}
+	return offset, nil
+}
+
+// lockUpload marks the upload with id as busy. It returns false if it already is.
+func (a *Agent) lockUpload(id string) bool {
+	a.uploadMu.Lock()
+	defer a.uploadMu.Unlock()
+
+	if a.uploading[id] {
+		return false
+	}
+	a.uploading[id] = true
+	return true
+}
+
+func (a *Agent) unlockUpload(id string) {
+	a.uploadMu.Lock()
+	defer a.uploadMu.Unlock()
+
+	delete(a.uploading, id)
+}
+
+// cleanUploads removes uploads that have not received a chunk in uploadTTL.
+func (a *Agent) cleanUploads() {
+	entries, err := os.ReadDir(filepath.Join(a.homePath, uploadDir))
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		fi, err := os.Stat(filepath.Join(a.uploadPath(e.Name()), uploadPackage))
+		if err != nil || time.Since(fi.ModTime()) < uploadTTL {
+			continue
+		}
+		if !a.lockUpload(e.Name()) {
+			continue
+		}
+		log.Printf("removing upload(%s) that was not finished", e.Name())
+		a.removeUpload(e.Name())
+		a.unlockUpload(e.Name())
+	}
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func sendChunkError(c *gin.Context, offset int64, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, errNotFound) {
+		status = http.StatusNotFound
+	}
+	c.IndentedJSON(status, msgs.ChunkResp{Offset: offset, ErrMsg: err.Error()})
+}
@@ -0,0 +1,577 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// uploadTester sends uploads to an Agent that trusts key.
+type uploadTester struct {
+	t      *testing.T
+	a      *Agent
+	router *gin.Engine
+	key    ed25519.PrivateKey
+}
+
+func newUploadTester(t *testing.T) *uploadTester {
+	t.Helper()
+
+	pub, key := newKey(t)
+	a := testAgent(t)
+	a.TrustedKeys = t.TempDir()
+	writePublicKey(t, filepath.Join(a.TrustedKeys, "key.pub"), pub)
+	t.Cleanup(func() { a.sup.remove("pkg") })
+
+	gin.SetMode(gin.TestMode)
+	ut := &uploadTester{t: t, a: a, router: gin.New(), key: key}
+	ut.routes()
+	return ut
+}
+
+// routes registers the upload handlers, like New() does.
+func (ut *uploadTester) routes() {
+	ut.router.POST("/api/v1.0.0/uploads", ut.a.StartUpload)
+	ut.router.PUT("/api/v1.0.0/uploads/:id", ut.a.UploadChunk)
+	ut.router.GET("/api/v1.0.0/uploads/:id", ut.a.UploadStatus)
+}
+
+// restart replaces the Agent with a new one using the same home directory, as if the
+// agent was restarted.
+func (ut *uploadTester) restart() {
+	old := ut.a
+	for _, r := range old.receivers {
+		r.stop()
+	}
+	ut.a = &Agent{
+		homePath:    old.homePath,
+		sup:         old.sup,
+		uploading:   map[string]bool{},
+		receivers:   map[string]*receiver{},
+		TrustedKeys: old.TrustedKeys,
+	}
+	ut.router = gin.New()
+	ut.routes()
+}
+
+// manifest returns a signed manifest for pkg.
+func (ut *uploadTester) manifest(pkg []byte) msgs.InstallReq {
+	sum := sha256.Sum256(pkg)
+	m := msgs.InstallReq{
+		Name:    "pkg",
+		Binary:  "svc",
+		Restart: msgs.RestartNever,
+		SHA256:  hex.EncodeToString(sum[:]),
+	}
+	m.Signature = signReq(ut.t, ut.key, m)
+	return m
+}
+
+func (ut *uploadTester) do(method, path string, header http.Header, body io.Reader, resp any) int {
+	ut.t.Helper()
+
+	req := httptest.NewRequest(method, path, body)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	ut.router.ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		ut.t.Fatalf("%s %s: response is not JSON: %q", method, path, w.Body.String())
+	}
+	return w.Code
+}
+
+// start starts an upload of size bytes with manifest m and returns its ID.
+func (ut *uploadTester) start(m msgs.InstallReq, size int64) string {
+	ut.t.Helper()
+
+	b, err := json.Marshal(msgs.UploadReq{Manifest: m, Size: size})
+	if err != nil {
+		ut.t.Fatal(err)
+	}
+	resp := msgs.UploadResp{}
+	if code := ut.do(http.MethodPost, "/api/v1.0.0/uploads", nil, bytes.NewReader(b), &resp); code != http.StatusOK {
+		ut.t.Fatalf("StartUpload: got status %d, want %d: %s", code, http.StatusOK, resp.ErrMsg)
+	}
+	return resp.ID
+}
+
+// chunk sends the chunk read from r, which starts at offset.
+func (ut *uploadTester) chunk(id string, offset int64, r io.Reader) (int, msgs.ChunkResp) {
+	ut.t.Helper()
+
+	h := http.Header{}
+	h.Set(offsetHeader, strconv.FormatInt(offset, 10))
+	resp := msgs.ChunkResp{}
+	code := ut.do(http.MethodPut, "/api/v1.0.0/uploads/"+id, h, r, &resp)
+	return code, resp
+}
+
+func (ut *uploadTester) status(id string) (int, msgs.ChunkResp) {
+	ut.t.Helper()
+
+	resp := msgs.ChunkResp{}
+	code := ut.do(http.MethodGet, "/api/v1.0.0/uploads/"+id, nil, nil, &resp)
+	return code, resp
+}
+
+// testPackage returns a package with a program and a file that doesn't compress, so that
+// the program is at the start of the package and the file's data is most of it.
+func testPackage(t *testing.T) []byte {
+	t.Helper()
+
+	data := make([]byte, 64*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return makeZip(
+		t,
+		[]zipFile{
+			{name: "svc", mode: 0755, body: "#!/bin/sh\nexec sleep 30\n"},
+			{name: "conf/", mode: fs.ModeDir | 0755},
+			{name: "conf/data", body: string(data)},
+		},
+	)
+}
+
+// checkInstalled checks that pkg was installed as package "pkg".
+func checkInstalled(t *testing.T, a *Agent, pkg []byte) {
+	t.Helper()
+
+	version, err := a.currentVersion("pkg")
+	if err != nil || version == "" {
+		t.Fatalf("package was not installed: version(%s), err(%v)", version, err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, z := range r.File {
+		fi, err := os.Stat(filepath.Join(a.versionPath("pkg", version), z.Name))
+		if err != nil {
+			t.Errorf("file(%s) was not installed: %s", z.Name, err)
+			continue
+		}
+		if fi.Mode().Perm()&^0700 != z.Mode().Perm()&^0700 {
+			t.Errorf("file(%s): got mode %v, want %v", z.Name, fi.Mode().Perm(), z.Mode().Perm())
+		}
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		desc      string
+		chunkSize int
+	}{
+		{desc: "one chunk", chunkSize: maxChunkSize},
+		{desc: "chunks split headers", chunkSize: 7},
+		{desc: "larger chunks", chunkSize: 4096},
+	}
+
+	for _, test := range tests {
+		ut := newUploadTester(t)
+		pkg := testPackage(t)
+		id := ut.start(ut.manifest(pkg), int64(len(pkg)))
+
+		var offset int64
+		for offset < int64(len(pkg)) {
+			end := min(offset+int64(test.chunkSize), int64(len(pkg)))
+			code, resp := ut.chunk(id, offset, bytes.NewReader(pkg[offset:end]))
+			if code != http.StatusOK {
+				t.Fatalf("TestUpload(%s): chunk at %d: got status %d: %s", test.desc, offset, code, resp.ErrMsg)
+			}
+			if resp.Offset != end {
+				t.Fatalf("TestUpload(%s): chunk at %d: got offset %d, want %d", test.desc, offset, resp.Offset, end)
+			}
+			if resp.Installed != (end == int64(len(pkg))) {
+				t.Fatalf("TestUpload(%s): chunk at %d: got installed == %v", test.desc, offset, resp.Installed)
+			}
+			offset = end
+		}
+		checkInstalled(t, ut.a, pkg)
+
+		if _, err := os.Stat(ut.a.uploadPath(id)); err == nil {
+			t.Errorf("TestUpload(%s): upload was not removed after it was installed", test.desc)
+		}
+	}
+}
+
+// TestUploadUnpacksChunks tests that a package is unpacked as it is received, not after
+// the last chunk.
+func TestUploadUnpacksChunks(t *testing.T) {
+	ut := newUploadTester(t)
+	pkg := testPackage(t)
+	id := ut.start(ut.manifest(pkg), int64(len(pkg)))
+
+	half := int64(len(pkg) / 2)
+	if code, resp := ut.chunk(id, 0, bytes.NewReader(pkg[:half])); code != http.StatusOK {
+		t.Fatalf("TestUploadUnpacksChunks: got status %d: %s", code, resp.ErrMsg)
+	}
+
+	// The program is at the start of the package, so it must be unpacked by now.
+	files := filepath.Join(ut.a.uploadPath(id), uploadFiles)
+	b, err := os.ReadFile(filepath.Join(files, "svc"))
+	if err != nil {
+		t.Fatalf("TestUploadUnpacksChunks: program was not unpacked after the first chunk: %s", err)
+	}
+	if !bytes.HasPrefix(b, []byte("#!/bin/sh")) {
+		t.Errorf("TestUploadUnpacksChunks: got program %q", b)
+	}
+	// Nothing is executable until the package is verified.
+	if fi, _ := os.Stat(filepath.Join(files, "svc")); fi.Mode()&0111 != 0 {
+		t.Errorf("TestUploadUnpacksChunks: got mode %v before the package was verified, want no execute", fi.Mode())
+	}
+
+	if code, resp := ut.chunk(id, half, bytes.NewReader(pkg[half:])); code != http.StatusOK || !resp.Installed {
+		t.Fatalf("TestUploadUnpacksChunks: last chunk: got status %d, installed %v: %s", code, resp.Installed, resp.ErrMsg)
+	}
+	checkInstalled(t, ut.a, pkg)
+}
+
+func TestUploadBadPackage(t *testing.T) {
+	tests := []struct {
+		desc  string
+		files []zipFile
+		pkg   []byte // Used instead of files if set.
+		// change changes the manifest.
+		change     func(ut *uploadTester, m *msgs.InstallReq)
+		wantStatus int
+		// wantEarly is true if the first chunk is rejected, before the rest is sent.
+		wantEarly bool
+	}{
+		{
+			desc:       "parent directory",
+			files:      []zipFile{{name: "../evil", body: "x"}},
+			wantStatus: http.StatusBadRequest,
+			wantEarly:  true,
+		},
+		{
+			desc:       "absolute path",
+			files:      []zipFile{{name: "/tmp/evil", body: "x"}},
+			wantStatus: http.StatusBadRequest,
+			wantEarly:  true,
+		},
+		{
+			desc:       "not a zip",
+			pkg:        bytes.Repeat([]byte("not a zip file"), 1000),
+			wantStatus: http.StatusBadRequest,
+			wantEarly:  true,
+		},
+		{
+			// A symlink's mode is only in the zip's directory, so this is found at the end.
+			desc:       "symlink",
+			files:      []zipFile{{name: "svc", mode: fs.ModeSymlink | 0777, body: "/bin/sh"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "same file twice",
+			files:      []zipFile{{name: "svc", body: "a"}, {name: "svc", body: "b"}},
+			wantStatus: http.StatusBadRequest,
+			wantEarly:  true,
+		},
+		{
+			desc:  "wrong sha256",
+			files: []zipFile{{name: "svc", mode: 0755, body: "#!/bin/sh\n"}},
+			change: func(ut *uploadTester, m *msgs.InstallReq) {
+				m.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:  "not signed",
+			files: []zipFile{{name: "svc", mode: 0755, body: "#!/bin/sh\n"}},
+			change: func(ut *uploadTester, m *msgs.InstallReq) {
+				m.Signature = nil
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:  "signed by an untrusted key",
+			files: []zipFile{{name: "svc", mode: 0755, body: "#!/bin/sh\n"}},
+			change: func(ut *uploadTester, m *msgs.InstallReq) {
+				_, other := newKey(ut.t)
+				m.Signature = signReq(ut.t, other, *m)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:  "args changed after signing",
+			files: []zipFile{{name: "svc", mode: 0755, body: "#!/bin/sh\n"}},
+			change: func(ut *uploadTester, m *msgs.InstallReq) {
+				m.Args = []string{"-c", "echo owned"}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		ut := newUploadTester(t)
+		pkg := test.pkg
+		if pkg == nil {
+			pkg = makeZip(t, test.files)
+		}
+		m := ut.manifest(pkg)
+		if test.change != nil {
+			test.change(ut, &m)
+		}
+		id := ut.start(m, int64(len(pkg)))
+
+		// All but the last byte, so that we can tell if the package is rejected early.
+		last := int64(len(pkg) - 1)
+		code, resp := ut.chunk(id, 0, bytes.NewReader(pkg[:last]))
+		if test.wantEarly {
+			if code != test.wantStatus {
+				t.Errorf("TestUploadBadPackage(%s): first chunk: got status %d, want %d", test.desc, code, test.wantStatus)
+			}
+			if code, _ := ut.status(id); code != http.StatusNotFound {
+				t.Errorf("TestUploadBadPackage(%s): got status %d for the upload, want it to be removed", test.desc, code)
+			}
+			continue
+		}
+		if code != http.StatusOK {
+			t.Errorf("TestUploadBadPackage(%s): first chunk: got status %d, want %d: %s", test.desc, code, http.StatusOK, resp.ErrMsg)
+			continue
+		}
+
+		code, resp = ut.chunk(id, last, bytes.NewReader(pkg[last:]))
+		if code != test.wantStatus || resp.Installed {
+			t.Errorf("TestUploadBadPackage(%s): got status %d, installed %v, want status %d", test.desc, code, resp.Installed, test.wantStatus)
+		}
+		if v, _ := ut.a.currentVersion("pkg"); v != "" {
+			t.Errorf("TestUploadBadPackage(%s): package was installed", test.desc)
+		}
+		if _, err := os.Stat(ut.a.uploadPath(id)); err == nil {
+			t.Errorf("TestUploadBadPackage(%s): upload was not removed", test.desc)
+		}
+	}
+}
+
+func TestUploadOffsetMismatch(t *testing.T) {
+	ut := newUploadTester(t)
+	pkg := testPackage(t)
+	id := ut.start(ut.manifest(pkg), int64(len(pkg)))
+
+	if code, resp := ut.chunk(id, 0, bytes.NewReader(pkg[:100])); code != http.StatusOK {
+		t.Fatalf("TestUploadOffsetMismatch: got status %d: %s", code, resp.ErrMsg)
+	}
+
+	tests := []struct {
+		desc   string
+		offset int64
+	}{
+		{desc: "chunk was already sent", offset: 0},
+		{desc: "chunk is in the middle of what we have", offset: 50},
+		{desc: "chunk skips data", offset: 200},
+	}
+
+	for _, test := range tests {
+		code, resp := ut.chunk(id, test.offset, bytes.NewReader(pkg[test.offset:test.offset+10]))
+		if code != http.StatusConflict {
+			t.Errorf("TestUploadOffsetMismatch(%s): got status %d, want %d", test.desc, code, http.StatusConflict)
+		}
+		if resp.Offset != 100 {
+			t.Errorf("TestUploadOffsetMismatch(%s): got offset %d, want 100", test.desc, resp.Offset)
+		}
+	}
+
+	// The chunks that were refused didn't change the upload.
+	if code, resp := ut.chunk(id, 100, bytes.NewReader(pkg[100:])); code != http.StatusOK || !resp.Installed {
+		t.Fatalf("TestUploadOffsetMismatch: got status %d, installed %v: %s", code, resp.Installed, resp.ErrMsg)
+	}
+	checkInstalled(t, ut.a, pkg)
+}
+
+// zeros reads zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestUploadChunkTooLarge(t *testing.T) {
+	tests := []struct {
+		desc  string
+		size  int64
+		chunk int64
+	}{
+		{desc: "chunk goes past the package's size", size: 1000, chunk: 1001},
+		{desc: "chunk is larger than maxChunkSize", size: 2 * maxChunkSize, chunk: maxChunkSize + 1},
+	}
+
+	for _, test := range tests {
+		ut := newUploadTester(t)
+		id := ut.start(ut.manifest([]byte("package")), test.size)
+
+		code, resp := ut.chunk(id, 0, io.LimitReader(zeros{}, test.chunk))
+		if code != http.StatusBadRequest {
+			t.Errorf("TestUploadChunkTooLarge(%s): got status %d, want %d", test.desc, code, http.StatusBadRequest)
+		}
+		if resp.Offset != 0 {
+			t.Errorf("TestUploadChunkTooLarge(%s): got offset %d, want 0", test.desc, resp.Offset)
+		}
+
+		// None of the chunk is kept.
+		fi, err := os.Stat(filepath.Join(ut.a.uploadPath(id), uploadPackage))
+		if err != nil {
+			t.Fatalf("TestUploadChunkTooLarge(%s): upload was removed: %s", test.desc, err)
+		}
+		if fi.Size() != 0 {
+			t.Errorf("TestUploadChunkTooLarge(%s): got %d bytes kept, want 0", test.desc, fi.Size())
+		}
+		if code, resp := ut.status(id); code != http.StatusOK || resp.Offset != 0 {
+			t.Errorf("TestUploadChunkTooLarge(%s): got status %d, offset %d, want %d, 0", test.desc, code, resp.Offset, http.StatusOK)
+		}
+	}
+}
+
+// cutReader reads from r, then fails.
+type cutReader struct {
+	r io.Reader
+}
+
+func (c cutReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestUploadResume(t *testing.T) {
+	tests := []struct {
+		desc    string
+		restart bool
+	}{
+		{desc: "agent keeps running"},
+		{desc: "agent restarts", restart: true},
+	}
+
+	for _, test := range tests {
+		ut := newUploadTester(t)
+		pkg := testPackage(t)
+		id := ut.start(ut.manifest(pkg), int64(len(pkg)))
+
+		// The connection is lost after part of the chunk is sent.
+		const sent = 1000
+		code, resp := ut.chunk(id, 0, cutReader{bytes.NewReader(pkg[:sent])})
+		if code != http.StatusBadRequest {
+			t.Errorf("TestUploadResume(%s): cut chunk: got status %d, want %d", test.desc, code, http.StatusBadRequest)
+		}
+		if resp.Offset != sent {
+			t.Errorf("TestUploadResume(%s): cut chunk: got offset %d, want %d", test.desc, resp.Offset, sent)
+		}
+
+		if test.restart {
+			ut.restart()
+		}
+
+		code, resp = ut.status(id)
+		if code != http.StatusOK {
+			t.Fatalf("TestUploadResume(%s): status: got status %d: %s", test.desc, code, resp.ErrMsg)
+		}
+		if resp.Offset != sent {
+			t.Fatalf("TestUploadResume(%s): status: got offset %d, want %d", test.desc, resp.Offset, sent)
+		}
+
+		code, resp = ut.chunk(id, resp.Offset, bytes.NewReader(pkg[resp.Offset:]))
+		if code != http.StatusOK || !resp.Installed {
+			t.Fatalf("TestUploadResume(%s): got status %d, installed %v: %s", test.desc, code, resp.Installed, resp.ErrMsg)
+		}
+		checkInstalled(t, ut.a, pkg)
+	}
+}
+
+func TestCleanUploads(t *testing.T) {
+	ut := newUploadTester(t)
+	pkg := testPackage(t)
+
+	old := ut.start(ut.manifest(pkg), int64(len(pkg)))
+	// The old upload is being unpacked when it is removed.
+	if code, resp := ut.chunk(old, 0, bytes.NewReader(pkg[:100])); code != http.StatusOK {
+		t.Fatalf("TestCleanUploads: got status %d: %s", code, resp.ErrMsg)
+	}
+	stale := time.Now().Add(-uploadTTL - time.Minute)
+	if err := os.Chtimes(filepath.Join(ut.a.uploadPath(old), uploadPackage), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting an upload cleans up old ones.
+	current := ut.start(ut.manifest(pkg), int64(len(pkg)))
+
+	if code, _ := ut.status(old); code != http.StatusNotFound {
+		t.Errorf("TestCleanUploads: old upload: got status %d, want %d", code, http.StatusNotFound)
+	}
+	if _, err := os.Stat(ut.a.uploadPath(old)); err == nil {
+		t.Errorf("TestCleanUploads: old upload's directory was not removed")
+	}
+	if len(ut.a.receivers) != 0 {
+		t.Errorf("TestCleanUploads: old upload is still being unpacked")
+	}
+	if code, _ := ut.status(current); code != http.StatusOK {
+		t.Errorf("TestCleanUploads: current upload: got status %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestStartUploadBinary(t *testing.T) {
+	tests := []struct {
+		desc    string
+		binary  string
+		wantErr bool
+	}{
+		{desc: "file in the package", binary: "svc"},
+		{desc: "parent directory", binary: "../../../../bin/sh", wantErr: true},
+		{desc: "absolute path", binary: "/bin/sh", wantErr: true},
+		{desc: "file in a directory", binary: "bin/svc", wantErr: true},
+		{desc: "package directory", binary: ".", wantErr: true},
+	}
+
+	for _, test := range tests {
+		ut := newUploadTester(t)
+		m := ut.manifest([]byte("package"))
+		m.Binary = test.binary
+		// The binary is signed, so a bad one can only come from a trusted signer.
+		m.Signature = signReq(t, ut.key, m)
+
+		b, err := json.Marshal(msgs.UploadReq{Manifest: m, Size: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp := msgs.UploadResp{}
+		code := ut.do(http.MethodPost, "/api/v1.0.0/uploads", nil, bytes.NewReader(b), &resp)
+		switch {
+		case code == http.StatusOK && test.wantErr:
+			t.Errorf("TestStartUploadBinary(%s): got status %d, want %d", test.desc, code, http.StatusBadRequest)
+		case code != http.StatusOK && !test.wantErr:
+			t.Errorf("TestStartUploadBinary(%s): got status %d, want %d: %s", test.desc, code, http.StatusOK, resp.ErrMsg)
+		}
+	}
+}
+
+func TestStartProgramBinary(t *testing.T) {
+	// A package's record is read from disk, so startProgram() checks its binary too.
+	a := testAgent(t)
+	err := a.startProgram(msgs.Package{Name: "pkg", Version: "1", Binary: "../../../../bin/sh", Args: []string{"-c", "true"}})
+	if err == nil {
+		a.sup.remove("pkg")
+		t.Errorf("TestStartProgramBinary: got err == nil, want err != nil")
+	}
+}
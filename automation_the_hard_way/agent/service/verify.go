@@ -0,0 +1,135 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// keysDir is the directory in the Agent user's home that holds the public keys we
+// trust to sign packages, if Agent.TrustedKeys isn't set.
+const keysDir = "sa/trusted_keys/"
+
+// verifyDigest checks that hexDigest is the SHA-256 digest in sum.
+func verifyDigest(hexDigest string, sum []byte) error {
+	want, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return fmt.Errorf("sha256 is not hex encoded: %w", err)
+	}
+	if subtle.ConstantTimeCompare(want, sum) != 1 {
+		return fmt.Errorf("package does not match its sha256 digest")
+	}
+	return nil
+}
+
+// verifySignature checks that req.Signature is a signature of req.SignedBytes() by one of
+// our trusted keys. This covers the package's digest, which must be checked against the
+// package with verifyDigest(), and how the package is run. If the agent allows unsigned
+// packages, a missing signature is accepted.
+func (a *Agent) verifySignature(req *msgs.InstallReq) error {
+	if len(req.Signature) == 0 {
+		if a.AllowUnsigned {
+			log.Println("installing a package that isn't signed")
+			return nil
+		}
+		return fmt.Errorf("package must be signed")
+	}
+
+	msg, err := req.SignedBytes()
+	if err != nil {
+		return err
+	}
+	keys, err := a.trustedKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if ed25519.Verify(k, msg, req.Signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("package signature is not from a trusted key")
+}
+
+// trustedKeys reads the public keys in our trusted keys directory. These are read for
+// every install, so that keys can be added and removed without restarting the agent.
+// Each key is a PEM encoded ed25519 public key, such as made by:
+//
+//	openssl genpkey -algorithm ed25519 -out key.pem
+//	openssl pkey -in key.pem -pubout -out key.pub
+func (a *Agent) trustedKeys() ([]ed25519.PublicKey, error) {
+	dir := a.TrustedKeys
+	if dir == "" {
+		dir = filepath.Join(a.homePath, keysDir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("could not read trusted keys directory(%s): %w", dir, err)
+	}
+
+	var keys []ed25519.PublicKey
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		k, err := readPublicKey(p)
+		if err != nil {
+			log.Printf("skipping trusted key(%s): %s", p, err)
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("there are no trusted keys in %s", dir)
+	}
+	return keys, nil
+}
+
+// readPublicKey reads a PEM encoded ed25519 public key from the file at p.
+func readPublicKey(p string) (ed25519.PublicKey, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("not a PEM encoded public key")
+	}
+	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := k.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key is a %T, not ed25519", k)
+	}
+	return pub, nil
+}
+
+// verifyPackage checks the package read from pkg against the digest and signature in req.
+func (a *Agent) verifyPackage(pkg io.Reader, req *msgs.InstallReq) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, pkg); err != nil {
+		return fmt.Errorf("could not read package: %w", err)
+	}
+	sum := h.Sum(nil)
+	if err := verifyDigest(req.SHA256, sum); err != nil {
+		return err
+	}
+	return a.verifySignature(req)
+}
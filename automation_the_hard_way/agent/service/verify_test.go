@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/msgs"
+)
+
+// newKey returns a new ed25519 key pair.
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+// writePublicKey writes pub as a PEM encoded key to the file at p.
+func writePublicKey(t *testing.T, p string, pub ed25519.PublicKey) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(p, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signReq returns the signature of req by key.
+func signReq(t *testing.T, key ed25519.PrivateKey, req msgs.InstallReq) []byte {
+	t.Helper()
+
+	b, err := req.SignedBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed25519.Sign(key, b)
+}
+
+func TestVerifyPackage(t *testing.T) {
+	pkg := []byte("package content")
+	sum := sha256.Sum256(pkg)
+	digest := hex.EncodeToString(sum[:])
+
+	trustedPub, trusted := newKey(t)
+	_, untrusted := newKey(t)
+
+	keys := t.TempDir()
+	writePublicKey(t, filepath.Join(keys, "trusted.pub"), trustedPub)
+	// Files that aren't keys are skipped.
+	if err := os.WriteFile(filepath.Join(keys, "README"), []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := msgs.InstallReq{
+		Name:    "pkg",
+		Version: "1.0.0",
+		SHA256:  digest,
+		Binary:  "svc",
+		Args:    []string{"-v"},
+		Restart: msgs.RestartNever,
+	}
+	goodSig := signReq(t, trusted, manifest)
+	badSig := bytes.Clone(goodSig)
+	badSig[0] ^= 0xff
+
+	tests := []struct {
+		desc   string
+		digest string
+		sig    []byte
+		// change changes the manifest after it is signed.
+		change        func(req *msgs.InstallReq)
+		keys          string
+		allowUnsigned bool
+		wantErr       bool
+	}{
+		{
+			desc:   "signed by a trusted key",
+			digest: digest,
+			sig:    goodSig,
+			keys:   keys,
+		},
+		{
+			desc:    "wrong sha256",
+			digest:  hex.EncodeToString(make([]byte, sha256.Size)),
+			sig:     goodSig,
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "sha256 is not hex",
+			digest:  "not hex",
+			sig:     goodSig,
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "sha256 is missing",
+			sig:     goodSig,
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "missing signature",
+			digest:  digest,
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:          "missing signature is allowed",
+			digest:        digest,
+			keys:          keys,
+			allowUnsigned: true,
+		},
+		{
+			desc:          "wrong signature when unsigned is allowed",
+			digest:        digest,
+			sig:           badSig,
+			keys:          keys,
+			allowUnsigned: true,
+			wantErr:       true,
+		},
+		{
+			desc:    "wrong signature",
+			digest:  digest,
+			sig:     badSig,
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "signed by an untrusted key",
+			digest:  digest,
+			sig:     signReq(t, untrusted, manifest),
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "only the digest is signed",
+			digest:  digest,
+			sig:     ed25519.Sign(trusted, sum[:]),
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "binary changed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Binary = "../../../../bin/sh" },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "args changed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Args = []string{"-c", "rm -rf ~"} },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "args removed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Args = nil },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "name changed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Name = "other" },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "version changed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Version = "0.0.1" },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:    "restart policy changed",
+			digest:  digest,
+			sig:     goodSig,
+			change:  func(req *msgs.InstallReq) { req.Restart = msgs.RestartAlways },
+			keys:    keys,
+			wantErr: true,
+		},
+		{
+			desc:   "empty args are the same as no args",
+			digest: digest,
+			sig:    signReq(t, trusted, msgs.InstallReq{Name: "pkg", SHA256: digest, Binary: "svc"}),
+			change: func(req *msgs.InstallReq) {
+				*req = msgs.InstallReq{Name: "pkg", SHA256: digest, Binary: "svc", Args: []string{}, Signature: req.Signature}
+			},
+			keys: keys,
+		},
+		{
+			desc:    "no trusted keys",
+			digest:  digest,
+			sig:     goodSig,
+			keys:    t.TempDir(),
+			wantErr: true,
+		},
+		{
+			desc:    "trusted keys directory does not exist",
+			digest:  digest,
+			sig:     goodSig,
+			keys:    filepath.Join(keys, "doesNotExist"),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := &Agent{homePath: t.TempDir(), TrustedKeys: test.keys, AllowUnsigned: test.allowUnsigned}
+
+		req := manifest
+		req.SHA256 = test.digest
+		req.Signature = test.sig
+		if test.change != nil {
+			test.change(&req)
+		}
+
+		err := a.verifyPackage(bytes.NewReader(pkg), &req)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestVerifyPackage(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestVerifyPackage(%s): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
+
+func TestTrustedKeysDefault(t *testing.T) {
+	pub, priv := newKey(t)
+	a := &Agent{homePath: t.TempDir()}
+
+	if err := os.MkdirAll(filepath.Join(a.homePath, keysDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writePublicKey(t, filepath.Join(a.homePath, keysDir, "key.pub"), pub)
+
+	sum := sha256.Sum256([]byte("package content"))
+	req := &msgs.InstallReq{Name: "pkg", SHA256: hex.EncodeToString(sum[:]), Binary: "svc"}
+	req.Signature = signReq(t, priv, *req)
+	if err := a.verifySignature(req); err != nil {
+		t.Errorf("TestTrustedKeysDefault: got err == %s, want err == nil", err)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -19,7 +20,10 @@ import (
 	"time"
 
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/events"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/journal"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/client"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/transport"
 )
 
 // StateFn is a function that represents a state in the workflow.
@@ -27,6 +31,13 @@ type StateFn func(ctx context.Context) (StateFn, error)
 
 // Actions is the set of actions to take on a single endpoint, one at a time.
 type Actions struct {
+	// Events, if set, receives an Event when the endpoint enters a state, finishes,
+	// fails or is rolled back.
+	Events events.Handler
+	// Weight, if set, is the weight the backend is added back to the load balancer with
+	// once it is upgraded. The pool must be PT_WEIGHTED.
+	Weight int32
+
 	// endpoint is the machine to connect to.
 	endpoint string
 	// backend is the backend configuraiton in the load balancer.
@@ -40,30 +51,56 @@ type Actions struct {
 	dst string
 	// lb is the load balancer client.
 	lb *client.Client
+	// journal records our state transitions. This can be nil.
+	journal *journal.Journal
+	// t is used to run commands and copy files on the endpoint.
+	t transport.Transport
 
 	// started indicates if the workflow has started.
 	started bool
+	// retrying indicates that Run() started at failedState, either from Resume() or
+	// because a previous Run() failed.
+	retrying bool
+	// done indicates that all states have completed.
+	done bool
 	// failedState is the state to start at if we have a failure.
 	failedState StateFn
 	// err is the error that caused the failure.
 	err error
+
+	// rbState is the rollback state to start at if a rollback failed.
+	rbState StateFn
+	// rbErr is the error that caused a rollback to fail.
+	rbErr error
 }
 
-// New creates a new Actions.
-func New(endpoint string, cfg *config.Config, lb *client.Client) (*Actions, error) {
+// New creates a new Actions. If j is not nil, all state transitions are recorded to it.
+func New(endpoint string, cfg *config.Config, lb *client.Client, j *journal.Journal) (*Actions, error) {
 	ip, port, err := config.CheckIPPort(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	t, err := transport.New(cfg.Transport, ip.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not create transport for endpoint(%s): %w", endpoint, err)
+	}
+
 	return &Actions{
 		endpoint: endpoint,
 		backend:  client.IPBackend{IP: ip, Port: port},
 		config:   cfg,
 		lb:       lb,
+		journal:  j,
+		t:        t,
 	}, nil
 }
 
+// Close closes the connection to the endpoint.
+func (a *Actions) Close() error {
+	return a.t.Close()
+}
+
 // Endpoint returns the endpoint this Actions is for.
 func (a *Actions) Endpoint() string {
 	return a.endpoint
@@ -74,42 +111,180 @@ func (a *Actions) Err() error {
 	return a.err
 }
 
+// Done returns true if all the states have completed.
+func (a *Actions) Done() bool {
+	return a.done
+}
+
+// Touched returns true if the Actions may have made changes to the endpoint, either
+// in this run or a previous run recovered with Resume().
+func (a *Actions) Touched() bool {
+	if a.dst == "" {
+		return false
+	}
+	return a.started || a.done || a.failedState != nil || a.rbState != nil
+}
+
+// RollingBack returns true if Resume() found that the endpoint was in the middle
+// of a rollback. Run() should not be called, instead Rollback() should be.
+func (a *Actions) RollingBack() bool {
+	return a.rbState != nil
+}
+
+// RollbackErr returns the error that caused a rollback to fail.
+func (a *Actions) RollbackErr() error {
+	return a.rbErr
+}
+
+// Resume sets up the Actions to start at the state recorded in e instead of
+// the beginning. This must be called before Run().
+func (a *Actions) Resume(e journal.Entry) error {
+	if a.started {
+		return fmt.Errorf("cannot resume endpoint(%s) after it has started", a.endpoint)
+	}
+	if e.Endpoint != a.endpoint {
+		return fmt.Errorf("journal entry is for endpoint(%s), not %s", e.Endpoint, a.endpoint)
+	}
+
+	a.dst = e.Dst
+	switch e.State {
+	case journal.StateDone:
+		a.done = true
+		return nil
+	case journal.StateRolledBack:
+		// The endpoint is back to where it started.
+		return nil
+	}
+
+	if fn, ok := a.rollbackStates()[e.State]; ok {
+		a.rbState = fn
+		return nil
+	}
+	fn, ok := a.states()[e.State]
+	if !ok {
+		return fmt.Errorf("journal entry for endpoint(%s) has unknown state %q", a.endpoint, e.State)
+	}
+	a.failedState = fn
+	return nil
+}
+
 // Run runs the workflow.
 func (a *Actions) Run(ctx context.Context) (err error) {
+	if a.done {
+		return nil
+	}
+	if a.rbState != nil {
+		return fmt.Errorf("endpoint(%s) is in the middle of a rollback", a.endpoint)
+	}
+
 	a.srcf, err = os.Open(a.config.Src)
 	if err != nil {
 		a.err = fmt.Errorf("cannot open binary to copy(%s): %w", a.config.Src, err)
+		a.emit(events.Event{Type: events.EndpointFailed, Err: a.err.Error()})
 		return a.err
 	}
+	defer a.srcf.Close()
 
 	fn := a.findAppLocal
-	if a.failedState != nil {
+	a.retrying = a.failedState != nil
+	if a.retrying {
 		fn = a.failedState
 	}
 
 	a.started = true
+	a.err = nil
+	if fn, err = a.run(ctx, fn); err != nil {
+		a.failedState = fn
+		a.err = err
+		a.emit(events.Event{Type: events.EndpointFailed, State: stateName(fn), Err: err.Error()})
+		return err
+	}
+	a.done = true
+	a.failedState = nil
+	a.emit(events.Event{Type: events.EndpointDone})
+	return a.record(journal.StateDone, nil)
+}
+
+// Rollback puts the previous binary back on an endpoint that Touched() and returns it
+// to the load balancer. It stops the job, restores the backup of the binary made before
+// it was overwritten, starts the job and waits for it to be healthy before adding it back
+// into the load balancer. If we failed before the job was stopped, the job is left running
+// and is only added back into the load balancer. If Touched() is false, this does nothing.
+func (a *Actions) Rollback(ctx context.Context) error {
+	if !a.Touched() {
+		return nil
+	}
+
+	fn := a.rbRmBackend
+	if a.rbState != nil {
+		fn = a.rbState
+	}
+
+	a.rbErr = nil
+	if fn, err := a.run(ctx, fn); err != nil {
+		a.rbState = fn
+		a.rbErr = err
+		a.emit(events.Event{Type: events.RollbackFailed, State: stateName(fn), Err: err.Error()})
+		return err
+	}
+	a.rbState = nil
+	a.done = false
+	a.started = false
+	a.failedState = nil
+	a.err = nil
+	a.emit(events.Event{Type: events.EndpointRolledBack})
+	return a.record(journal.StateRolledBack, nil)
+}
+
+// run runs states starting with fn until a state returns a nil StateFn. If a state
+// returns an error, that state and the error are returned.
+func (a *Actions) run(ctx context.Context, fn StateFn) (StateFn, error) {
 	for {
 		if ctx.Err() != nil {
-			a.err = ctx.Err()
-			return ctx.Err()
+			a.record(stateName(fn), ctx.Err())
+			return fn, ctx.Err()
+		}
+		if err := a.record(stateName(fn), nil); err != nil {
+			return fn, err
 		}
-		fn, err = fn(ctx)
+		a.emit(events.Event{Type: events.StateEntered, State: stateName(fn)})
+		next, err := fn(ctx)
 		if err != nil {
-			a.failedState = fn
-			a.err = err
-			return err
+			a.record(stateName(fn), err)
+			return fn, err
 		}
-		if fn == nil {
-			return nil
+		if next == nil {
+			return nil, nil
 		}
+		fn = next
+	}
+}
+
+// record records a state transition to our journal.
+func (a *Actions) record(state string, err error) error {
+	e := journal.Entry{
+		Endpoint: a.endpoint,
+		State:    state,
+		Dst:      a.dst,
+	}
+	if err != nil {
+		e.Err = err.Error()
 	}
+	return a.journal.Record(e)
 }
 
-// findAppLocal finds the app on the local machine by querying the /installedAt URL.
+// emit sends e to our Events handler, if set.
+func (a *Actions) emit(e events.Event) {
+	if a.Events == nil {
+		return
+	}
+	e.Time = time.Now()
+	e.Endpoint = a.endpoint
+	a.Events(e)
+}
+
+// findAppLocal finds the app on the endpoint by querying the /installedAt URL.
 // In real life, you would do this in a more robust way, but this is just a demo.
-// The orignal version of this has a hardcoded path to the binary and we did this with SFTP.
-// However, to avoid all the SSH setup and do this all on your local machine, we are using
-// a little bandaid here.
 func (a *Actions) findAppLocal(ctx context.Context) (StateFn, error) {
 	c := &http.Client{}
 
@@ -142,8 +317,28 @@ func (a *Actions) findAppLocal(ctx context.Context) (StateFn, error) {
 		return nil, errors.New("findAppLocal() returned nothing")
 	}
 
-	a.dst = strings.TrimSpace(string(b))
-	a.dst = filepath.Clean(a.dst)
+	dst := filepath.Clean(strings.TrimSpace(string(b)))
+
+	// A backup left by an older rollout must never be restored by a rollback of this one,
+	// which could happen if we fail before backup() replaces it. We only set a.dst once
+	// it is gone, as Touched() is false until a.dst is set.
+	if err := a.removeRemote(ctx, dst+backupSuffix); err != nil {
+		return nil, fmt.Errorf("could not remove old backup binary: %w", err)
+	}
+	a.dst = dst
+	return a.drainBackend, nil
+}
+
+// drainBackend stops the load balancer from sending new requests to the backend and
+// waits for the requests it has to finish, so that jobKill() doesn't cut them off.
+func (a *Actions) drainBackend(ctx context.Context) (StateFn, error) {
+	inFlight, err := a.lb.DrainBackend(ctx, a.config.Pattern, a.backend, a.config.DrainTimeout())
+	if err != nil {
+		return nil, fmt.Errorf("problem draining backend: %w", err)
+	}
+	if inFlight > 0 {
+		log.Printf("backend(%s) still had %d requests in-flight after draining", a.endpoint, inFlight)
+	}
 	return a.rmBackend, nil
 }
 
@@ -170,39 +365,142 @@ func (a *Actions) jobKill(ctx context.Context) (StateFn, error) {
 	}
 
 	if pid == "" {
+		// A previous attempt may have killed the job before it failed or we died.
+		if a.retrying {
+			return a.backup, nil
+		}
 		return nil, fmt.Errorf("could not locate a job for backend: %s", a.endpoint)
 	}
 
-	if err := a.killPID(ctx, pid, SIGTERM); err != nil {
-		return nil, fmt.Errorf("failed to kill existing PIDs: %w", err)
+	if err := a.stopPID(ctx, pid); err != nil {
+		return nil, err
 	}
+	return a.backup, nil
+}
 
-	if err := a.waitForDeath(ctx, pid, 30*time.Second); err != nil {
-		if err := a.killPID(ctx, pid, SIGKILL); err != nil {
-			return nil, fmt.Errorf("failed to kill existing PIDs: %w", err)
-		}
-		if err := a.waitForDeath(ctx, pid, 10*time.Second); err != nil {
-			return nil, fmt.Errorf("failed to kill existing PIDs after -9: %w", err)
-		}
-		return a.cp, nil
+// backup copies the existing binary on the remote machine so that Rollback() can restore it.
+func (a *Actions) backup(ctx context.Context) (StateFn, error) {
+	if err := a.copyRemote(ctx, a.dst, a.backupPath()); err != nil {
+		return nil, fmt.Errorf("could not backup existing binary: %w", err)
 	}
 	return a.cp, nil
 }
 
+// backupSuffix is added to the binary's path to get the path of its backup.
+const backupSuffix = ".prev"
+
+// backupPath is the path on the remote machine where the previous binary is kept.
+func (a *Actions) backupPath() string {
+	return a.dst + backupSuffix
+}
+
 // cp copies the binary to the remote machine.
 func (a *Actions) cp(ctx context.Context) (StateFn, error) {
-	dstf, err := os.OpenFile(a.dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0770)
+	if err := a.t.CopyFile(ctx, a.srcf, a.dst, 0770); err != nil {
+		return nil, err
+	}
+	return a.jobStart, nil
+}
+
+// copyRemote copies the file at src to dst on the remote machine, keeping its mode.
+func (a *Actions) copyRemote(ctx context.Context, src, dst string) error {
+	cmd := fmt.Sprintf("cp -p %s %s", transport.Quote(src), transport.Quote(dst))
+	if out, err := a.t.Run(ctx, cmd); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// removeRemote removes the file at p on the remote machine. It is not an error if p
+// doesn't exist.
+func (a *Actions) removeRemote(ctx context.Context, p string) error {
+	if out, err := a.t.Run(ctx, "rm -f "+transport.Quote(p)); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// rbRmBackend removes the backend from the load balancer as the first step of a rollback.
+// Removing a backend that isn't in the pool is not an error.
+func (a *Actions) rbRmBackend(ctx context.Context) (StateFn, error) {
+	if _, err := a.rmBackend(ctx); err != nil {
+		return nil, err
+	}
+	return a.rbJobKill, nil
+}
+
+// rbJobKill kills the job on the remote machine if it is running. If the job is running
+// and there is no backup, we failed before jobKill() stopped it and the binary was never
+// replaced. That job is left running and the states that restore the binary are skipped.
+func (a *Actions) rbJobKill(ctx context.Context) (StateFn, error) {
+	pid, err := a.findPID(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not open new file on remote destination(%s): %w", a.dst, err)
+		return nil, fmt.Errorf("problem finding existing PIDs: %w", err)
+	}
+	if pid == "" {
+		return a.restore, nil
 	}
-	defer dstf.Close()
 
-	_, err = io.Copy(dstf, a.srcf)
+	backedUp, err := a.hasBackup(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("SFTP failed to do a complete copy: %w", err)
+		return nil, err
 	}
+	if !backedUp {
+		return a.rbAddBackend, nil
+	}
+	if err := a.stopPID(ctx, pid); err != nil {
+		return nil, err
+	}
+	return a.restore, nil
+}
 
-	return a.jobStart, nil
+// hasBackup returns true if backup() made a backup of the binary. Any backup from an
+// older rollout was removed by findAppLocal(), so a backup here is ours.
+func (a *Actions) hasBackup(ctx context.Context) (bool, error) {
+	if _, err := a.t.Stat(ctx, a.backupPath()); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not stat backup binary(%s): %w", a.backupPath(), err)
+	}
+	return true, nil
+}
+
+// restore copies the backup of the previous binary over the new binary. If there is
+// no backup, the binary was never replaced and there is nothing to restore.
+func (a *Actions) restore(ctx context.Context) (StateFn, error) {
+	backedUp, err := a.hasBackup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !backedUp {
+		return a.rbJobStart, nil
+	}
+
+	if err := a.copyRemote(ctx, a.backupPath(), a.dst); err != nil {
+		return nil, fmt.Errorf("could not restore backup binary: %w", err)
+	}
+	return a.rbJobStart, nil
+}
+
+// rbJobStart starts the restored binary and waits for it to be reachable.
+func (a *Actions) rbJobStart(ctx context.Context) (StateFn, error) {
+	if err := a.runBinary(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start binary after restore: %w", err)
+	}
+	if _, err := a.reachable(ctx); err != nil {
+		return nil, err
+	}
+	return a.rbAddBackend, nil
+}
+
+// rbAddBackend adds the backend back to the load balancer after a rollback. This uses
+// the default weight, as the old binary should get its normal share of traffic.
+func (a *Actions) rbAddBackend(ctx context.Context) (StateFn, error) {
+	if err := a.lb.AddBackend(ctx, a.config.Pattern, a.backend); err != nil {
+		return nil, err
+	}
+	return nil, nil
 }
 
 // jobStart starts the binary on the remote machine.
@@ -259,7 +557,7 @@ func (a *Actions) reachable(ctx context.Context) (StateFn, error) {
 
 // addBackend adds the backend to the load balancer.
 func (a *Actions) addBackend(ctx context.Context) (StateFn, error) {
-	err := a.lb.AddBackend(ctx, a.config.Pattern, a.backend)
+	err := a.lb.AddWeightedBackend(ctx, a.config.Pattern, a.backend, a.Weight)
 	if err != nil {
 		return nil, err
 	}
@@ -267,24 +565,131 @@ func (a *Actions) addBackend(ctx context.Context) (StateFn, error) {
 	return nil, nil
 }
 
-// findPID finds the PIDs of the running binary.
+// SetWeight sets the weight of the backend in the load balancer. The pool must be PT_WEIGHTED.
+func (a *Actions) SetWeight(ctx context.Context, weight int32) error {
+	if err := a.lb.SetBackendWeight(ctx, a.config.Pattern, a.backend, weight); err != nil {
+		return fmt.Errorf("could not set weight of backend(%s) to %d: %w", a.endpoint, weight, err)
+	}
+	a.Weight = weight
+	return nil
+}
+
+// findPID finds the PID of the running binary. We first look in the pidfile that runBinary()
+// writes. If there isn't one, such as when the binary was not started by us, we look for a
+// process whose executable is exactly our binary. A process that just has our binary's name
+// in its command line is not a match. If the binary isn't running, this returns "".
 func (a *Actions) findPID(ctx context.Context) (string, error) {
-	serviceName := path.Base(a.dst)
-	cmdStr := fmt.Sprintf(`ps -A | grep %s | grep -v grep | awk '{print $1}'`, serviceName)
-	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmdStr)
-	result, err := cmd.CombinedOutput()
+	pid, err := a.readPIDFile(ctx)
 	if err != nil {
 		return "", err
 	}
+	if pid != "" {
+		ours, err := a.isOurs(ctx, pid)
+		if err != nil {
+			return "", err
+		}
+		if ours {
+			return pid, nil
+		}
+		// The pidfile is stale, so the binary died or was restarted by someone else.
+	}
 
-	pid := strings.TrimSpace(string(result))
-	if strings.Contains(pid, "\n") {
-		return "", fmt.Errorf("found more than one pid with name %q", a.dst)
+	out, err := a.t.Run(ctx, "ps -A -o pid= -o command=")
+	if err != nil {
+		return "", err
+	}
+
+	var pids []string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if a.isBinary(fields[1]) {
+			pids = append(pids, fields[0])
+		}
+	}
+	switch len(pids) {
+	case 0:
+		return "", nil
+	case 1:
+		return pids[0], nil
+	}
+	return "", fmt.Errorf("found more than one process running %q: %v", a.dst, pids)
+}
+
+// pidPath is the path on the remote machine of the pidfile for the binary.
+func (a *Actions) pidPath() string {
+	return a.dst + ".pid"
+}
+
+// readPIDFile reads the PID in our pidfile. If there is no pidfile, this returns "".
+func (a *Actions) readPIDFile(ctx context.Context) (string, error) {
+	if _, err := a.t.Stat(ctx, a.pidPath()); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("could not stat pidfile(%s): %w", a.pidPath(), err)
+	}
+
+	out, err := a.t.Run(ctx, "cat "+transport.Quote(a.pidPath()))
+	if err != nil {
+		return "", fmt.Errorf("could not read pidfile(%s): %w", a.pidPath(), err)
+	}
+	pid := strings.TrimSpace(string(out))
+	if _, err := strconv.Atoi(pid); err != nil {
+		// A pidfile we can't read is the same as not having one.
+		return "", nil
 	}
 	return pid, nil
 }
 
-// killPID sends the PIDs the given signal.
+// isOurs returns true if pid is running and is our binary. This protects us from
+// signaling a process that reused the PID after our binary died.
+func (a *Actions) isOurs(ctx context.Context, pid string) (bool, error) {
+	out, err := a.t.Run(ctx, fmt.Sprintf("ps -p %s -o command=", transport.Quote(pid)))
+	fields := strings.Fields(string(out))
+	if err != nil {
+		// ps exits with an error and no output if the pid isn't running.
+		if len(fields) == 0 {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get command for pid(%s): %w", pid, err)
+	}
+	if len(fields) == 0 {
+		return false, nil
+	}
+	return a.isBinary(fields[0]), nil
+}
+
+// isBinary returns true if the executable p is our binary.
+func (a *Actions) isBinary(p string) bool {
+	return path.Clean(p) == path.Clean(a.dst)
+}
+
+// stopPID sends pid a SIGTERM and waits for it to die. If it doesn't die, it sends a SIGKILL.
+// Once it is dead, the pidfile is removed.
+func (a *Actions) stopPID(ctx context.Context, pid string) error {
+	if err := a.killPID(ctx, pid, SIGTERM); err != nil {
+		return fmt.Errorf("failed to kill existing PIDs: %w", err)
+	}
+
+	if err := a.waitForDeath(ctx, pid, 30*time.Second); err != nil {
+		if err := a.killPID(ctx, pid, SIGKILL); err != nil {
+			return fmt.Errorf("failed to kill existing PIDs: %w", err)
+		}
+		if err := a.waitForDeath(ctx, pid, 10*time.Second); err != nil {
+			return fmt.Errorf("failed to kill existing PIDs after -9: %w", err)
+		}
+	}
+
+	if _, err := a.t.Run(ctx, "rm -f "+transport.Quote(a.pidPath())); err != nil {
+		return fmt.Errorf("could not remove pidfile(%s): %w", a.pidPath(), err)
+	}
+	return nil
+}
+
+// killPID sends the pid the given signal if it is still our binary.
 func (a *Actions) killPID(ctx context.Context, pid string, signal syscall.Signal) error {
 	switch signal {
 	case SIGTERM, SIGKILL:
@@ -292,14 +697,24 @@ func (a *Actions) killPID(ctx context.Context, pid string, signal syscall.Signal
 	default:
 		return fmt.Errorf("sent killPID a non-termination signal: %d", signal)
 	}
-	cmd := exec.CommandContext(ctx, "kill", "-"+strconv.Itoa(int(signal)), pid)
-	if _, err := cmd.CombinedOutput(); err != nil {
+
+	ours, err := a.isOurs(ctx, pid)
+	if err != nil {
+		return err
+	}
+	if !ours {
+		return nil
+	}
+
+	cmdStr := fmt.Sprintf("kill -%d %s", int(signal), transport.Quote(pid))
+	if _, err := a.t.Run(ctx, cmdStr); err != nil {
 		return fmt.Errorf("problem kiling pid %s: %w", pid, err)
 	}
 	return nil
 }
 
-// waitForDeath waits for the pid to die or times out.
+// waitForDeath waits for the pid to die or times out. The pid is dead if it is no longer
+// running our binary.
 func (a *Actions) waitForDeath(ctx context.Context, pid string, timeout time.Duration) error {
 	t := time.NewTimer(timeout)
 	defer t.Stop()
@@ -310,11 +725,11 @@ func (a *Actions) waitForDeath(ctx context.Context, pid string, timeout time.Dur
 		default:
 		}
 
-		pid, err := a.findPID(ctx)
+		ours, err := a.isOurs(ctx, pid)
 		if err != nil {
-			return fmt.Errorf("findPIDs giving errors: %w", err)
+			return fmt.Errorf("isOurs giving errors: %w", err)
 		}
-		if pid == "" {
+		if !ours {
 			return nil
 		}
 
@@ -322,21 +737,62 @@ func (a *Actions) waitForDeath(ctx context.Context, pid string, timeout time.Dur
 	}
 }
 
-// runBinary runs the binary on the remote machine.
+// runBinary runs the binary on the remote machine and writes its PID to the pidfile.
+// The output is sent to /dev/null so that remote transports don't wait on the
+// binary's output to close.
 func (a *Actions) runBinary(ctx context.Context) error {
-	cmdStr := fmt.Sprintf("nohup %s -port %d &", a.dst, a.backend.Port)
-	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmdStr)
-	if err := cmd.Run(); err != nil {
+	cmdStr := fmt.Sprintf(
+		"nohup %s -port %d > /dev/null 2>&1 & echo $! > %s",
+		transport.Quote(a.dst), a.backend.Port, transport.Quote(a.pidPath()),
+	)
+	if _, err := a.t.Run(ctx, cmdStr); err != nil {
 		return fmt.Errorf("problem running the binary on the remote side: %w", err)
 	}
 
 	return nil
 }
 
+// Failure returns the name of the state that Run() failed in.
 func (a *Actions) Failure() string {
 	if a.failedState == nil {
 		return ""
 	}
+	return stateName(a.failedState)
+}
+
+// RollbackFailure returns the name of the state that Rollback() failed in.
+func (a *Actions) RollbackFailure() string {
+	if a.rbState == nil {
+		return ""
+	}
+	return stateName(a.rbState)
+}
 
-	return runtime.FuncForPC(reflect.ValueOf(a.failedState).Pointer()).Name()
+// states returns all the states Run() can be in, keyed by their stateName().
+func (a *Actions) states() map[string]StateFn {
+	return stateMap(a.findAppLocal, a.drainBackend, a.rmBackend, a.jobKill, a.backup, a.cp, a.jobStart, a.reachable, a.addBackend)
+}
+
+// rollbackStates returns the states Rollback() can be in, keyed by their stateName().
+// These are distinct from states() so that a journal tells us which one we were doing.
+func (a *Actions) rollbackStates() map[string]StateFn {
+	return stateMap(a.rbRmBackend, a.rbJobKill, a.restore, a.rbJobStart, a.rbAddBackend)
+}
+
+func stateMap(fns ...StateFn) map[string]StateFn {
+	m := make(map[string]StateFn, len(fns))
+	for _, fn := range fns {
+		m[stateName(fn)] = fn
+	}
+	return m
+}
+
+// stateName returns the short name of a StateFn, such as "rmBackend".
+func stateName(fn StateFn) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i != -1 {
+		name = name[i+1:]
+	}
+	return name
 }
@@ -0,0 +1,243 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/journal"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/transport"
+)
+
+func TestJobKillNotRunning(t *testing.T) {
+	tests := []struct {
+		desc     string
+		retrying bool
+		want     string
+		wantErr  bool
+	}{
+		{
+			desc:    "first run",
+			wantErr: true,
+		},
+		{
+			desc:     "resumed or retried",
+			retrying: true,
+			want:     "backup",
+		},
+	}
+
+	for _, test := range tests {
+		// Nothing is running this binary, as it doesn't exist.
+		a := &Actions{
+			endpoint: "127.0.0.1:8080",
+			dst:      filepath.Join(t.TempDir(), "svc"),
+			t:        transport.Local{},
+			retrying: test.retrying,
+		}
+
+		next, err := a.jobKill(context.Background())
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestJobKillNotRunning(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestJobKillNotRunning(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		if got := stateName(next); got != test.want {
+			t.Errorf("TestJobKillNotRunning(%s): got next state %s, want %s", test.desc, got, test.want)
+		}
+	}
+}
+
+// startBinary copies sleep to dst and runs it, writing its pidfile like runBinary() does.
+// The process is killed when the test ends.
+func startBinary(t *testing.T, dst string) *exec.Cmd {
+	t.Helper()
+
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skipf("no sleep binary: %s", err)
+	}
+	src, err := os.Open(sleep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if err := (transport.Local{}).CopyFile(context.Background(), src, dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(dst, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		<-done
+	})
+
+	if err := os.WriteFile(dst+".pid", []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestRbJobKill(t *testing.T) {
+	tests := []struct {
+		desc    string
+		running bool
+		backup  bool
+		want    string
+		// wantRunning is if the job is still running after rbJobKill().
+		wantRunning bool
+	}{
+		{
+			desc:        "failed before jobKill",
+			running:     true,
+			want:        "rbAddBackend",
+			wantRunning: true,
+		},
+		{
+			desc:    "new binary running",
+			running: true,
+			backup:  true,
+			want:    "restore",
+		},
+		{
+			desc:   "job not running",
+			backup: true,
+			want:   "restore",
+		},
+		{
+			desc: "job not running and no backup",
+			want: "restore",
+		},
+	}
+
+	for _, test := range tests {
+		a := &Actions{
+			endpoint: "127.0.0.1:8080",
+			dst:      filepath.Join(t.TempDir(), "svc"),
+			t:        transport.Local{},
+		}
+		if test.running {
+			startBinary(t, a.dst)
+		}
+		if test.backup {
+			if err := os.WriteFile(a.backupPath(), []byte("old binary"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		next, err := a.rbJobKill(context.Background())
+		if err != nil {
+			t.Errorf("TestRbJobKill(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		}
+		if got := stateName(next); got != test.want {
+			t.Errorf("TestRbJobKill(%s): got next state %s, want %s", test.desc, got, test.want)
+		}
+
+		pid, err := a.findPID(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if running := pid != ""; running != test.wantRunning {
+			t.Errorf("TestRbJobKill(%s): got job running == %v, want %v", test.desc, running, test.wantRunning)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		desc        string
+		state       string
+		dst         string
+		wantDone    bool
+		wantFailure string
+		wantRB      string
+		wantTouched bool
+		wantErr     bool
+	}{
+		{
+			desc:        "done",
+			state:       journal.StateDone,
+			dst:         "/app/svc",
+			wantDone:    true,
+			wantTouched: true,
+		},
+		{
+			desc:  "rolled back",
+			state: journal.StateRolledBack,
+			dst:   "/app/svc",
+		},
+		{
+			desc:        "in a state",
+			state:       "cp",
+			dst:         "/app/svc",
+			wantFailure: "cp",
+			wantTouched: true,
+		},
+		{
+			desc:        "before the binary was found",
+			state:       "findAppLocal",
+			wantFailure: "findAppLocal",
+		},
+		{
+			desc:        "in a rollback",
+			state:       "restore",
+			dst:         "/app/svc",
+			wantRB:      "restore",
+			wantTouched: true,
+		},
+		{
+			desc:    "unknown state",
+			state:   "bogus",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := &Actions{endpoint: "127.0.0.1:8080"}
+		err := a.Resume(journal.Entry{Endpoint: a.endpoint, State: test.state, Dst: test.dst})
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestResume(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestResume(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		if a.Done() != test.wantDone {
+			t.Errorf("TestResume(%s): got Done() == %v, want %v", test.desc, a.Done(), test.wantDone)
+		}
+		if a.Failure() != test.wantFailure {
+			t.Errorf("TestResume(%s): got Failure() == %q, want %q", test.desc, a.Failure(), test.wantFailure)
+		}
+		if a.RollbackFailure() != test.wantRB {
+			t.Errorf("TestResume(%s): got RollbackFailure() == %q, want %q", test.desc, a.RollbackFailure(), test.wantRB)
+		}
+		if a.RollingBack() != (test.wantRB != "") {
+			t.Errorf("TestResume(%s): got RollingBack() == %v, want %v", test.desc, a.RollingBack(), test.wantRB != "")
+		}
+		if a.Touched() != test.wantTouched {
+			t.Errorf("TestResume(%s): got Touched() == %v, want %v", test.desc, a.Touched(), test.wantTouched)
+		}
+	}
+}
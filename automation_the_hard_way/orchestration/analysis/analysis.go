@@ -0,0 +1,268 @@
+/*
+Package analysis provides canary analysis for rollouts.
+
+Seeing "ok" on /healthz only tells us a canary is up, not that it is working. An
+Analyzer watches the canaries and a set of control backends, which are still running
+the old binary, during a soak period and decides if the canaries are worse than the
+controls.
+
+Metrics provides an Analyzer that scrapes Prometheus text format or expvar JSON
+metrics at the start and end of the soak and compares the error rate and average
+latency of the two groups:
+
+	a, err := analysis.NewMetrics(*cfg.CanaryAnalysis)
+	if err != nil {
+		// Do something
+	}
+	report, err := a.Analyze(ctx, canaries, controls, 5*time.Minute)
+	if err != nil {
+		// Do something
+	}
+	if !report.Passed() {
+		fmt.Println(report)
+	}
+*/
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// Analyzer compares canaries to controls.
+type Analyzer interface {
+	// Analyze observes the canaries and controls, which are host:port endpoints, for
+	// the soak duration and returns a Report. An error means the analysis could not
+	// be done, not that the canaries failed.
+	Analyze(ctx context.Context, canaries, controls []string, soak time.Duration) (Report, error)
+}
+
+// Result is the observations of a group of backends during the soak.
+type Result struct {
+	// Requests is the number of requests served.
+	Requests float64
+	// Errors is the number of requests that were errors.
+	Errors float64
+	// ErrorRate is Errors / Requests.
+	ErrorRate float64
+	// Latency is the average latency of a request. This is in whatever unit the metric
+	// uses and is 0 if latency is not being measured.
+	Latency float64
+}
+
+// Report is the result of an analysis.
+type Report struct {
+	// Canary is the result for the canaries.
+	Canary Result
+	// Control is the result for the controls.
+	Control Result
+	// Violations are the thresholds the canaries broke. If empty, the analysis passed.
+	Violations []string
+}
+
+// Passed returns true if the canaries did not break any thresholds.
+func (r Report) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// String outputs the Report as a table comparing the canaries to the controls
+// followed by any violations.
+func (r Report) String() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "%-10s %14s %14s\n", "Metric", "Canary", "Control")
+	fmt.Fprintf(&b, "%-10s %14.0f %14.0f\n", "Requests", r.Canary.Requests, r.Control.Requests)
+	fmt.Fprintf(&b, "%-10s %14.0f %14.0f\n", "Errors", r.Canary.Errors, r.Control.Errors)
+	fmt.Fprintf(&b, "%-10s %14.4f %14.4f\n", "ErrorRate", r.Canary.ErrorRate, r.Control.ErrorRate)
+	fmt.Fprintf(&b, "%-10s %14.4f %14.4f\n", "Latency", r.Canary.Latency, r.Control.Latency)
+	for _, v := range r.Violations {
+		fmt.Fprintf(&b, "violation: %s\n", v)
+	}
+	return b.String()
+}
+
+// Metrics implements Analyzer by scraping metrics from the backends.
+type Metrics struct {
+	cfg    config.Analysis
+	client *http.Client
+}
+
+// NewMetrics creates a new Metrics analyzer.
+func NewMetrics(cfg config.Analysis) (*Metrics, error) {
+	switch cfg.Format {
+	case "prometheus", "expvar":
+	default:
+		return nil, fmt.Errorf("unsupported metric format(%s)", cfg.Format)
+	}
+
+	return &Metrics{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Analyze implements Analyzer.Analyze().
+func (m *Metrics) Analyze(ctx context.Context, canaries, controls []string, soak time.Duration) (Report, error) {
+	if len(canaries) == 0 {
+		return Report{}, fmt.Errorf("must have at least one canary")
+	}
+
+	startCanary, err := m.scrapeAll(ctx, canaries)
+	if err != nil {
+		return Report{}, err
+	}
+	startControl, err := m.scrapeAll(ctx, controls)
+	if err != nil {
+		return Report{}, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return Report{}, ctx.Err()
+	case <-time.After(soak):
+	}
+
+	endCanary, err := m.scrapeAll(ctx, canaries)
+	if err != nil {
+		return Report{}, err
+	}
+	endControl, err := m.scrapeAll(ctx, controls)
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{
+		Canary:  m.result(startCanary, endCanary),
+		Control: m.result(startControl, endControl),
+	}
+	r.Violations = m.compare(r.Canary, r.Control, len(controls) > 0)
+	return r, nil
+}
+
+// counters are the counter values we track for a group of backends.
+type counters struct {
+	requests, errors, latencySum, latencyCount float64
+}
+
+// scrapeAll scrapes all endpoints and returns the sum of their counters.
+func (m *Metrics) scrapeAll(ctx context.Context, endpoints []string) (counters, error) {
+	sum := counters{}
+	for _, e := range endpoints {
+		c, err := m.scrape(ctx, e)
+		if err != nil {
+			return counters{}, err
+		}
+		sum.requests += c.requests
+		sum.errors += c.errors
+		sum.latencySum += c.latencySum
+		sum.latencyCount += c.latencyCount
+	}
+	return sum, nil
+}
+
+// scrape scrapes the counters from a single endpoint.
+func (m *Metrics) scrape(ctx context.Context, endpoint string) (counters, error) {
+	u := &url.URL{
+		Host:   endpoint,
+		Path:   m.cfg.Path,
+		Scheme: "http",
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return counters{}, fmt.Errorf("problem creating HTTP request: %w", err)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return counters{}, fmt.Errorf("could not scrape metrics from %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return counters{}, fmt.Errorf("scraping metrics from %s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	var vals map[string]float64
+	switch m.cfg.Format {
+	case "prometheus":
+		vals, err = readProm(resp.Body)
+	case "expvar":
+		vals, err = readExpvar(resp.Body)
+	}
+	if err != nil {
+		return counters{}, fmt.Errorf("could not parse metrics from %s: %w", endpoint, err)
+	}
+
+	c := counters{}
+	if c.requests, err = lookup(vals, m.cfg.RequestsMetric); err != nil {
+		return counters{}, fmt.Errorf("endpoint %s: %w", endpoint, err)
+	}
+	if c.errors, err = lookup(vals, m.cfg.ErrorsMetric); err != nil {
+		return counters{}, fmt.Errorf("endpoint %s: %w", endpoint, err)
+	}
+	if m.cfg.LatencySumMetric != "" {
+		if c.latencySum, err = lookup(vals, m.cfg.LatencySumMetric); err != nil {
+			return counters{}, fmt.Errorf("endpoint %s: %w", endpoint, err)
+		}
+		if c.latencyCount, err = lookup(vals, m.cfg.LatencyCountMetric); err != nil {
+			return counters{}, fmt.Errorf("endpoint %s: %w", endpoint, err)
+		}
+	}
+	return c, nil
+}
+
+// result calculates the Result from the counters at the start and end of the soak.
+func (m *Metrics) result(start, end counters) Result {
+	r := Result{
+		Requests: end.requests - start.requests,
+		Errors:   end.errors - start.errors,
+	}
+	if r.Requests > 0 {
+		r.ErrorRate = r.Errors / r.Requests
+	}
+	if count := end.latencyCount - start.latencyCount; count > 0 {
+		r.Latency = (end.latencySum - start.latencySum) / count
+	}
+	return r
+}
+
+// compare compares the canary to the control and returns any thresholds that were broken.
+func (m *Metrics) compare(canary, control Result, haveControl bool) []string {
+	var violations []string
+
+	if canary.Requests < m.cfg.MinRequests {
+		violations = append(
+			violations,
+			fmt.Sprintf("canaries served %.0f requests, need at least %.0f", canary.Requests, m.cfg.MinRequests),
+		)
+	}
+	if m.cfg.MaxErrorRate > 0 && canary.ErrorRate > m.cfg.MaxErrorRate {
+		violations = append(
+			violations,
+			fmt.Sprintf("canary error rate %.4f is above the max %.4f", canary.ErrorRate, m.cfg.MaxErrorRate),
+		)
+	}
+	if !haveControl {
+		return violations
+	}
+	if m.cfg.MaxErrorRateIncrease > 0 && canary.ErrorRate-control.ErrorRate > m.cfg.MaxErrorRateIncrease {
+		violations = append(
+			violations,
+			fmt.Sprintf(
+				"canary error rate %.4f is more than %.4f above the control error rate %.4f",
+				canary.ErrorRate, m.cfg.MaxErrorRateIncrease, control.ErrorRate,
+			),
+		)
+	}
+	if m.cfg.MaxLatencyRatio > 0 && control.Latency > 0 && canary.Latency/control.Latency > m.cfg.MaxLatencyRatio {
+		violations = append(
+			violations,
+			fmt.Sprintf(
+				"canary latency %.4f is %.2fx the control latency %.4f, max is %.2fx",
+				canary.Latency, canary.Latency/control.Latency, control.Latency, m.cfg.MaxLatencyRatio,
+			),
+		)
+	}
+	return violations
+}
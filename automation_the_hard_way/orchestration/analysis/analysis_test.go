@@ -0,0 +1,178 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// fakeBackend serves metrics that grow by the given amounts on every scrape.
+type fakeBackend struct {
+	format                 string
+	requests, errors       int64
+	latencySum             float64
+	scrapes                int64
+	addRequests, addErrors int64
+	addLatency             float64
+}
+
+func (f *fakeBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt64(&f.scrapes, 1) - 1
+	requests := f.requests + n*f.addRequests
+	errors := f.errors + n*f.addErrors
+	latency := f.latencySum + float64(n)*f.addLatency
+
+	switch f.format {
+	case "prometheus":
+		fmt.Fprintln(w, "# HELP http_requests_total The total requests.")
+		fmt.Fprintln(w, "# TYPE http_requests_total counter")
+		fmt.Fprintf(w, "http_requests_total{code=\"200\"} %d\n", requests-errors)
+		fmt.Fprintf(w, "http_requests_total{code=\"500\"} %d\n", errors)
+		fmt.Fprintf(w, "http_latency_seconds_sum %f\n", latency)
+		fmt.Fprintf(w, "http_latency_seconds_count %d 1697000000000\n", requests)
+	case "expvar":
+		fmt.Fprintf(
+			w,
+			`{"cmdline": ["web"], "http": {"requests": %d, "errors": %d, "latency": {"sum": %f, "count": %d}}}`,
+			requests, errors, latency, requests,
+		)
+	}
+}
+
+func TestReadProm(t *testing.T) {
+	text := `
+# TYPE http_requests_total counter
+http_requests_total{code="200",path="/a b"} 10
+http_requests_total{code="500",path="/a b"} 2 1697000000000
+up 1
+`
+	vals, err := readProm(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("TestReadProm: got err == %s, want err == nil", err)
+	}
+
+	tests := []struct {
+		name    string
+		want    float64
+		wantErr bool
+	}{
+		{name: `http_requests_total{code="500",path="/a b"}`, want: 2},
+		{name: "http_requests_total", want: 12},
+		{name: "up", want: 1},
+		{name: "down", wantErr: true},
+		{name: `up{code="200"}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := lookup(vals, test.name)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestReadProm(%s): got err == nil, want err != nil", test.name)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestReadProm(%s): got err == %s, want err == nil", test.name, err)
+			continue
+		case err != nil:
+			continue
+		}
+		if got != test.want {
+			t.Errorf("TestReadProm(%s): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	promCfg := config.Analysis{
+		Format:               "prometheus",
+		Path:                 "/metrics",
+		RequestsMetric:       "http_requests_total",
+		ErrorsMetric:         `http_requests_total{code="500"}`,
+		LatencySumMetric:     "http_latency_seconds_sum",
+		LatencyCountMetric:   "http_latency_seconds_count",
+		MinRequests:          10,
+		MaxErrorRate:         0.10,
+		MaxErrorRateIncrease: 0.05,
+		MaxLatencyRatio:      1.5,
+	}
+	expvarCfg := config.Analysis{
+		Format:               "expvar",
+		Path:                 "/debug/vars",
+		RequestsMetric:       "http.requests",
+		ErrorsMetric:         "http.errors",
+		LatencySumMetric:     "http.latency.sum",
+		LatencyCountMetric:   "http.latency.count",
+		MaxErrorRateIncrease: 0.05,
+		MaxLatencyRatio:      1.5,
+	}
+
+	tests := []struct {
+		desc           string
+		cfg            config.Analysis
+		canary         *fakeBackend
+		control        *fakeBackend
+		wantViolations int
+	}{
+		{
+			desc:    "prometheus: canary matches control",
+			cfg:     promCfg,
+			canary:  &fakeBackend{requests: 500, errors: 5, addRequests: 100, addErrors: 1, addLatency: 10},
+			control: &fakeBackend{requests: 1000, errors: 10, addRequests: 100, addErrors: 1, addLatency: 10},
+		},
+		{
+			desc:           "prometheus: canary has high error rate",
+			cfg:            promCfg,
+			canary:         &fakeBackend{addRequests: 100, addErrors: 20, addLatency: 10},
+			control:        &fakeBackend{addRequests: 100, addErrors: 1, addLatency: 10},
+			wantViolations: 2,
+		},
+		{
+			desc:           "prometheus: canary served too few requests",
+			cfg:            promCfg,
+			canary:         &fakeBackend{addRequests: 5, addLatency: 1},
+			control:        &fakeBackend{addRequests: 100, addLatency: 20},
+			wantViolations: 1,
+		},
+		{
+			desc:           "expvar: canary is slow",
+			cfg:            expvarCfg,
+			canary:         &fakeBackend{addRequests: 100, addLatency: 30},
+			control:        &fakeBackend{addRequests: 100, addLatency: 10},
+			wantViolations: 1,
+		},
+	}
+
+	for _, test := range tests {
+		test.canary.format = test.cfg.Format
+		test.control.format = test.cfg.Format
+		canary := httptest.NewServer(test.canary)
+		control := httptest.NewServer(test.control)
+
+		m, err := NewMetrics(test.cfg)
+		if err != nil {
+			t.Fatalf("TestAnalyze(%s): NewMetrics() got err == %s", test.desc, err)
+		}
+
+		report, err := m.Analyze(
+			context.Background(),
+			[]string{strings.TrimPrefix(canary.URL, "http://")},
+			[]string{strings.TrimPrefix(control.URL, "http://")},
+			10*time.Millisecond,
+		)
+		canary.Close()
+		control.Close()
+		if err != nil {
+			t.Errorf("TestAnalyze(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		}
+		if len(report.Violations) != test.wantViolations {
+			t.Errorf("TestAnalyze(%s): got %d violations, want %d:\n%s", test.desc, len(report.Violations), test.wantViolations, report)
+		}
+	}
+}
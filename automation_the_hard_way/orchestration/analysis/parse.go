@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// readProm reads metrics in the Prometheus text exposition format. The returned map is
+// keyed by the series as it appears in the text, such as `http_requests_total{code="500"}`.
+// Comments, timestamps and non-numeric values are ignored.
+func readProm(r io.Reader) (map[string]float64, error) {
+	m := map[string]float64{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// The series name can have spaces inside the labels, so find the end of the labels first.
+		end := 0
+		if i := strings.Index(line, "{"); i != -1 {
+			j := strings.LastIndex(line, "}")
+			if j < i {
+				return nil, fmt.Errorf("malformed line: %s", line)
+			}
+			end = j + 1
+		} else {
+			end = strings.IndexAny(line, " \t")
+			if end == -1 {
+				return nil, fmt.Errorf("malformed line: %s", line)
+			}
+		}
+
+		series := line[:end]
+		fields := strings.Fields(line[end:])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("malformed line: %s", line)
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value in line: %s", line)
+		}
+		m[series] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readExpvar reads the JSON output of the expvar package. Nested values are flattened with
+// their keys joined by ".", so {"http": {"requests": 10}} becomes "http.requests". Only
+// numeric values are kept.
+func readExpvar(r io.Reader) (map[string]float64, error) {
+	var v map[string]any
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	m := map[string]float64{}
+	flatten("", v, m)
+	return m, nil
+}
+
+func flatten(prefix string, v map[string]any, m map[string]float64) {
+	for k, val := range v {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch x := val.(type) {
+		case float64:
+			m[k] = x
+		case map[string]any:
+			flatten(k, x, m)
+		}
+	}
+}
+
+// lookup finds the metric named name in vals. If name has no labels and isn't found
+// exactly, this is the sum of all Prometheus series with that name.
+func lookup(vals map[string]float64, name string) (float64, error) {
+	if v, ok := vals[name]; ok {
+		return v, nil
+	}
+	if strings.Contains(name, "{") {
+		return 0, fmt.Errorf("metric %s not found", name)
+	}
+
+	var sum float64
+	found := false
+	for k, v := range vals {
+		if strings.HasPrefix(k, name+"{") {
+			sum += v
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("metric %s not found", name)
+	}
+	return sum, nil
+}
@@ -2,21 +2,48 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config represents the configuration file that details the work to be done.
 type Config struct {
-	// Concurrency is the number of servers that can be upgraded at a time.
+	// Concurrency is the number of servers that can be upgraded at a time. If Waves
+	// is set, this is only used for rollbacks.
 	Concurrency int32
 	// CanaryNum is the number of canaries to do before proceeding with a general rollout.
 	// Any canary failure fails the workflow. Canaries execute one at a time.
+	// This is ignored if Waves is set.
 	CanaryNum int32
 	// MaxFailures is the maximum number of failures to tolerate before stopping.
 	// You can have more failures than MaxFailures due to concurrency settings.
+	// This is ignored if Waves is set.
 	MaxFailures int32
+	// Waves is the rollout plan. Backends are assigned to waves in the order they are
+	// listed and the waves are executed in order. If not set, the plan is a "canary" wave
+	// of CanaryNum backends followed by a "rest" wave using Concurrency and MaxFailures.
+	Waves []Wave
+	// CanaryAnalysis, if set, compares metrics from the canaries to control backends
+	// during the soak time of canary waves. If the canaries are worse than the
+	// thresholds, the canary wave fails.
+	CanaryAnalysis *Analysis
+	// ES is the name of the emergency stop entry in configs/es.json that controls this
+	// workflow. If set, the workflow will not start unless the entry is "go" and stops
+	// starting new work if it changes to "stop".
+	ES string
+	// RollbackOnFailure causes every endpoint that was touched to be returned to
+	// its previous binary if the workflow fails on a canary or hits MaxFailures.
+	RollbackOnFailure bool
+	// Transport is how we connect to the backends to run commands and copy files.
+	// If not set, commands are run on the local machine.
+	Transport Transport
+	// DrainTimeoutSecs is how long to wait for a backend's in-flight requests to finish
+	// after it stops receiving new requests, before its job is killed. If 0, the load
+	// balancer's default is used.
+	DrainTimeoutSecs int32
 	// Src is the path on disk to the binary to push.
 	Src string
 	// LB is the host:port of the load balancer.
@@ -27,6 +54,280 @@ type Config struct {
 	Backends []string
 }
 
+// DrainTimeout returns DrainTimeoutSecs as a time.Duration.
+func (s Config) DrainTimeout() time.Duration {
+	return time.Duration(s.DrainTimeoutSecs) * time.Second
+}
+
+// Wave is a stage of a rollout plan.
+type Wave struct {
+	// Name is the name of the wave, such as "canary" or "10%".
+	Name string
+	// Count is the number of backends in the wave.
+	Count int32
+	// Percent is the percentage of all Backends to put in the wave, rounded up.
+	// If Count and Percent are both zero, the wave is all remaining backends.
+	// Only the last wave may do this.
+	Percent float64
+	// Concurrency is the number of backends in the wave that can be upgraded at a time.
+	Concurrency int32
+	// MaxFailures is the maximum number of failures to tolerate in this wave before stopping.
+	MaxFailures int32
+	// SoakSecs is how long to wait after the wave completes before starting the next wave.
+	SoakSecs int32
+	// Approval requires a person to approve the wave before it starts.
+	Approval bool
+	// Canary indicates a failure of this wave is a canary failure.
+	Canary bool
+	// Ramp are load balancer weights to give the wave's backends after they are upgraded.
+	// Backends are added back to the load balancer with the first weight. Once the wave
+	// completes, each following weight is set RampStepSecs apart. Backends default to
+	// a weight of 100, so the last weight is usually 100. This requires a PT_WEIGHTED pool.
+	Ramp []int32
+	// RampStepSecs is how long to wait between each weight in Ramp.
+	RampStepSecs int32
+}
+
+// Soak returns the soak time as a time.Duration.
+func (w Wave) Soak() time.Duration {
+	return time.Duration(w.SoakSecs) * time.Second
+}
+
+// RampStep returns the time between Ramp weights as a time.Duration.
+func (w Wave) RampStep() time.Duration {
+	return time.Duration(w.RampStepSecs) * time.Second
+}
+
+func (w Wave) validate() error {
+	if strings.TrimSpace(w.Name) == "" {
+		return fmt.Errorf("Name must be set")
+	}
+	if w.Count < 0 {
+		return fmt.Errorf("Count(%d) is invalid", w.Count)
+	}
+	if w.Percent < 0 || w.Percent > 100 {
+		return fmt.Errorf("Percent(%v) is invalid", w.Percent)
+	}
+	if w.Count > 0 && w.Percent > 0 {
+		return fmt.Errorf("cannot set both Count and Percent")
+	}
+	if w.Concurrency < 1 {
+		return fmt.Errorf("Concurrency(%d) is invalid", w.Concurrency)
+	}
+	if w.MaxFailures < 0 {
+		return fmt.Errorf("MaxFailures(%d) is invalid", w.MaxFailures)
+	}
+	if w.SoakSecs < 0 {
+		return fmt.Errorf("SoakSecs(%d) is invalid", w.SoakSecs)
+	}
+	for _, weight := range w.Ramp {
+		if weight < 1 {
+			return fmt.Errorf("Ramp weight(%d) is invalid", weight)
+		}
+	}
+	if len(w.Ramp) > 1 && w.RampStepSecs < 1 {
+		return fmt.Errorf("RampStepSecs must be set if Ramp has more than one weight")
+	}
+	return nil
+}
+
+func (w Wave) rest() bool {
+	return w.Count == 0 && w.Percent == 0
+}
+
+// Plan returns the waves to execute.
+func (s Config) Plan() []Wave {
+	if len(s.Waves) > 0 {
+		return s.Waves
+	}
+
+	var waves []Wave
+	if s.CanaryNum > 0 {
+		waves = append(
+			waves,
+			Wave{
+				Name:        "canary",
+				Count:       s.CanaryNum,
+				Concurrency: 1,
+				SoakSecs:    60,
+				Canary:      true,
+			},
+		)
+	}
+	return append(
+		waves,
+		Wave{
+			Name:        "rest",
+			Concurrency: s.Concurrency,
+			MaxFailures: s.MaxFailures,
+		},
+	)
+}
+
+// Weighted returns true if any wave in Plan() has a Ramp, which requires a PT_WEIGHTED pool.
+func (s Config) Weighted() bool {
+	for _, w := range s.Plan() {
+		if len(w.Ramp) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Assign assigns Backends to the waves in Plan(). The returned slice has an entry
+// for each wave, which may be empty if earlier waves used all the backends.
+func (s Config) Assign() ([][]string, error) {
+	waves := s.Plan()
+	assigned := make([][]string, 0, len(waves))
+
+	remaining := s.Backends
+	for _, w := range waves {
+		var n int
+		switch {
+		case w.Count > 0:
+			n = int(w.Count)
+		case w.Percent > 0:
+			n = int(math.Ceil(float64(len(s.Backends)) * w.Percent / 100))
+		default:
+			n = len(remaining)
+		}
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		assigned = append(assigned, remaining[:n])
+		remaining = remaining[n:]
+	}
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("the waves do not cover all Backends, %d are unassigned", len(remaining))
+	}
+	return assigned, nil
+}
+
+// Transport configures how the workflow connects to the backends.
+type Transport struct {
+	// Type is the type of transport: "local", "ssh" or "agent". Defaults to "local",
+	// which runs everything on this machine. "agent" connects to the agent's domain
+	// socket over SSH, so it requires compiling with "-tags ds" and the agent must be
+	// run with -allowRemoteExec.
+	Type string
+	// Port is the SSH port to connect to on the backend. Defaults to 22. Not used
+	// for "local".
+	Port int32
+	// User is the user to log in as with "ssh" and "agent". Defaults to $USER.
+	User string
+	// KeyFile is the SSH private key to use. If not set, the SSH agent on SSH_AUTH_SOCK
+	// is used. Used with "ssh" and "agent".
+	KeyFile string
+	// KnownHostsFile is a known_hosts file used to verify host keys with "ssh" and
+	// "agent". Defaults to ~/.ssh/known_hosts.
+	KnownHostsFile string
+	// InsecureIgnoreHostKey turns off host key verification, which allows anyone who can
+	// intercept the connection to pretend to be the backend. Only use this for testing.
+	InsecureIgnoreHostKey bool
+}
+
+// TypeOrDefault returns Type, or "local" if not set.
+func (t Transport) TypeOrDefault() string {
+	if t.Type == "" {
+		return "local"
+	}
+	return t.Type
+}
+
+// PortOrDefault returns Port, or the default port for the Type if not set.
+func (t Transport) PortOrDefault() int32 {
+	if t.Port != 0 {
+		return t.Port
+	}
+	switch t.TypeOrDefault() {
+	case "ssh", "agent":
+		return 22
+	}
+	return 0
+}
+
+func (t Transport) validate() error {
+	switch t.TypeOrDefault() {
+	case "local", "ssh", "agent":
+	default:
+		return fmt.Errorf("Type(%s) must be local, ssh or agent", t.Type)
+	}
+	if t.Port < 0 || t.Port > 65534 {
+		return fmt.Errorf("Port(%d) is invalid", t.Port)
+	}
+	if t.InsecureIgnoreHostKey && t.KnownHostsFile != "" {
+		return fmt.Errorf("KnownHostsFile cannot be set with InsecureIgnoreHostKey")
+	}
+	return nil
+}
+
+// Analysis configures metric based canary analysis. Metrics are scraped from the
+// canaries and control backends at the start and end of a canary wave's soak time,
+// so all metrics must be counters.
+type Analysis struct {
+	// Format is the format of the metrics, either "prometheus" or "expvar".
+	Format string
+	// Path is the URL path to scrape, such as "/metrics" or "/debug/vars".
+	Path string
+	// RequestsMetric is the name of the counter of requests. For prometheus, a name without
+	// labels is the sum of all series with that name. For expvar, nested values are
+	// separated with ".", like "http.requests".
+	RequestsMetric string
+	// ErrorsMetric is the name of the counter of requests that were errors.
+	ErrorsMetric string
+	// LatencySumMetric is the name of the counter of total request latency, such as a
+	// histogram's _sum. This is optional.
+	LatencySumMetric string
+	// LatencyCountMetric is the name of the counter of requests measured in
+	// LatencySumMetric, such as a histogram's _count. Required if LatencySumMetric is set.
+	LatencyCountMetric string
+	// Controls is the maximum number of backends that have not been upgraded to use as
+	// the control group. Defaults to 3.
+	Controls int32
+
+	// MinRequests is the minimum number of requests the canaries must serve during the
+	// soak for the analysis to pass.
+	MinRequests float64
+	// MaxErrorRate is the maximum error rate(0-1) the canaries can have. 0 is not checked.
+	MaxErrorRate float64
+	// MaxErrorRateIncrease is the maximum amount the canaries' error rate can be above the
+	// controls' error rate. 0 is not checked.
+	MaxErrorRateIncrease float64
+	// MaxLatencyRatio is the maximum the canaries' average latency divided by the
+	// controls' average latency can be, such as 1.2 for 20% slower. 0 is not checked.
+	MaxLatencyRatio float64
+}
+
+func (a Analysis) validate() error {
+	switch a.Format {
+	case "prometheus", "expvar":
+	default:
+		return fmt.Errorf("Format(%s) must be prometheus or expvar", a.Format)
+	}
+	if !strings.HasPrefix(a.Path, "/") {
+		return fmt.Errorf("Path(%s) must start with /", a.Path)
+	}
+	if a.RequestsMetric == "" || a.ErrorsMetric == "" {
+		return fmt.Errorf("RequestsMetric and ErrorsMetric must be set")
+	}
+	if (a.LatencySumMetric == "") != (a.LatencyCountMetric == "") {
+		return fmt.Errorf("LatencySumMetric and LatencyCountMetric must both be set")
+	}
+	if a.MaxLatencyRatio > 0 && a.LatencySumMetric == "" {
+		return fmt.Errorf("MaxLatencyRatio requires LatencySumMetric and LatencyCountMetric")
+	}
+	if a.Controls < 0 {
+		return fmt.Errorf("Controls(%d) is invalid", a.Controls)
+	}
+	if a.MaxErrorRate < 0 || a.MaxErrorRate > 1 {
+		return fmt.Errorf("MaxErrorRate(%v) is invalid", a.MaxErrorRate)
+	}
+	if a.MinRequests < 0 || a.MaxErrorRateIncrease < 0 || a.MaxLatencyRatio < 0 {
+		return fmt.Errorf("thresholds cannot be negative")
+	}
+	return nil
+}
+
 // Validate does basic validation of the config.
 func (s Config) Validate() error {
 	if _, _, err := CheckIPPort(s.LB); err != nil {
@@ -44,9 +345,44 @@ func (s Config) Validate() error {
 	if strings.TrimSpace(s.Pattern) == "" {
 		return fmt.Errorf("Pattern(%s) is invalid", s.Pattern)
 	}
-	if s.Concurrency < 1 {
+	if s.DrainTimeoutSecs < 0 {
+		return fmt.Errorf("DrainTimeoutSecs(%d) is invalid", s.DrainTimeoutSecs)
+	}
+	if len(s.Waves) == 0 && s.Concurrency < 1 {
 		return fmt.Errorf("Concurrency(%d) is invalid", s.Concurrency)
 	}
+
+	names := map[string]bool{}
+	for i, w := range s.Waves {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("Wave(%d) is invalid: %w", i, err)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("Wave(%s) is defined twice", w.Name)
+		}
+		names[w.Name] = true
+		if w.rest() && i != len(s.Waves)-1 {
+			return fmt.Errorf("Wave(%s) has no Count or Percent, only the last wave can do this", w.Name)
+		}
+	}
+	if _, err := s.Assign(); err != nil {
+		return err
+	}
+
+	if err := s.Transport.validate(); err != nil {
+		return fmt.Errorf("Transport is invalid: %w", err)
+	}
+
+	if s.CanaryAnalysis != nil {
+		if err := s.CanaryAnalysis.validate(); err != nil {
+			return fmt.Errorf("CanaryAnalysis is invalid: %w", err)
+		}
+		for _, w := range s.Plan() {
+			if w.Canary && w.SoakSecs < 1 {
+				return fmt.Errorf("Wave(%s) must have SoakSecs to do CanaryAnalysis", w.Name)
+			}
+		}
+	}
 	return nil
 }
 
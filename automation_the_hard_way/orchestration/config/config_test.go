@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// backends returns n backends in IP:Port form.
+func backends(n int) []string {
+	var b []string
+	for i := 0; i < n; i++ {
+		b = append(b, fmt.Sprintf("127.0.0.1:%d", 8000+i))
+	}
+	return b
+}
+
+func TestPlan(t *testing.T) {
+	waves := []Wave{
+		{Name: "first", Count: 1, Concurrency: 1},
+		{Name: "second", Concurrency: 2},
+	}
+
+	tests := []struct {
+		desc   string
+		config Config
+		want   []Wave
+	}{
+		{
+			desc:   "canaries and the rest",
+			config: Config{CanaryNum: 2, Concurrency: 5, MaxFailures: 3},
+			want: []Wave{
+				{Name: "canary", Count: 2, Concurrency: 1, SoakSecs: 60, Canary: true},
+				{Name: "rest", Concurrency: 5, MaxFailures: 3},
+			},
+		},
+		{
+			desc:   "no canaries",
+			config: Config{Concurrency: 5, MaxFailures: 3},
+			want:   []Wave{{Name: "rest", Concurrency: 5, MaxFailures: 3}},
+		},
+		{
+			desc:   "waves replace the canary settings",
+			config: Config{CanaryNum: 2, Concurrency: 5, Waves: waves},
+			want:   waves,
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.config.Plan(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("TestPlan(%s): got %+v, want %+v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestAssign(t *testing.T) {
+	b := backends(10)
+
+	tests := []struct {
+		desc     string
+		backends []string
+		waves    []Wave
+		want     [][]string
+		wantErr  bool
+	}{
+		{
+			desc:     "count then the rest",
+			backends: b,
+			waves:    []Wave{{Name: "canary", Count: 2}, {Name: "rest"}},
+			want:     [][]string{b[:2], b[2:]},
+		},
+		{
+			desc:     "percent rounds up",
+			backends: b,
+			waves:    []Wave{{Name: "15%", Percent: 15}, {Name: "50%", Percent: 50}, {Name: "rest"}},
+			want:     [][]string{b[:2], b[2:7], b[7:]},
+		},
+		{
+			desc:     "earlier waves use all the backends",
+			backends: b[:3],
+			waves:    []Wave{{Name: "canary", Count: 5}, {Name: "rest"}},
+			want:     [][]string{b[:3], {}},
+		},
+		{
+			desc:     "backends left over",
+			backends: b,
+			waves:    []Wave{{Name: "canary", Count: 2}, {Name: "more", Count: 3}},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		c := Config{Backends: test.backends, Waves: test.waves}
+		got, err := c.Assign()
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestAssign(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestAssign(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		if len(got) != len(test.want) {
+			t.Errorf("TestAssign(%s): got %d waves, want %d", test.desc, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if len(got[i]) == 0 && len(test.want[i]) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got[i], test.want[i]) {
+				t.Errorf("TestAssign(%s): wave %d: got %v, want %v", test.desc, i, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestValidateWaves(t *testing.T) {
+	tests := []struct {
+		desc    string
+		waves   []Wave
+		wantErr bool
+	}{
+		{
+			desc:  "valid",
+			waves: []Wave{{Name: "canary", Count: 1, Concurrency: 1, Canary: true}, {Name: "rest", Concurrency: 2}},
+		},
+		{
+			desc:  "ramp",
+			waves: []Wave{{Name: "all", Concurrency: 1, Ramp: []int32{10, 100}, RampStepSecs: 30}},
+		},
+		{
+			desc:    "no name",
+			waves:   []Wave{{Concurrency: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "no concurrency",
+			waves:   []Wave{{Name: "all"}},
+			wantErr: true,
+		},
+		{
+			desc:    "count and percent",
+			waves:   []Wave{{Name: "canary", Count: 1, Percent: 10, Concurrency: 1}, {Name: "rest", Concurrency: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "name used twice",
+			waves:   []Wave{{Name: "a", Count: 1, Concurrency: 1}, {Name: "a", Concurrency: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "the rest is not the last wave",
+			waves:   []Wave{{Name: "rest", Concurrency: 1}, {Name: "last", Count: 1, Concurrency: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "waves don't cover the backends",
+			waves:   []Wave{{Name: "canary", Count: 1, Concurrency: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "ramp without a step",
+			waves:   []Wave{{Name: "all", Concurrency: 1, Ramp: []int32{10, 100}}},
+			wantErr: true,
+		},
+		{
+			desc:    "ramp weight of 0",
+			waves:   []Wave{{Name: "all", Concurrency: 1, Ramp: []int32{0}}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := Config{
+			LB:       "127.0.0.1:8081",
+			Pattern:  "/",
+			Backends: backends(3),
+			Waves:    test.waves,
+		}
+		err := c.Validate()
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestValidateWaves(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestValidateWaves(%s): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
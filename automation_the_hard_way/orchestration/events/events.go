@@ -0,0 +1,89 @@
+/*
+Package events provides the typed events a rollout emits as it runs.
+
+A Handler can be set on a workflow to receive every Event. This can be used to feed
+the progress of a rollout into other tooling. JSONLines() provides a Handler that
+writes each Event as a line of JSON:
+
+	wf.Events = events.JSONLines(os.Stdout)
+*/
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// Type is the type of an Event.
+type Type string
+
+const (
+	// WaveStarted is emitted when a wave starts.
+	WaveStarted Type = "waveStarted"
+	// WaveCompleted is emitted when all the actions in a wave have run without
+	// exceeding the wave's MaxFailures.
+	WaveCompleted Type = "waveCompleted"
+	// StateEntered is emitted when an endpoint enters a new state.
+	StateEntered Type = "stateEntered"
+	// EndpointDone is emitted when an endpoint has completed all of its states.
+	EndpointDone Type = "endpointDone"
+	// EndpointFailed is emitted when an endpoint fails. State is the state it failed in.
+	EndpointFailed Type = "endpointFailed"
+	// Retry is emitted when a failed endpoint is retried.
+	Retry Type = "retry"
+	// EndpointRolledBack is emitted when an endpoint has been rolled back.
+	EndpointRolledBack Type = "endpointRolledBack"
+	// RollbackFailed is emitted when an endpoint fails to roll back.
+	RollbackFailed Type = "rollbackFailed"
+	// EndState is emitted when the workflow has finished running. EndState holds the
+	// workflow's end state.
+	EndState Type = "endState"
+	// RollbackEnd is emitted when a rollback has finished. RollbackState holds the
+	// state of the rollback.
+	RollbackEnd Type = "rollbackEnd"
+)
+
+// Event is something that happened during a rollout. Only the fields that apply
+// to the Type are set.
+type Event struct {
+	// Time is when the event happened.
+	Time time.Time
+	// Type is the type of event.
+	Type Type
+	// Wave is the name of the wave the event happened in.
+	Wave string `json:",omitempty"`
+	// Endpoint is the endpoint the event is for.
+	Endpoint string `json:",omitempty"`
+	// State is the state the endpoint entered or failed in.
+	State string `json:",omitempty"`
+	// Attempt is the retry attempt, starting at 1.
+	Attempt int `json:",omitempty"`
+	// EndState is the end state of the workflow.
+	EndState string `json:",omitempty"`
+	// RollbackState is the state of a rollback.
+	RollbackState string `json:",omitempty"`
+	// Err is the error that caused a failure.
+	Err string `json:",omitempty"`
+}
+
+// Handler receives Events. Handlers may be called concurrently.
+type Handler func(Event)
+
+// JSONLines returns a Handler that writes each Event to w as a line of JSON.
+// Errors writing to w are logged.
+func JSONLines(w io.Writer) Handler {
+	mu := sync.Mutex{}
+	enc := json.NewEncoder(w)
+
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := enc.Encode(e); err != nil {
+			log.Printf("could not write event: %s", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJSONLines(t *testing.T) {
+	const n = 100
+
+	buf := &bytes.Buffer{}
+	h := JSONLines(buf)
+
+	// Handlers may be called concurrently, so lines must not be interleaved.
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h(Event{Time: time.Unix(int64(i), 0), Type: StateEntered, Endpoint: fmt.Sprintf("127.0.0.1:%d", 8000+i), State: "cp"})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		var fields map[string]any
+		if err := json.Unmarshal(s.Bytes(), &fields); err != nil {
+			t.Fatalf("TestJSONLines: line %q is not JSON: %s", s.Text(), err)
+		}
+		// Fields that don't apply to the Type are left out.
+		for _, k := range []string{"Wave", "Attempt", "EndState", "RollbackState", "Err"} {
+			if _, ok := fields[k]; ok {
+				t.Errorf("TestJSONLines: line %q has field %s, want it left out", s.Text(), k)
+			}
+		}
+
+		var e Event
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type != StateEntered || e.State != "cp" {
+			t.Errorf("TestJSONLines: got event %+v, want a %s event for state cp", e, StateEntered)
+		}
+		seen[e.Endpoint] = true
+	}
+	if len(seen) != n {
+		t.Errorf("TestJSONLines: got events for %d endpoints, want %d", len(seen), n)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Package journal provides an append-only journal of rollout state transitions.
+
+Every time an endpoint enters a new state, the transition is written to disk
+and synced before the state is executed. If the orchestration binary dies in the
+middle of a rollout, the journal can be read back with Read() to find the last
+state each endpoint was in so the rollout can be resumed where it left off.
+
+The journal is a file of JSON entries, one per line.
+*/
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// StateDone is the State recorded when an endpoint has completed all of its states.
+	StateDone = "done"
+	// StateRolledBack is the State recorded when an endpoint has been rolled back to
+	// its previous binary.
+	StateRolledBack = "rolledBack"
+)
+
+// Entry is a single state transition for an endpoint.
+type Entry struct {
+	// Time is when the transition happened.
+	Time time.Time
+	// Endpoint is the endpoint the transition is for.
+	Endpoint string
+	// State is the name of the state being entered. If the endpoint has finished,
+	// this will be StateDone or StateRolledBack.
+	State string
+	// Dst is the path to the binary on the endpoint, if it is known.
+	Dst string
+	// Err is the error that the state failed with. If empty, this records entering
+	// the state.
+	Err string
+}
+
+// Journal writes Entry records to a file.
+type Journal struct {
+	path string
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// Create creates a new journal at path. If the file already exists, this will
+// return an error, as we never want to clobber a journal of a previous rollout.
+func Create(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("journal(%s) already exists, did you mean to resume it?", path)
+		}
+		return nil, fmt.Errorf("could not create journal(%s): %w", path, err)
+	}
+	return &Journal{path: path, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Open opens an existing journal at path for appending. The last Entry for each
+// endpoint in the journal is returned, keyed by endpoint. If the journal ends in a
+// partial write, that write is removed so new entries follow the last good one. Any
+// other line that can't be read is an error and the journal is not changed.
+func Open(path string) (*Journal, map[string]Entry, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0640)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open journal(%s): %w", path, err)
+	}
+
+	last, good, err := read(path, f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if err := f.Truncate(good); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("could not remove partial write from journal(%s): %w", path, err)
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("could not seek to end of journal(%s): %w", path, err)
+	}
+	return &Journal{path: path, f: f, enc: json.NewEncoder(f)}, last, nil
+}
+
+// Read reads the journal at path and returns the last Entry for each endpoint.
+func Read(path string) (map[string]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open journal(%s): %w", path, err)
+	}
+	defer f.Close()
+
+	last, _, err := read(path, f)
+	return last, err
+}
+
+// read reads the journal in r, which is at path, and returns the last Entry for each
+// endpoint and the offset of the end of the last good entry.
+func read(path string, r io.Reader) (map[string]Entry, int64, error) {
+	last := map[string]Entry{}
+	br := bufio.NewReader(r)
+
+	var good int64
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, fmt.Errorf("problem reading journal(%s): %w", path, err)
+		}
+		// Every entry is written with its newline, so a last line without one is a
+		// partial write from when we died. We drop it.
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			good += int64(len(b))
+			continue
+		}
+		// A complete line that is corrupt isn't from us dying mid write. Entries after it
+		// may be good, so we must not truncate it away.
+		e := Entry{}
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, 0, fmt.Errorf("journal(%s) line %d is corrupt: %w", path, line, err)
+		}
+		if e.Endpoint == "" {
+			return nil, 0, fmt.Errorf("journal(%s) line %d has an entry with no endpoint", path, line)
+		}
+		last[e.Endpoint] = e
+		good += int64(len(b))
+	}
+	return last, good, nil
+}
+
+// Path returns the path to the journal file.
+func (j *Journal) Path() string {
+	if j == nil {
+		return ""
+	}
+	return j.path
+}
+
+// Record writes e to the journal and syncs it to disk. If e.Time is not set, it
+// is set to the current time. Record on a nil *Journal does nothing.
+func (j *Journal) Record(e Entry) error {
+	if j == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.enc.Encode(e); err != nil {
+		return fmt.Errorf("could not write to journal(%s): %w", j.path, err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("could not sync journal(%s): %w", j.path, err)
+	}
+	return nil
+}
+
+// Close closes the journal.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.f.Close()
+}
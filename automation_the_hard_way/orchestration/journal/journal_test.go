@@ -0,0 +1,178 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeJournal writes a journal with entries for endpoints "a" and "b" to a temporary
+// file, followed by tail, and returns its path.
+func writeJournal(t *testing.T, tail string) string {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), "journal")
+	j, err := Create(p)
+	if err != nil {
+		t.Fatalf("Create(): got err == %s", err)
+	}
+	for _, e := range []Entry{
+		{Endpoint: "a", State: "rmBackend"},
+		{Endpoint: "b", State: "rmBackend"},
+		{Endpoint: "a", State: "cp"},
+	} {
+		if err := j.Record(e); err != nil {
+			t.Fatalf("Record(): got err == %s", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close(): got err == %s", err)
+	}
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(tail); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		desc string
+		tail string
+		want map[string]string
+	}{
+		{
+			desc: "complete journal",
+			want: map[string]string{"a": "cp", "b": "rmBackend"},
+		},
+		{
+			desc: "blank lines are skipped",
+			tail: "\n\n",
+			want: map[string]string{"a": "cp", "b": "rmBackend"},
+		},
+		{
+			desc: "torn write of an entry",
+			tail: `{"Endpoint":"b","Sta`,
+			want: map[string]string{"a": "cp", "b": "rmBackend"},
+		},
+		{
+			desc: "entry without its newline is torn",
+			tail: `{"Endpoint":"b","State":"cp"}`,
+			want: map[string]string{"a": "cp", "b": "rmBackend"},
+		},
+	}
+
+	for _, test := range tests {
+		p := writeJournal(t, test.tail)
+
+		got, err := Read(p)
+		if err != nil {
+			t.Errorf("TestRead(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("TestRead(%s): got %d endpoints, want %d", test.desc, len(got), len(test.want))
+		}
+		for ep, state := range test.want {
+			if got[ep].State != state {
+				t.Errorf("TestRead(%s): endpoint(%s): got state %q, want %q", test.desc, ep, got[ep].State, state)
+			}
+		}
+	}
+}
+
+func TestReadNoEndpoint(t *testing.T) {
+	p := writeJournal(t, "{\"State\":\"cp\"}\n")
+
+	if _, err := Read(p); err == nil {
+		t.Errorf("TestReadNoEndpoint: got err == nil, want err != nil")
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	// The corrupt line is line 4 and has good entries after it, which must not be lost.
+	tail := "{\"Endpoint\":\"b\",\"Sta\n{\"Endpoint\":\"b\",\"State\":\"cp\"}\n{\"Endpoint\":\"c\",\"State\":\"cp\"}\n"
+	p := writeJournal(t, tail)
+	before, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Read(p)
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("TestCorrupt: Read(): got err == %v, want an error for line 4", err)
+	}
+	if _, _, err := Open(p); err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("TestCorrupt: Open(): got err == %v, want an error for line 4", err)
+	}
+
+	after, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("TestCorrupt: journal was changed:\ngot:\n%s\nwant:\n%s", after, before)
+	}
+}
+
+func TestOpenResume(t *testing.T) {
+	tests := []struct {
+		desc string
+		tail string
+	}{
+		{desc: "complete journal"},
+		{desc: "torn write", tail: `{"Endpoint":"b","Sta`},
+		{desc: "entry without its newline", tail: `{"Endpoint":"b","State":"cp"}`},
+	}
+
+	for _, test := range tests {
+		p := writeJournal(t, test.tail)
+
+		// Resume twice, as a second crash and resume must see what the first resume wrote.
+		for i, state := range []string{"jobKill", "cp"} {
+			j, last, err := Open(p)
+			if err != nil {
+				t.Fatalf("TestOpenResume(%s): Open() #%d: got err == %s", test.desc, i, err)
+			}
+			if last["a"].State != "cp" {
+				t.Errorf("TestOpenResume(%s): Open() #%d: endpoint(a): got state %q, want %q", test.desc, i, last["a"].State, "cp")
+			}
+			if err := j.Record(Entry{Endpoint: "b", State: state}); err != nil {
+				t.Fatalf("TestOpenResume(%s): Record() #%d: got err == %s", test.desc, i, err)
+			}
+			if err := j.Close(); err != nil {
+				t.Fatalf("TestOpenResume(%s): Close() #%d: got err == %s", test.desc, i, err)
+			}
+
+			got, err := Read(p)
+			if err != nil {
+				t.Fatalf("TestOpenResume(%s): Read() #%d: got err == %s", test.desc, i, err)
+			}
+			if got["b"].State != state {
+				t.Errorf("TestOpenResume(%s): resume #%d: endpoint(b): got state %q, want %q", test.desc, i, got["b"].State, state)
+			}
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(b), "\n"); n != 5 {
+			t.Errorf("TestOpenResume(%s): got %d lines in journal, want 5:\n%s", test.desc, n, b)
+		}
+	}
+}
+
+func TestCreateExists(t *testing.T) {
+	p := writeJournal(t, "")
+
+	if _, err := Create(p); err == nil {
+		t.Errorf("TestCreateExists: got err == nil, want err != nil")
+	}
+}
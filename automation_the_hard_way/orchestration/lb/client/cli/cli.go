@@ -5,24 +5,59 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/client"
 
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
 var (
-	server  = flag.String("lb", "", "The load balancer address to connect to, host:port")
-	ip      = flag.String("ip", "", "An IP setting")
-	port    = flag.Int("port", 0, "A port setting")
-	urlPath = flag.String("url_path", "", "The url path to use")
-	pattern = flag.String("pattern", "", "A pattern setting")
+	server   = flag.String("lb", "", "The load balancer address to connect to, host:port")
+	ip       = flag.String("ip", "", "An IP setting")
+	port     = flag.Int("port", 0, "A port setting")
+	urlPath  = flag.String("url_path", "", "The url path to use")
+	pattern  = flag.String("pattern", "", "A pattern setting")
+	weight   = flag.Int("weight", 0, "A backend weight setting, only for weighted pools")
+	poolType = flag.String("pool_type", "p2c", "The type of pool to add: p2c, weighted, round_robin, least_conn or consistent_hash")
+	hashHdr  = flag.String("hash_header", "", "The header to hash for a consistent_hash pool")
+	hashCk   = flag.String("hash_cookie", "", "The cookie to hash for a consistent_hash pool")
+	file     = flag.String("file", "", "The config file to use with applyConfig")
+	rtOnly   = flag.Bool("route_only", false, "If set, addPool adds a pool named -pattern that only receives requests from routes")
+	route    = flag.String("route", "", "The name of a route")
+	priority = flag.Int("priority", 0, "The priority of a route, higher priorities are checked first")
+	host     = flag.String("host", "", "The host a route matches, such as www.example.com or *.example.com")
+	prefix   = flag.String("path_prefix", "", "The URL path prefix a route matches")
+	headers  = flag.String("headers", "", "Comma separated headers a route matches, as name=value, or name to match any value")
+	methods  = flag.String("methods", "", "Comma separated HTTP methods a route matches")
+	retries  = flag.Int("max_retries", 0, "If set, addPool adds a pool that retries failed idempotent requests on other backends up to this many times")
+	tryTime  = flag.Duration("per_try_timeout", 0, "How long each try of a retried request can take")
+	cert     = flag.String("cert", "", "If set with -key and -ca, connect to the load balancer with TLS using this client certificate")
+	key      = flag.String("key", "", "The key for -cert")
+	ca       = flag.String("ca", "", "The CA the load balancer's certificate is from")
+	token    = flag.String("token", "", "If set, the token sent to the load balancer to authenticate")
+	backCA   = flag.String("backend_ca", "", "If set, addPool adds a pool that connects to backends with TLS, verified with this CA file on the load balancer")
+	backCert = flag.String("backend_cert", "", "The client certificate file on the load balancer for mutual TLS to backends")
+	backKey  = flag.String("backend_key", "", "The key file for -backend_cert")
+	backName = flag.String("backend_server_name", "", "The name backend certificates must be for")
 )
 
+var poolTypes = map[string]pb.PoolType{
+	"p2c":             pb.PoolType_PT_P2C,
+	"weighted":        pb.PoolType_PT_WEIGHTED,
+	"round_robin":     pb.PoolType_PT_ROUND_ROBIN,
+	"least_conn":      pb.PoolType_PT_LEAST_CONN,
+	"consistent_hash": pb.PoolType_PT_CONSISTENT_HASH,
+}
+
 var hcs = client.HealthChecks{
 	Interval: 10 * time.Second,
 	HealthChecks: []client.HealthCheck{
@@ -40,7 +75,27 @@ func main() {
 		panic("bad args")
 	}
 
-	c, err := client.New(*server)
+	if *retries > 0 {
+		hcs.Retries = &client.RetryPolicy{MaxRetries: int32(*retries), PerTryTimeout: *tryTime}
+	}
+
+	if *backCA != "" || *backCert != "" {
+		hcs.TLS = &client.BackendTLS{CAFile: *backCA, CertFile: *backCert, KeyFile: *backKey, ServerName: *backName}
+	}
+
+	var (
+		c    *client.Client
+		err  error
+		opts []grpc.DialOption
+	)
+	if *token != "" {
+		opts = append(opts, client.Token(*token))
+	}
+	if *cert != "" {
+		c, err = client.NewTLS(*server, *cert, *key, *ca, opts...)
+	} else {
+		c, err = client.New(*server, opts...)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -48,7 +103,33 @@ func main() {
 	switch flag.Args()[0] {
 	case "addPool":
 		ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-		if err := c.AddPool(ctx, *pattern, pb.PoolType_PT_P2C, hcs); err != nil {
+		if *rtOnly {
+			pt, ok := poolTypes[*poolType]
+			if !ok {
+				panic("non-recognized pool_type")
+			}
+			key := client.HashKey{Header: *hashHdr, Cookie: *hashCk}
+			if err := c.AddRouteOnlyPool(ctx, *pattern, pt, key, hcs); err != nil {
+				panic(err)
+			}
+			break
+		}
+		var err error
+		switch *poolType {
+		case "p2c":
+			err = c.AddPool(ctx, *pattern, pb.PoolType_PT_P2C, hcs)
+		case "weighted":
+			err = c.AddPool(ctx, *pattern, pb.PoolType_PT_WEIGHTED, hcs)
+		case "round_robin":
+			err = c.AddPool(ctx, *pattern, pb.PoolType_PT_ROUND_ROBIN, hcs)
+		case "least_conn":
+			err = c.AddPool(ctx, *pattern, pb.PoolType_PT_LEAST_CONN, hcs)
+		case "consistent_hash":
+			err = c.AddHashPool(ctx, *pattern, client.HashKey{Header: *hashHdr, Cookie: *hashCk}, hcs)
+		default:
+			panic("non-recognized pool_type")
+		}
+		if err != nil {
 			panic(err)
 		}
 	case "removePool":
@@ -64,7 +145,7 @@ func main() {
 			Port:    int32(*port),
 			URLPath: *urlPath,
 		}
-		if err := c.AddBackend(ctx, *pattern, b); err != nil {
+		if err := c.AddWeightedBackend(ctx, *pattern, b, int32(*weight)); err != nil {
 			panic(err)
 		}
 	case "removeBackend":
@@ -78,6 +159,135 @@ func main() {
 		if err := c.AddBackend(ctx, *pattern, b); err != nil {
 			panic(err)
 		}
+	case "setBackendWeight":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		b := client.IPBackend{
+			IP:      net.ParseIP(*ip),
+			Port:    int32(*port),
+			URLPath: *urlPath,
+		}
+		if err := c.SetBackendWeight(ctx, *pattern, b, int32(*weight)); err != nil {
+			panic(err)
+		}
+	case "drainBackend":
+		ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+		defer cancel()
+
+		b := client.IPBackend{
+			IP:      net.ParseIP(*ip),
+			Port:    int32(*port),
+			URLPath: *urlPath,
+		}
+		inFlight, err := c.DrainBackend(ctx, *pattern, b, 30*time.Second)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("backend drained with %d requests in-flight\n", inFlight)
+	case "exportConfig":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		config, err := c.ExportConfig(ctx)
+		if err != nil {
+			panic(err)
+		}
+		b, err := protojson.MarshalOptions{Multiline: true}.Marshal(config)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(b))
+	case "applyConfig":
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		b, err := os.ReadFile(*file)
+		if err != nil {
+			panic(err)
+		}
+		config := &pb.LBConfig{}
+		if err := protojson.Unmarshal(b, config); err != nil {
+			panic(err)
+		}
+		if err := c.ApplyConfig(ctx, config); err != nil {
+			panic(err)
+		}
+	case "addRoute":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		r := client.Route{
+			Name:       *route,
+			Priority:   int32(*priority),
+			Host:       *host,
+			PathPrefix: *prefix,
+			Pool:       *pattern,
+		}
+		if *headers != "" {
+			r.Headers = map[string]string{}
+			for _, h := range strings.Split(*headers, ",") {
+				k, v, _ := strings.Cut(h, "=")
+				r.Headers[k] = v
+			}
+		}
+		if *methods != "" {
+			r.Methods = strings.Split(*methods, ",")
+		}
+		if err := c.AddRoute(ctx, r); err != nil {
+			panic(err)
+		}
+	case "removeRoute":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := c.RemoveRoute(ctx, *route); err != nil {
+			panic(err)
+		}
+	case "listRoutes":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		routes, err := c.Routes(ctx)
+		if err != nil {
+			panic(err)
+		}
+		headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+		columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+		tbl := table.New("Route", "Priority", "Host", "Path Prefix", "Headers", "Methods", "Pool")
+		tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		for _, r := range routes {
+			var hdrs []string
+			for k, v := range r.Headers {
+				if v == "" {
+					hdrs = append(hdrs, k)
+					continue
+				}
+				hdrs = append(hdrs, k+"="+v)
+			}
+			sort.Strings(hdrs)
+			tbl.AddRow(r.Name, r.Priority, r.Host, r.PathPrefix, strings.Join(hdrs, ","), strings.Join(r.Methods, ","), r.Pool)
+		}
+		tbl.Print()
+	case "watchPoolHealth":
+		ph, updates, err := c.WatchPoolHealth(context.Background(), *pattern)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("pool %s is %s with %d backends\n", *pattern, ph.Status, len(ph.Backends))
+		for u := range updates {
+			if u.Err != nil {
+				panic(u.Err)
+			}
+			ev := u.Event
+			b := ev.Backend.GetIpBackend()
+			fmt.Printf(
+				"%s %s:%d%s %s %s\n",
+				time.Unix(0, ev.TimeUnixNano).Format(time.RFC3339),
+				b.GetIp(), b.GetPort(), b.GetUrlPath(), ev.Type, ev.Reason,
+			)
+		}
 	case "poolHealth":
 		ctx, _ := context.WithTimeout(context.Background(), 2*time.Second)
 		ph, err := c.PoolHealth(ctx, *pattern, true, true)
@@ -92,7 +302,7 @@ func main() {
 		tbl.AddRow(*pattern, ph.Status)
 		tbl.Print()
 
-		tbl = table.New("Backend", "Status")
+		tbl = table.New("Backend", "Status", "Weight", "Reason")
 		tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
 		for _, b := range ph.Backends {
 			switch {
@@ -101,6 +311,8 @@ func main() {
 				tbl.AddRow(
 					fmt.Sprintf("%s:%d%s", v.Ip, v.Port, v.UrlPath),
 					b.Status.String(),
+					b.Weight,
+					b.Reason,
 				)
 			}
 		}
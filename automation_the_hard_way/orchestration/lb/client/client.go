@@ -2,29 +2,49 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"sort"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
 // HealthChecks are a set of backend health checks that a backend must pass
-// in order to be considered healthy.
+// in order to be considered healthy. It also holds how a pool connects to its backends
+// and handles backends that fail live requests.
 type HealthChecks struct {
 	// HealthChecks are the checks to run against the backends.
 	HealthChecks []HealthCheck
 	// Interval is the between checks.
 	Interval time.Duration
+	// Rise is the number of checks in a row a sick backend must pass to return to service.
+	// Defaults to 1.
+	Rise int32
+	// Fall is the number of checks in a row a healthy backend must fail to be taken out
+	// of service. Defaults to 1.
+	Fall int32
+	// Outliers, if set, ejects backends whose live traffic has too many errors.
+	Outliers *OutlierDetection
+	// Retries, if set, retries requests that a backend failed on other backends.
+	Retries *RetryPolicy
+	// TLS, if set, connects to backends with TLS. Health checks use it too.
+	TLS *BackendTLS
 }
 
 func (h HealthChecks) toPB() *pb.HealthChecks {
 	hcs := &pb.HealthChecks{
 		IntervalSecs: int32(h.Interval / time.Second),
+		Rise:         h.Rise,
+		Fall:         h.Fall,
 	}
 	for _, hc := range h.HealthChecks {
 		p := hc.toPB()
@@ -33,6 +53,84 @@ func (h HealthChecks) toPB() *pb.HealthChecks {
 	return hcs
 }
 
+// OutlierDetection ejects backends from service when too many requests sent to them fail.
+// A request fails if the backend can't be reached or returns a 5xx status code.
+type OutlierDetection struct {
+	// ErrorRate is the fraction of requests, greater than 0 and at most 1, that must fail
+	// in an Interval to eject a backend.
+	ErrorRate float64
+	// MinRequests is the number of requests a backend must receive in an Interval before
+	// it can be ejected. Defaults to 10.
+	MinRequests int32
+	// Interval is how often error rates are checked. Defaults to 10 seconds.
+	Interval time.Duration
+	// BaseEjection is how long a backend is ejected the first time. Each time in a row
+	// it is ejected adds another BaseEjection. Defaults to 30 seconds.
+	BaseEjection time.Duration
+	// MaxEjectionPercent is the maximum percentage of backends that can be ejected at a time.
+	// Defaults to 50.
+	MaxEjectionPercent int32
+}
+
+func (o *OutlierDetection) toPB() *pb.OutlierDetection {
+	if o == nil {
+		return nil
+	}
+	return &pb.OutlierDetection{
+		ErrorRate:          o.ErrorRate,
+		MinRequests:        o.MinRequests,
+		IntervalSecs:       int32(o.Interval / time.Second),
+		BaseEjectionSecs:   int32(o.BaseEjection / time.Second),
+		MaxEjectionPercent: o.MaxEjectionPercent,
+	}
+}
+
+// RetryPolicy retries requests with idempotent methods on a different backend when the
+// backend can't be reached, the try takes longer than PerTryTimeout or the backend returns
+// a 502, 503 or 504.
+type RetryPolicy struct {
+	// MaxRetries is the most times a request is retried. Must be at least 1.
+	MaxRetries int32
+	// PerTryTimeout is how long each try can take. If 0, tries only end when the request does.
+	PerTryTimeout time.Duration
+	// Budget is the number of retries allowed for each request the pool receives. This
+	// keeps retries from multiplying the load on failing backends. Defaults to 0.2.
+	Budget float64
+}
+
+func (r *RetryPolicy) toPB() *pb.RetryPolicy {
+	if r == nil {
+		return nil
+	}
+	return &pb.RetryPolicy{
+		MaxRetries:      r.MaxRetries,
+		PerTryTimeoutMs: int32(r.PerTryTimeout / time.Millisecond),
+		Budget:          r.Budget,
+	}
+}
+
+// BackendTLS connects to a pool's backends with TLS. The files are paths on the load balancer.
+type BackendTLS struct {
+	// CAFile is the CA backend certificates are verified with. If empty, the system's CAs are used.
+	CAFile string
+	// CertFile and KeyFile are the client certificate sent to backends for mutual TLS.
+	CertFile, KeyFile string
+	// ServerName is the name backend certificates must be for. If empty, this is the backend's IP.
+	ServerName string
+}
+
+func (b *BackendTLS) toPB() *pb.BackendTLS {
+	if b == nil {
+		return nil
+	}
+	return &pb.BackendTLS{
+		CaFile:     b.CAFile,
+		CertFile:   b.CertFile,
+		KeyFile:    b.KeyFile,
+		ServerName: b.ServerName,
+	}
+}
+
 // HealthCheck defines a health check that must pass for a backend in a Pool
 // to be considered healthy.
 type HealthCheck interface {
@@ -63,17 +161,166 @@ func (s StatusCheck) toPB() *pb.HealthCheck {
 
 func (s StatusCheck) isHealthCheck() {}
 
+// TCPCheck implements HealthCheck to check that a TCP connection can be made
+// to a node.
+type TCPCheck struct{}
+
+func (t TCPCheck) toPB() *pb.HealthCheck {
+	return &pb.HealthCheck{
+		HealthCheck: &pb.HealthCheck_TcpCheck{
+			TcpCheck: &pb.TCPCheck{},
+		},
+	}
+}
+
+func (t TCPCheck) isHealthCheck() {}
+
+// GRPCCheck implements HealthCheck to check a node with the grpc.health.v1.Health
+// service. If it reports SERVING, the node is healthy.
+type GRPCCheck struct {
+	// Port is the port of the gRPC service. Defaults to the backend's port.
+	Port int32
+	// Service is the service to check. If empty, the server's overall health is checked.
+	Service string
+}
+
+func (g GRPCCheck) toPB() *pb.HealthCheck {
+	return &pb.HealthCheck{
+		HealthCheck: &pb.HealthCheck_GrpcCheck{
+			GrpcCheck: &pb.GRPCCheck{
+				Port:    g.Port,
+				Service: g.Service,
+			},
+		},
+	}
+}
+
+func (g GRPCCheck) isHealthCheck() {}
+
+// HTTPCodeCheck implements HealthCheck to check that a node at "URLPath" returns
+// a status code between "MinCode" and "MaxCode", inclusive.
+type HTTPCodeCheck struct {
+	// URLPath is the path to the health status page, like "/health".
+	URLPath string
+	// MinCode is the lowest healthy status code. Defaults to 200.
+	MinCode int32
+	// MaxCode is the highest healthy status code. Defaults to 399.
+	MaxCode int32
+}
+
+func (h HTTPCodeCheck) toPB() *pb.HealthCheck {
+	return &pb.HealthCheck{
+		HealthCheck: &pb.HealthCheck_HttpCodeCheck{
+			HttpCodeCheck: &pb.HTTPCodeCheck{
+				UrlPath: h.URLPath,
+				MinCode: h.MinCode,
+				MaxCode: h.MaxCode,
+			},
+		},
+	}
+}
+
+func (h HTTPCodeCheck) isHealthCheck() {}
+
+// LatencyCheck implements HealthCheck to check that a node at "URLPath" responds
+// within "MaxLatency" without a 5xx status code.
+type LatencyCheck struct {
+	// URLPath is the path to the health status page, like "/health".
+	URLPath string
+	// MaxLatency is the longest the response can take. It has millisecond precision.
+	MaxLatency time.Duration
+}
+
+func (l LatencyCheck) toPB() *pb.HealthCheck {
+	return &pb.HealthCheck{
+		HealthCheck: &pb.HealthCheck_LatencyCheck{
+			LatencyCheck: &pb.LatencyCheck{
+				UrlPath:      l.URLPath,
+				MaxLatencyMs: int32(l.MaxLatency / time.Millisecond),
+			},
+		},
+	}
+}
+
+func (l LatencyCheck) isHealthCheck() {}
+
+// HashKey is the part of a request that a PT_CONSISTENT_HASH pool hashes to choose
+// a backend. Only one of Header or Cookie may be set. If a request doesn't have the
+// key, the client's IP is hashed instead.
+type HashKey struct {
+	// Header is the name of a header whose value is hashed.
+	Header string
+	// Cookie is the name of a cookie whose value is hashed.
+	Cookie string
+}
+
+func (h HashKey) toPB() *pb.HashKey {
+	switch {
+	case h.Header != "":
+		return &pb.HashKey{Key: &pb.HashKey_Header{Header: h.Header}}
+	case h.Cookie != "":
+		return &pb.HashKey{Key: &pb.HashKey_Cookie{Cookie: h.Cookie}}
+	}
+	return nil
+}
+
 // Client is a client to the Quote of the day server.
 type Client struct {
 	client pb.LoadBalancerClient
 	conn   *grpc.ClientConn
 }
 
-// New is the constructor for Client. addr is the server's [host]:[port].
-func New(addr string) (*Client, error) {
-	creds := grpc.WithTransportCredentials(insecure.NewCredentials())
+// New is the constructor for Client. addr is the server's [host]:[port]. opts are
+// extra options for the connection, such as Token().
+func New(addr string, opts ...grpc.DialOption) (*Client, error) {
+	return dial(addr, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials())))
+}
+
+// NewTLS is the constructor for a Client that connects to a server using TLS. The client
+// sends the certificate in certFile and keyFile and the server's certificate must be
+// signed by the CA in caFile.
+func NewTLS(addr, certFile, keyFile, caFile string, opts ...grpc.DialOption) (*Client, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("caFile(%s) had no certificates", caFile)
+	}
+
+	tc := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}
+	return dial(addr, append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tc))))
+}
+
+// Token returns an option that sends tok to the server on every call, for servers that
+// authenticate callers with tokens. Tokens should only be sent over TLS.
+func Token(tok string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(tokenCreds(tok))
+}
+
+// tokenCreds implements credentials.PerRPCCredentials for a bearer token.
+type tokenCreds string
+
+func (t tokenCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false so that a token can be used with New() in testing.
+func (t tokenCreds) RequireTransportSecurity() bool {
+	return false
+}
 
-	conn, err := grpc.Dial(addr, creds, grpc.WithBlock())
+func dial(addr string, opts []grpc.DialOption) (*Client, error) {
+	conn, err := grpc.Dial(addr, append(opts, grpc.WithBlock())...)
 	if err != nil {
 		return nil, err
 	}
@@ -88,20 +335,62 @@ func New(addr string) (*Client, error) {
 // AddPool adds a pool that serves "pattern" using a PoolType that controls how
 // the pool load balances traffic and a HealthCheck to determine if a node is healthy.
 func (c *Client) AddPool(ctx context.Context, pattern string, pt pb.PoolType, hcs HealthChecks) error {
+	return c.addPool(
+		ctx,
+		&pb.AddPoolReq{
+			Pattern:          pattern,
+			PoolType:         pt,
+			HealthChecks:     hcs.toPB(),
+			OutlierDetection: hcs.Outliers.toPB(),
+			RetryPolicy:      hcs.Retries.toPB(),
+			BackendTls:       hcs.TLS.toPB(),
+		},
+	)
+}
+
+// AddHashPool adds a PT_CONSISTENT_HASH pool that serves "pattern". Requests with the same
+// value for "key" are sent to the same backend while it is healthy.
+func (c *Client) AddHashPool(ctx context.Context, pattern string, key HashKey, hcs HealthChecks) error {
+	return c.addPool(
+		ctx,
+		&pb.AddPoolReq{
+			Pattern:          pattern,
+			PoolType:         pb.PoolType_PT_CONSISTENT_HASH,
+			HealthChecks:     hcs.toPB(),
+			HashKey:          key.toPB(),
+			OutlierDetection: hcs.Outliers.toPB(),
+			RetryPolicy:      hcs.Retries.toPB(),
+			BackendTls:       hcs.TLS.toPB(),
+		},
+	)
+}
+
+// AddRouteOnlyPool adds a pool named "name" that only receives requests sent to it by
+// a Route. "key" is only used by PT_CONSISTENT_HASH pools.
+func (c *Client) AddRouteOnlyPool(ctx context.Context, name string, pt pb.PoolType, key HashKey, hcs HealthChecks) error {
+	return c.addPool(
+		ctx,
+		&pb.AddPoolReq{
+			Pattern:          name,
+			PoolType:         pt,
+			HealthChecks:     hcs.toPB(),
+			HashKey:          key.toPB(),
+			OutlierDetection: hcs.Outliers.toPB(),
+			RetryPolicy:      hcs.Retries.toPB(),
+			BackendTls:       hcs.TLS.toPB(),
+			RouteOnly:        true,
+		},
+	)
+}
+
+func (c *Client) addPool(ctx context.Context, req *pb.AddPoolReq) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
 		defer cancel()
 	}
 
-	_, err := c.client.AddPool(
-		ctx,
-		&pb.AddPoolReq{
-			Pattern:      pattern,
-			PoolType:     pt,
-			HealthChecks: hcs.toPB(),
-		},
-	)
+	_, err := c.client.AddPool(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -139,14 +428,20 @@ func (i IPBackend) isBackend() {}
 
 // AddBackend adds backend "b" from the pool serving "pattern".
 func (c *Client) AddBackend(ctx context.Context, pattern string, b Backend) error {
+	return c.AddWeightedBackend(ctx, pattern, b, 0)
+}
+
+// AddWeightedBackend adds backend "b" with "weight" to the PT_WEIGHTED pool serving "pattern".
+// If weight is 0, the server's default weight is used.
+func (c *Client) AddWeightedBackend(ctx context.Context, pattern string, b Backend, weight int32) error {
 	switch v := b.(type) {
 	case IPBackend:
-		return c.addIPBackend(ctx, pattern, v)
+		return c.addIPBackend(ctx, pattern, v, weight)
 	}
 	return fmt.Errorf("Backend is not a recognized type(%T)", b)
 }
 
-func (c *Client) addIPBackend(ctx context.Context, pattern string, b IPBackend) error {
+func (c *Client) addIPBackend(ctx context.Context, pattern string, b IPBackend, weight int32) error {
 	_, err := c.client.AddBackend(
 		ctx,
 		&pb.AddBackendReq{
@@ -160,6 +455,39 @@ func (c *Client) addIPBackend(ctx context.Context, pattern string, b IPBackend)
 					},
 				},
 			},
+			Weight: weight,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetBackendWeight sets the weight of backend "b" in the PT_WEIGHTED pool serving "pattern".
+func (c *Client) SetBackendWeight(ctx context.Context, pattern string, b Backend, weight int32) error {
+	switch v := b.(type) {
+	case IPBackend:
+		return c.setIPBackendWeight(ctx, pattern, v, weight)
+	}
+	return fmt.Errorf("Backend is not a recognized type(%T)", b)
+}
+
+func (c *Client) setIPBackendWeight(ctx context.Context, pattern string, b IPBackend, weight int32) error {
+	_, err := c.client.SetBackendWeight(
+		ctx,
+		&pb.SetBackendWeightReq{
+			Pattern: pattern,
+			Backend: &pb.Backend{
+				Backend: &pb.Backend_IpBackend{
+					IpBackend: &pb.IPBackend{
+						Ip:      b.IP.String(),
+						Port:    b.Port,
+						UrlPath: b.URLPath,
+					},
+				},
+			},
+			Weight: weight,
 		},
 	)
 	if err != nil {
@@ -199,6 +527,181 @@ func (c *Client) removeIPBackend(ctx context.Context, pattern string, b IPBacken
 	return nil
 }
 
+// DrainBackend stops the pool serving "pattern" from sending new requests to backend "b"
+// and waits up to timeout for its in-flight requests to finish. The number of requests
+// still in-flight when the timeout passed is returned. If timeout is 0, the server
+// uses its default of 30 seconds. The backend must still be removed with RemoveBackend().
+func (c *Client) DrainBackend(ctx context.Context, pattern string, b Backend, timeout time.Duration) (int32, error) {
+	switch v := b.(type) {
+	case IPBackend:
+		return c.drainIPBackend(ctx, pattern, v, timeout)
+	}
+	return 0, fmt.Errorf("Backend is not a recognized type(%T)", b)
+}
+
+func (c *Client) drainIPBackend(ctx context.Context, pattern string, b IPBackend, timeout time.Duration) (int32, error) {
+	resp, err := c.client.DrainBackend(
+		ctx,
+		&pb.DrainBackendReq{
+			Pattern: pattern,
+			Backend: &pb.Backend{
+				Backend: &pb.Backend_IpBackend{
+					IpBackend: &pb.IPBackend{
+						Ip:      b.IP.String(),
+						Port:    b.Port,
+						UrlPath: b.URLPath,
+					},
+				},
+			},
+			TimeoutSecs: int32(timeout / time.Second),
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return resp.InFlight, nil
+}
+
+// ExportConfig returns the configuration of all the load balancer's pools and their backends.
+func (c *Client) ExportConfig(ctx context.Context) (*pb.LBConfig, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	resp, err := c.client.ExportConfig(ctx, &pb.ExportConfigReq{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Config, nil
+}
+
+// ApplyConfig replaces all the load balancer's pools with the ones in config. Either all of
+// config is applied or none of it is.
+func (c *Client) ApplyConfig(ctx context.Context, config *pb.LBConfig) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+	}
+
+	_, err := c.client.ApplyConfig(ctx, &pb.ApplyConfigReq{Config: config})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Route sends requests that match all of its set fields to a pool. Routes are checked
+// before the patterns pools serve, highest Priority first.
+type Route struct {
+	// Name is the unique name of the route.
+	Name string
+	// Priority decides the order routes are checked in, highest first.
+	Priority int32
+	// Host matches the request's host without the port. "*.example.com" matches
+	// any subdomain of example.com.
+	Host string
+	// PathPrefix matches requests whose URL path starts with it.
+	PathPrefix string
+	// Headers match requests that have all these headers. An empty value only
+	// requires the header to be present.
+	Headers map[string]string
+	// Methods match requests using any of these methods.
+	Methods []string
+	// Pool is the pattern or name of the pool to send requests to.
+	Pool string
+}
+
+func (r Route) toPB() *pb.Route {
+	names := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pr := &pb.Route{
+		Name:       r.Name,
+		Priority:   r.Priority,
+		Host:       r.Host,
+		PathPrefix: r.PathPrefix,
+		Methods:    r.Methods,
+		Pool:       r.Pool,
+	}
+	for _, k := range names {
+		pr.Headers = append(pr.Headers, &pb.HeaderMatch{Name: k, Value: r.Headers[k]})
+	}
+	return pr
+}
+
+func routeFromPB(pr *pb.Route) Route {
+	r := Route{
+		Name:       pr.Name,
+		Priority:   pr.Priority,
+		Host:       pr.Host,
+		PathPrefix: pr.PathPrefix,
+		Methods:    pr.Methods,
+		Pool:       pr.Pool,
+	}
+	if len(pr.Headers) > 0 {
+		r.Headers = make(map[string]string, len(pr.Headers))
+		for _, h := range pr.Headers {
+			r.Headers[h.Name] = h.Value
+		}
+	}
+	return r
+}
+
+// AddRoute adds route "r". The pool it uses must already exist.
+func (c *Client) AddRoute(ctx context.Context, r Route) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	_, err := c.client.AddRoute(ctx, &pb.AddRouteReq{Route: r.toPB()})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveRoute removes the route called "name".
+func (c *Client) RemoveRoute(ctx context.Context, name string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	_, err := c.client.RemoveRoute(ctx, &pb.RemoveRouteReq{Name: name})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Routes returns all the load balancer's routes in the order they are checked.
+func (c *Client) Routes(ctx context.Context) ([]Route, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	resp, err := c.client.ListRoutes(ctx, &pb.ListRoutesReq{})
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]Route, 0, len(resp.Routes))
+	for _, r := range resp.Routes {
+		routes = append(routes, routeFromPB(r))
+	}
+	return routes, nil
+}
+
 // PoolHealth queries the server for the health of the pool that serves "pattern".
 // healthy and sick determine what node information is included.
 func (c *Client) PoolHealth(ctx context.Context, pattern string, healthy, sick bool) (*pb.PoolHealth, error) {
@@ -215,3 +718,60 @@ func (c *Client) PoolHealth(ctx context.Context, pattern string, healthy, sick b
 	}
 	return resp.Health, nil
 }
+
+// HealthUpdate is sent on the channel returned by WatchPoolHealth. Either Event or Err is set.
+type HealthUpdate struct {
+	// Event is a change to a backend in the pool.
+	Event *pb.BackendEvent
+	// Err is why the watch ended. It is only set on the last HealthUpdate.
+	Err error
+}
+
+// WatchPoolHealth watches the health of the pool that serves "pattern". It returns the
+// pool's current health, with all its backends, and a channel that receives every change
+// to its backends after that. The channel is closed when ctx is done or the watch ends. If
+// the watch ended for any other reason, the last HealthUpdate has the error and you should
+// watch again to get the current health.
+func (c *Client) WatchPoolHealth(ctx context.Context, pattern string) (*pb.PoolHealth, <-chan HealthUpdate, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	stream, err := c.client.WatchPoolHealth(ctx, &pb.WatchPoolHealthReq{Pattern: pattern})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if resp.GetHealth() == nil {
+		cancel()
+		return nil, nil, fmt.Errorf("server did not send the pool's health first")
+	}
+
+	ch := make(chan HealthUpdate, 1)
+	go func() {
+		defer cancel()
+		defer close(ch)
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				select {
+				case ch <- HealthUpdate{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if ev := resp.GetEvent(); ev != nil {
+				select {
+				case ch <- HealthUpdate{Event: ev}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return resp.GetHealth(), ch, nil
+}
@@ -1,22 +1,53 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"log"
 	"net"
+	gohttp "net/http"
+	"strings"
+	"time"
 
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/grpc"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/http"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/telemetry/metrics"
+
+	gogrpc "google.golang.org/grpc"
 )
 
 var (
 	httpAddr = flag.String("httpAddr", "localhost:9090", "The addr:port to listen on for HTTP requests for load-balancing")
 	grpcAddr = flag.String("grpcAddr", "localhost:9091", "The addr:port to listen on for gRPC control requests")
+	state    = flag.String("state", "", "If set, the pools and backends are saved to this file after every change and restored from it on startup")
+	config   = flag.String("config", "", "If set, a config file to load on startup instead of the file at --state")
+
+	httpsAddr = flag.String("httpsAddr", "", "If set, the addr:port to listen on for HTTPS requests for load-balancing, using the --certs")
+	certs     = flag.String("certs", "", "Comma separated certFile:keyFile pairs to serve on --httpsAddr. The certificate is chosen by the server name the client asks for and the first one is the default")
+	grpcCert  = flag.String("grpcCert", "", "If set with --grpcKey and --grpcCA, the gRPC control service uses TLS with this certificate")
+	grpcKey   = flag.String("grpcKey", "", "The key for --grpcCert")
+	grpcCA    = flag.String("grpcCA", "", "The CA that gRPC clients must have certificates from")
+
+	tokens = flag.String("tokens", "", "If set, a file of 'name token' lines. gRPC callers must send one of the tokens, or a client certificate if TLS is on")
+	acl    = flag.String("acl", "", "If set, a JSON ACL file that controls which gRPC callers can read and change which pools")
+	audit  = flag.String("audit", "", "If set, every gRPC call that changes the load balancer is appended to this file")
+
+	metricsAddr = flag.String("metricsAddr", "localhost:9089", "The addr:port to serve Prometheus metrics on at /metrics. If empty, this is disabled")
+	otelAddr    = flag.String("otelAddr", "", "If set, the addr:port of an OTEL collector to send metrics to over gRPC")
 )
 
 func main() {
 	flag.Parse()
 
+	stop, err := startMetrics()
+	if err != nil {
+		panic(err)
+	}
+	defer stop()
+
 	ln, err := net.Listen("tcp", *httpAddr)
 	if err != nil {
 		panic(err)
@@ -34,13 +65,181 @@ func main() {
 		}
 	}()
 
-	serv, err := grpc.New(*grpcAddr, lb)
+	if err := serveTLS(lb); err != nil {
+		panic(err)
+	}
+
+	opts, err := grpcOptions()
 	if err != nil {
 		panic(err)
 	}
+	serv, err := grpc.New(*grpcAddr, lb, opts...)
+	if err != nil {
+		panic(err)
+	}
+	serv.StateFile = *state
+
+	if err := setupAuth(serv); err != nil {
+		panic(err)
+	}
+
+	if err := load(serv); err != nil {
+		panic(err)
+	}
 
 	log.Printf("grpc server started(%s)...", *grpcAddr)
 	if err := serv.Start(); err != nil {
 		panic(err)
 	}
 }
+
+// serveTLS serves HTTPS on --httpsAddr with the --certs, if set.
+func serveTLS(lb *http.LoadBalancer) error {
+	if *httpsAddr == "" {
+		return nil
+	}
+
+	var pairs []http.CertPair
+	for _, c := range strings.Split(*certs, ",") {
+		cert, key, ok := strings.Cut(c, ":")
+		if !ok {
+			return fmt.Errorf("--certs entry %q must be certFile:keyFile", c)
+		}
+		pairs = append(pairs, http.CertPair{CertFile: cert, KeyFile: key})
+	}
+	cs, err := http.NewCertStore(pairs, 0)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *httpsAddr)
+	if err != nil {
+		return err
+	}
+	log.Printf("load balancer started(%s) with TLS...", *httpsAddr)
+	go func() {
+		if err := lb.ServeCerts(ln, cs); err != nil {
+			panic(err)
+		}
+	}()
+	return nil
+}
+
+// setupAuth sets up authentication, authorization and auditing on serv from our flags.
+// Callers are authenticated by token if --tokens is set and by client certificate if
+// the gRPC service uses TLS.
+func setupAuth(serv *grpc.Server) error {
+	var auths grpc.MultiAuth
+	if *tokens != "" {
+		ta, err := grpc.LoadTokens(*tokens)
+		if err != nil {
+			return err
+		}
+		auths = append(auths, ta)
+	}
+	if *grpcCert != "" {
+		auths = append(auths, grpc.MTLSAuth{})
+	}
+	if len(auths) > 0 {
+		serv.Authenticator = auths
+	}
+
+	if *acl != "" {
+		if len(auths) == 0 {
+			return fmt.Errorf("--acl requires --tokens or gRPC TLS so that callers are known")
+		}
+		a, err := grpc.LoadACL(*acl)
+		if err != nil {
+			return err
+		}
+		serv.Authorizer = a
+	}
+
+	if *audit != "" {
+		a, err := grpc.OpenAuditLog(*audit)
+		if err != nil {
+			return err
+		}
+		serv.Audit = a
+	}
+	return nil
+}
+
+// grpcOptions returns the options for our gRPC server. If --grpcCert is set, the server
+// uses TLS and requires client certificates.
+func grpcOptions() ([]gogrpc.ServerOption, error) {
+	if *grpcCert == "" && *grpcKey == "" && *grpcCA == "" {
+		return nil, nil
+	}
+	if *grpcCert == "" || *grpcKey == "" || *grpcCA == "" {
+		return nil, fmt.Errorf("--grpcCert, --grpcKey and --grpcCA must all be set")
+	}
+
+	cs, err := http.NewCertStore([]http.CertPair{{CertFile: *grpcCert, KeyFile: *grpcKey}}, 0)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := grpc.TLSCreds(cs, *grpcCA)
+	if err != nil {
+		return nil, err
+	}
+	return []gogrpc.ServerOption{creds}, nil
+}
+
+// load loads --config if set. Otherwise it restores the --state file if it exists.
+func load(serv *grpc.Server) error {
+	p := *config
+	if p == "" {
+		p = *state
+	}
+	if p == "" {
+		return nil
+	}
+
+	// Backends are health checked as they are loaded, which can take a while.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	err := serv.Load(ctx, p)
+	switch {
+	case err == nil:
+		log.Printf("loaded config from %s", p)
+	case *config == "" && errors.Is(err, fs.ErrNotExist):
+		log.Printf("no state file at %s, starting empty", p)
+	default:
+		return err
+	}
+	return nil
+}
+
+// startMetrics exports our metrics to the OTEL collector at --otelAddr and serves them
+// for Prometheus on --metricsAddr.
+func startMetrics() (metrics.Stop, error) {
+	var controllers []metrics.Controller
+	if *otelAddr != "" {
+		controllers = append(controllers, metrics.OTELGRPC{Addr: *otelAddr})
+	}
+	if *metricsAddr != "" {
+		controllers = append(controllers, metrics.Prometheus{})
+	}
+	if len(controllers) == 0 {
+		return func() {}, nil
+	}
+
+	stop, err := metrics.Start(context.Background(), controllers...)
+	if err != nil {
+		return nil, err
+	}
+
+	if *metricsAddr != "" {
+		mux := gohttp.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		go func() {
+			log.Printf("metrics server started(%s)...", *metricsAddr)
+			if err := gohttp.ListenAndServe(*metricsAddr, mux); err != nil {
+				panic(err)
+			}
+		}()
+	}
+	return stop, nil
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
+// 	protoc-gen-go v1.30.0
 // 	protoc        v3.18.0
 // source: lb.proto
 
@@ -29,6 +29,19 @@ const (
 	PoolType_PT_UNKNOWN PoolType = 0
 	// The power of 2 choices selection pool.
 	PoolType_PT_P2C PoolType = 1
+	// A pool that sends each backend a share of the traffic based on its weight.
+	// In a pool with two backends, one with weight 1 and the other with weight
+	// 99, the first backend receives 1% of the traffic.
+	PoolType_PT_WEIGHTED PoolType = 2
+	// A pool that sends requests to each healthy backend in turn.
+	PoolType_PT_ROUND_ROBIN PoolType = 3
+	// A pool that sends each request to the healthy backend with the fewest
+	// in-flight requests.
+	PoolType_PT_LEAST_CONN PoolType = 4
+	// A pool that hashes the request's hash_key to choose a backend, so that
+	// requests with the same key go to the same backend while it is healthy.
+	// This provides session affinity.
+	PoolType_PT_CONSISTENT_HASH PoolType = 5
 )
 
 // Enum value maps for PoolType.
@@ -36,10 +49,18 @@ var (
 	PoolType_name = map[int32]string{
 		0: "PT_UNKNOWN",
 		1: "PT_P2C",
+		2: "PT_WEIGHTED",
+		3: "PT_ROUND_ROBIN",
+		4: "PT_LEAST_CONN",
+		5: "PT_CONSISTENT_HASH",
 	}
 	PoolType_value = map[string]int32{
-		"PT_UNKNOWN": 0,
-		"PT_P2C":     1,
+		"PT_UNKNOWN":         0,
+		"PT_P2C":             1,
+		"PT_WEIGHTED":        2,
+		"PT_ROUND_ROBIN":     3,
+		"PT_LEAST_CONN":      4,
+		"PT_CONSISTENT_HASH": 5,
 	}
 )
 
@@ -136,6 +157,8 @@ const (
 	BackendStatus_BS_HEALTHY BackendStatus = 1
 	// The node is sick according to its health checks.
 	BackendStatus_BS_SICK BackendStatus = 2
+	// The node is being drained and is not receiving new requests.
+	BackendStatus_BS_DRAINING BackendStatus = 3
 )
 
 // Enum value maps for BackendStatus.
@@ -144,11 +167,13 @@ var (
 		0: "BS_UNKNOWN",
 		1: "BS_HEALTHY",
 		2: "BS_SICK",
+		3: "BS_DRAINING",
 	}
 	BackendStatus_value = map[string]int32{
-		"BS_UNKNOWN": 0,
-		"BS_HEALTHY": 1,
-		"BS_SICK":    2,
+		"BS_UNKNOWN":  0,
+		"BS_HEALTHY":  1,
+		"BS_SICK":     2,
+		"BS_DRAINING": 3,
 	}
 )
 
@@ -179,6 +204,71 @@ func (BackendStatus) EnumDescriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{2}
 }
 
+// BackendEventType is the type of change to a backend in a pool.
+type BackendEventType int32
+
+const (
+	// This indicates an error by the developers.
+	BackendEventType_BET_UNKNOWN BackendEventType = 0
+	// The backend was added to the pool.
+	BackendEventType_BET_ADDED BackendEventType = 1
+	// The backend was removed from the pool.
+	BackendEventType_BET_REMOVED BackendEventType = 2
+	// The backend became healthy and was returned to service.
+	BackendEventType_BET_HEALTHY BackendEventType = 3
+	// The backend became sick and was taken out of service.
+	BackendEventType_BET_SICK BackendEventType = 4
+	// The backend started draining.
+	BackendEventType_BET_DRAINING BackendEventType = 5
+)
+
+// Enum value maps for BackendEventType.
+var (
+	BackendEventType_name = map[int32]string{
+		0: "BET_UNKNOWN",
+		1: "BET_ADDED",
+		2: "BET_REMOVED",
+		3: "BET_HEALTHY",
+		4: "BET_SICK",
+		5: "BET_DRAINING",
+	}
+	BackendEventType_value = map[string]int32{
+		"BET_UNKNOWN":  0,
+		"BET_ADDED":    1,
+		"BET_REMOVED":  2,
+		"BET_HEALTHY":  3,
+		"BET_SICK":     4,
+		"BET_DRAINING": 5,
+	}
+)
+
+func (x BackendEventType) Enum() *BackendEventType {
+	p := new(BackendEventType)
+	*p = x
+	return p
+}
+
+func (x BackendEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BackendEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_lb_proto_enumTypes[3].Descriptor()
+}
+
+func (BackendEventType) Type() protoreflect.EnumType {
+	return &file_lb_proto_enumTypes[3]
+}
+
+func (x BackendEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BackendEventType.Descriptor instead.
+func (BackendEventType) EnumDescriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{3}
+}
+
 type HealthChecks struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -186,6 +276,12 @@ type HealthChecks struct {
 
 	HealthChecks []*HealthCheck `protobuf:"bytes,1,rep,name=health_checks,json=healthChecks,proto3" json:"health_checks,omitempty"`
 	IntervalSecs int32          `protobuf:"varint,2,opt,name=interval_secs,json=intervalSecs,proto3" json:"interval_secs,omitempty"`
+	// The number of health checks in a row a sick backend must pass before it is
+	// returned to service. Defaults to 1.
+	Rise int32 `protobuf:"varint,3,opt,name=rise,proto3" json:"rise,omitempty"`
+	// The number of health checks in a row a healthy backend must fail before it is
+	// taken out of service. Defaults to 1.
+	Fall int32 `protobuf:"varint,4,opt,name=fall,proto3" json:"fall,omitempty"`
 }
 
 func (x *HealthChecks) Reset() {
@@ -234,6 +330,20 @@ func (x *HealthChecks) GetIntervalSecs() int32 {
 	return 0
 }
 
+func (x *HealthChecks) GetRise() int32 {
+	if x != nil {
+		return x.Rise
+	}
+	return 0
+}
+
+func (x *HealthChecks) GetFall() int32 {
+	if x != nil {
+		return x.Fall
+	}
+	return 0
+}
+
 type HealthCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -241,6 +351,10 @@ type HealthCheck struct {
 
 	// Types that are assignable to HealthCheck:
 	//	*HealthCheck_StatusCheck
+	//	*HealthCheck_TcpCheck
+	//	*HealthCheck_GrpcCheck
+	//	*HealthCheck_HttpCodeCheck
+	//	*HealthCheck_LatencyCheck
 	HealthCheck isHealthCheck_HealthCheck `protobuf_oneof:"health_check"`
 }
 
@@ -290,6 +404,34 @@ func (x *HealthCheck) GetStatusCheck() *StatusCheck {
 	return nil
 }
 
+func (x *HealthCheck) GetTcpCheck() *TCPCheck {
+	if x, ok := x.GetHealthCheck().(*HealthCheck_TcpCheck); ok {
+		return x.TcpCheck
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetGrpcCheck() *GRPCCheck {
+	if x, ok := x.GetHealthCheck().(*HealthCheck_GrpcCheck); ok {
+		return x.GrpcCheck
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetHttpCodeCheck() *HTTPCodeCheck {
+	if x, ok := x.GetHealthCheck().(*HealthCheck_HttpCodeCheck); ok {
+		return x.HttpCodeCheck
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetLatencyCheck() *LatencyCheck {
+	if x, ok := x.GetHealthCheck().(*HealthCheck_LatencyCheck); ok {
+		return x.LatencyCheck
+	}
+	return nil
+}
+
 type isHealthCheck_HealthCheck interface {
 	isHealthCheck_HealthCheck()
 }
@@ -298,8 +440,32 @@ type HealthCheck_StatusCheck struct {
 	StatusCheck *StatusCheck `protobuf:"bytes,1,opt,name=status_check,json=statusCheck,proto3,oneof"`
 }
 
+type HealthCheck_TcpCheck struct {
+	TcpCheck *TCPCheck `protobuf:"bytes,2,opt,name=tcp_check,json=tcpCheck,proto3,oneof"`
+}
+
+type HealthCheck_GrpcCheck struct {
+	GrpcCheck *GRPCCheck `protobuf:"bytes,3,opt,name=grpc_check,json=grpcCheck,proto3,oneof"`
+}
+
+type HealthCheck_HttpCodeCheck struct {
+	HttpCodeCheck *HTTPCodeCheck `protobuf:"bytes,4,opt,name=http_code_check,json=httpCodeCheck,proto3,oneof"`
+}
+
+type HealthCheck_LatencyCheck struct {
+	LatencyCheck *LatencyCheck `protobuf:"bytes,5,opt,name=latency_check,json=latencyCheck,proto3,oneof"`
+}
+
 func (*HealthCheck_StatusCheck) isHealthCheck_HealthCheck() {}
 
+func (*HealthCheck_TcpCheck) isHealthCheck_HealthCheck() {}
+
+func (*HealthCheck_GrpcCheck) isHealthCheck_HealthCheck() {}
+
+func (*HealthCheck_HttpCodeCheck) isHealthCheck_HealthCheck() {}
+
+func (*HealthCheck_LatencyCheck) isHealthCheck_HealthCheck() {}
+
 // StatusCheck is a check against a URL path. That path must
 // emit in its body one of the healthy_values or it fails.
 type StatusCheck struct {
@@ -357,18 +523,15 @@ func (x *StatusCheck) GetHealthyValues() []string {
 	return nil
 }
 
-type Backend struct {
+// TCPCheck is a check that a TCP connection can be made to the backend.
+type TCPCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	// Types that are assignable to Backend:
-	//	*Backend_IpBackend
-	Backend isBackend_Backend `protobuf_oneof:"backend"`
 }
 
-func (x *Backend) Reset() {
-	*x = Backend{}
+func (x *TCPCheck) Reset() {
+	*x = TCPCheck{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -376,13 +539,13 @@ func (x *Backend) Reset() {
 	}
 }
 
-func (x *Backend) String() string {
+func (x *TCPCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Backend) ProtoMessage() {}
+func (*TCPCheck) ProtoMessage() {}
 
-func (x *Backend) ProtoReflect() protoreflect.Message {
+func (x *TCPCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -394,51 +557,26 @@ func (x *Backend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
-func (*Backend) Descriptor() ([]byte, []int) {
+// Deprecated: Use TCPCheck.ProtoReflect.Descriptor instead.
+func (*TCPCheck) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{3}
 }
 
-func (m *Backend) GetBackend() isBackend_Backend {
-	if m != nil {
-		return m.Backend
-	}
-	return nil
-}
-
-func (x *Backend) GetIpBackend() *IPBackend {
-	if x, ok := x.GetBackend().(*Backend_IpBackend); ok {
-		return x.IpBackend
-	}
-	return nil
-}
-
-type isBackend_Backend interface {
-	isBackend_Backend()
-}
-
-type Backend_IpBackend struct {
-	IpBackend *IPBackend `protobuf:"bytes,1,opt,name=ip_backend,json=ipBackend,proto3,oneof"`
-}
-
-func (*Backend_IpBackend) isBackend_Backend() {}
-
-// IPBackend provides a Backend that has its endpoint as an ip:port.
-type IPBackend struct {
+// GRPCCheck is a check against the backend's grpc.health.v1.Health service.
+// The service must report SERVING.
+type GRPCCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The IP(v4 or v6) to use as the host.
-	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
-	// The port number to connect on.
-	Port int32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	// The url_path to forward to. Generally this is empty.
-	UrlPath string `protobuf:"bytes,3,opt,name=url_path,json=urlPath,proto3" json:"url_path,omitempty"`
+	// The port the gRPC service is on. Defaults to the backend's port.
+	Port int32 `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	// The service name to check. If empty, the overall health of the server is checked.
+	Service string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
 }
 
-func (x *IPBackend) Reset() {
-	*x = IPBackend{}
+func (x *GRPCCheck) Reset() {
+	*x = GRPCCheck{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -446,13 +584,13 @@ func (x *IPBackend) Reset() {
 	}
 }
 
-func (x *IPBackend) String() string {
+func (x *GRPCCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IPBackend) ProtoMessage() {}
+func (*GRPCCheck) ProtoMessage() {}
 
-func (x *IPBackend) ProtoReflect() protoreflect.Message {
+func (x *GRPCCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -464,47 +602,41 @@ func (x *IPBackend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IPBackend.ProtoReflect.Descriptor instead.
-func (*IPBackend) Descriptor() ([]byte, []int) {
+// Deprecated: Use GRPCCheck.ProtoReflect.Descriptor instead.
+func (*GRPCCheck) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *IPBackend) GetIp() string {
-	if x != nil {
-		return x.Ip
-	}
-	return ""
-}
-
-func (x *IPBackend) GetPort() int32 {
+func (x *GRPCCheck) GetPort() int32 {
 	if x != nil {
 		return x.Port
 	}
 	return 0
 }
 
-func (x *IPBackend) GetUrlPath() string {
+func (x *GRPCCheck) GetService() string {
 	if x != nil {
-		return x.UrlPath
+		return x.Service
 	}
 	return ""
 }
 
-// PoolHealth details the health status of a pool.
-type PoolHealth struct {
+// HTTPCodeCheck is a check against a URL path. The response's status code must be
+// between min_code and max_code, inclusive.
+type HTTPCodeCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The status of the pool.
-	Status PoolStatus `protobuf:"varint,1,opt,name=status,proto3,enum=rollout.lb.PoolStatus" json:"status,omitempty"`
-	// The pool's backend health status. This can contain all backends
-	// or only sick/healthy backends, depending on the request.
-	Backends []*BackendHealth `protobuf:"bytes,2,rep,name=backends,proto3" json:"backends,omitempty"`
+	UrlPath string `protobuf:"bytes,1,opt,name=url_path,json=urlPath,proto3" json:"url_path,omitempty"`
+	// Defaults to 200.
+	MinCode int32 `protobuf:"varint,2,opt,name=min_code,json=minCode,proto3" json:"min_code,omitempty"`
+	// Defaults to 399.
+	MaxCode int32 `protobuf:"varint,3,opt,name=max_code,json=maxCode,proto3" json:"max_code,omitempty"`
 }
 
-func (x *PoolHealth) Reset() {
-	*x = PoolHealth{}
+func (x *HTTPCodeCheck) Reset() {
+	*x = HTTPCodeCheck{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -512,13 +644,13 @@ func (x *PoolHealth) Reset() {
 	}
 }
 
-func (x *PoolHealth) String() string {
+func (x *HTTPCodeCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolHealth) ProtoMessage() {}
+func (*HTTPCodeCheck) ProtoMessage() {}
 
-func (x *PoolHealth) ProtoReflect() protoreflect.Message {
+func (x *HTTPCodeCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -530,37 +662,45 @@ func (x *PoolHealth) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolHealth.ProtoReflect.Descriptor instead.
-func (*PoolHealth) Descriptor() ([]byte, []int) {
+// Deprecated: Use HTTPCodeCheck.ProtoReflect.Descriptor instead.
+func (*HTTPCodeCheck) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *PoolHealth) GetStatus() PoolStatus {
+func (x *HTTPCodeCheck) GetUrlPath() string {
 	if x != nil {
-		return x.Status
+		return x.UrlPath
 	}
-	return PoolStatus_PS_UNKNOWN
+	return ""
 }
 
-func (x *PoolHealth) GetBackends() []*BackendHealth {
+func (x *HTTPCodeCheck) GetMinCode() int32 {
 	if x != nil {
-		return x.Backends
+		return x.MinCode
 	}
-	return nil
+	return 0
 }
 
-// BackendHealth details the health status of a backend.
-type BackendHealth struct {
+func (x *HTTPCodeCheck) GetMaxCode() int32 {
+	if x != nil {
+		return x.MaxCode
+	}
+	return 0
+}
+
+// LatencyCheck is a check against a URL path. The backend must send its full
+// response within max_latency_ms and the status code must not be 5xx.
+type LatencyCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Backend *Backend      `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
-	Status  BackendStatus `protobuf:"varint,2,opt,name=status,proto3,enum=rollout.lb.BackendStatus" json:"status,omitempty"`
+	UrlPath      string `protobuf:"bytes,1,opt,name=url_path,json=urlPath,proto3" json:"url_path,omitempty"`
+	MaxLatencyMs int32  `protobuf:"varint,2,opt,name=max_latency_ms,json=maxLatencyMs,proto3" json:"max_latency_ms,omitempty"`
 }
 
-func (x *BackendHealth) Reset() {
-	*x = BackendHealth{}
+func (x *LatencyCheck) Reset() {
+	*x = LatencyCheck{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -568,13 +708,13 @@ func (x *BackendHealth) Reset() {
 	}
 }
 
-func (x *BackendHealth) String() string {
+func (x *LatencyCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BackendHealth) ProtoMessage() {}
+func (*LatencyCheck) ProtoMessage() {}
 
-func (x *BackendHealth) ProtoReflect() protoreflect.Message {
+func (x *LatencyCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -586,41 +726,52 @@ func (x *BackendHealth) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BackendHealth.ProtoReflect.Descriptor instead.
-func (*BackendHealth) Descriptor() ([]byte, []int) {
+// Deprecated: Use LatencyCheck.ProtoReflect.Descriptor instead.
+func (*LatencyCheck) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *BackendHealth) GetBackend() *Backend {
+func (x *LatencyCheck) GetUrlPath() string {
 	if x != nil {
-		return x.Backend
+		return x.UrlPath
 	}
-	return nil
+	return ""
 }
 
-func (x *BackendHealth) GetStatus() BackendStatus {
+func (x *LatencyCheck) GetMaxLatencyMs() int32 {
 	if x != nil {
-		return x.Status
+		return x.MaxLatencyMs
 	}
-	return BackendStatus_BS_UNKNOWN
+	return 0
 }
 
-// AddPoolReq requests to create a pool for handling requests.
-type AddPoolReq struct {
+// OutlierDetection ejects a backend from service when the live traffic sent to it
+// has too many errors. A request is an error if the backend can't be reached or it
+// returns a 5xx status code. An ejected backend is sick for base_ejection_secs times
+// the number of times in a row it was ejected, then returns to service once it passes
+// its health checks.
+type OutlierDetection struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The URL pattern to direct traffic too.
-	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	// The type of traffic distribution pool to use.
-	PoolType PoolType `protobuf:"varint,2,opt,name=pool_type,json=poolType,proto3,enum=rollout.lb.PoolType" json:"pool_type,omitempty"`
-	// Health checks to against backends.
-	HealthChecks *HealthChecks `protobuf:"bytes,4,opt,name=health_checks,json=healthChecks,proto3" json:"health_checks,omitempty"`
-}
-
-func (x *AddPoolReq) Reset() {
-	*x = AddPoolReq{}
+	// The fraction of requests, greater than 0 and at most 1, that must fail in an
+	// interval to eject a backend.
+	ErrorRate float64 `protobuf:"fixed64,1,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	// The number of requests a backend must receive in an interval before it can be
+	// ejected. Defaults to 10.
+	MinRequests int32 `protobuf:"varint,2,opt,name=min_requests,json=minRequests,proto3" json:"min_requests,omitempty"`
+	// How often error rates are checked. Defaults to 10.
+	IntervalSecs int32 `protobuf:"varint,3,opt,name=interval_secs,json=intervalSecs,proto3" json:"interval_secs,omitempty"`
+	// How long a backend is ejected the first time. Defaults to 30.
+	BaseEjectionSecs int32 `protobuf:"varint,4,opt,name=base_ejection_secs,json=baseEjectionSecs,proto3" json:"base_ejection_secs,omitempty"`
+	// The maximum percentage of the pool's backends that can be ejected at a time,
+	// rounded down. Defaults to 50.
+	MaxEjectionPercent int32 `protobuf:"varint,5,opt,name=max_ejection_percent,json=maxEjectionPercent,proto3" json:"max_ejection_percent,omitempty"`
+}
+
+func (x *OutlierDetection) Reset() {
+	*x = OutlierDetection{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -628,13 +779,13 @@ func (x *AddPoolReq) Reset() {
 	}
 }
 
-func (x *AddPoolReq) String() string {
+func (x *OutlierDetection) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddPoolReq) ProtoMessage() {}
+func (*OutlierDetection) ProtoMessage() {}
 
-func (x *AddPoolReq) ProtoReflect() protoreflect.Message {
+func (x *OutlierDetection) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -646,41 +797,67 @@ func (x *AddPoolReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddPoolReq.ProtoReflect.Descriptor instead.
-func (*AddPoolReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use OutlierDetection.ProtoReflect.Descriptor instead.
+func (*OutlierDetection) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *AddPoolReq) GetPattern() string {
+func (x *OutlierDetection) GetErrorRate() float64 {
 	if x != nil {
-		return x.Pattern
+		return x.ErrorRate
 	}
-	return ""
+	return 0
 }
 
-func (x *AddPoolReq) GetPoolType() PoolType {
+func (x *OutlierDetection) GetMinRequests() int32 {
 	if x != nil {
-		return x.PoolType
+		return x.MinRequests
 	}
-	return PoolType_PT_UNKNOWN
+	return 0
 }
 
-func (x *AddPoolReq) GetHealthChecks() *HealthChecks {
+func (x *OutlierDetection) GetIntervalSecs() int32 {
 	if x != nil {
-		return x.HealthChecks
+		return x.IntervalSecs
 	}
-	return nil
+	return 0
 }
 
-// AddPoolResp is the response to adding a pool.
-type AddPoolResp struct {
+func (x *OutlierDetection) GetBaseEjectionSecs() int32 {
+	if x != nil {
+		return x.BaseEjectionSecs
+	}
+	return 0
+}
+
+func (x *OutlierDetection) GetMaxEjectionPercent() int32 {
+	if x != nil {
+		return x.MaxEjectionPercent
+	}
+	return 0
+}
+
+// RetryPolicy retries requests that a backend failed on a different backend.
+// Only requests with idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT and DELETE)
+// are retried. A try fails if the backend can't be reached, the try times out or the
+// backend returns a 502, 503 or 504.
+type RetryPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// The most times a request is retried. Must be at least 1.
+	MaxRetries int32 `protobuf:"varint,1,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
+	// How long each try can take. If 0, tries only end when the request does.
+	PerTryTimeoutMs int32 `protobuf:"varint,2,opt,name=per_try_timeout_ms,json=perTryTimeoutMs,proto3" json:"per_try_timeout_ms,omitempty"`
+	// The number of retries allowed for each request the pool receives, between 0
+	// and 1. This keeps retries from multiplying the load on failing backends.
+	// Defaults to 0.2.
+	Budget float64 `protobuf:"fixed64,3,opt,name=budget,proto3" json:"budget,omitempty"`
 }
 
-func (x *AddPoolResp) Reset() {
-	*x = AddPoolResp{}
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -688,13 +865,13 @@ func (x *AddPoolResp) Reset() {
 	}
 }
 
-func (x *AddPoolResp) String() string {
+func (x *RetryPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddPoolResp) ProtoMessage() {}
+func (*RetryPolicy) ProtoMessage() {}
 
-func (x *AddPoolResp) ProtoReflect() protoreflect.Message {
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -706,23 +883,52 @@ func (x *AddPoolResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddPoolResp.ProtoReflect.Descriptor instead.
-func (*AddPoolResp) Descriptor() ([]byte, []int) {
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{8}
 }
 
-// RemovePoolReq is used to remove a pool by its pattern.
-type RemovePoolReq struct {
+func (x *RetryPolicy) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetPerTryTimeoutMs() int32 {
+	if x != nil {
+		return x.PerTryTimeoutMs
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBudget() float64 {
+	if x != nil {
+		return x.Budget
+	}
+	return 0
+}
+
+// BackendTLS connects to a pool's backends with TLS, for both requests and health
+// checks. The files are paths on the load balancer.
+type BackendTLS struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Pattern is the pattern to remove.
-	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The CA certificate backend certificates are verified with. If empty, the
+	// system's CAs are used.
+	CaFile string `protobuf:"bytes,1,opt,name=ca_file,json=caFile,proto3" json:"ca_file,omitempty"`
+	// The client certificate and key sent to backends for mutual TLS. If empty,
+	// no client certificate is sent.
+	CertFile string `protobuf:"bytes,2,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`
+	KeyFile  string `protobuf:"bytes,3,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`
+	// The name backend certificates must be for. If empty, this is the backend's IP.
+	ServerName string `protobuf:"bytes,4,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
 }
 
-func (x *RemovePoolReq) Reset() {
-	*x = RemovePoolReq{}
+func (x *BackendTLS) Reset() {
+	*x = BackendTLS{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -730,13 +936,13 @@ func (x *RemovePoolReq) Reset() {
 	}
 }
 
-func (x *RemovePoolReq) String() string {
+func (x *BackendTLS) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemovePoolReq) ProtoMessage() {}
+func (*BackendTLS) ProtoMessage() {}
 
-func (x *RemovePoolReq) ProtoReflect() protoreflect.Message {
+func (x *BackendTLS) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -748,27 +954,54 @@ func (x *RemovePoolReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemovePoolReq.ProtoReflect.Descriptor instead.
-func (*RemovePoolReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use BackendTLS.ProtoReflect.Descriptor instead.
+func (*BackendTLS) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *RemovePoolReq) GetPattern() string {
+func (x *BackendTLS) GetCaFile() string {
 	if x != nil {
-		return x.Pattern
+		return x.CaFile
 	}
 	return ""
 }
 
-// RemovePoolResp is the response to removing a pool.
-type RemovePoolResp struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *BackendTLS) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
+	}
+	return ""
+}
+
+func (x *BackendTLS) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *BackendTLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+// HashKey is the part of a request that a PT_CONSISTENT_HASH pool hashes. If a
+// request does not have the key, the client's IP is hashed instead.
+type HashKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Key:
+	//	*HashKey_Header
+	//	*HashKey_Cookie
+	Key isHashKey_Key `protobuf_oneof:"key"`
 }
 
-func (x *RemovePoolResp) Reset() {
-	*x = RemovePoolResp{}
+func (x *HashKey) Reset() {
+	*x = HashKey{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -776,13 +1009,13 @@ func (x *RemovePoolResp) Reset() {
 	}
 }
 
-func (x *RemovePoolResp) String() string {
+func (x *HashKey) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemovePoolResp) ProtoMessage() {}
+func (*HashKey) ProtoMessage() {}
 
-func (x *RemovePoolResp) ProtoReflect() protoreflect.Message {
+func (x *HashKey) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -794,25 +1027,62 @@ func (x *RemovePoolResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemovePoolResp.ProtoReflect.Descriptor instead.
-func (*RemovePoolResp) Descriptor() ([]byte, []int) {
+// Deprecated: Use HashKey.ProtoReflect.Descriptor instead.
+func (*HashKey) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{10}
 }
 
-// AddBackendReq adds a backend to a pool.
-type AddBackendReq struct {
+func (m *HashKey) GetKey() isHashKey_Key {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (x *HashKey) GetHeader() string {
+	if x, ok := x.GetKey().(*HashKey_Header); ok {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *HashKey) GetCookie() string {
+	if x, ok := x.GetKey().(*HashKey_Cookie); ok {
+		return x.Cookie
+	}
+	return ""
+}
+
+type isHashKey_Key interface {
+	isHashKey_Key()
+}
+
+type HashKey_Header struct {
+	// The name of a header whose value is hashed.
+	Header string `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type HashKey_Cookie struct {
+	// The name of a cookie whose value is hashed.
+	Cookie string `protobuf:"bytes,2,opt,name=cookie,proto3,oneof"`
+}
+
+func (*HashKey_Header) isHashKey_Key() {}
+
+func (*HashKey_Cookie) isHashKey_Key() {}
+
+type Backend struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The pattern to add a backend to.
-	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	// The backend to add to the pool.
-	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// Types that are assignable to Backend:
+	//	*Backend_IpBackend
+	Backend isBackend_Backend `protobuf_oneof:"backend"`
 }
 
-func (x *AddBackendReq) Reset() {
-	*x = AddBackendReq{}
+func (x *Backend) Reset() {
+	*x = Backend{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -820,13 +1090,13 @@ func (x *AddBackendReq) Reset() {
 	}
 }
 
-func (x *AddBackendReq) String() string {
+func (x *Backend) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddBackendReq) ProtoMessage() {}
+func (*Backend) ProtoMessage() {}
 
-func (x *AddBackendReq) ProtoReflect() protoreflect.Message {
+func (x *Backend) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -838,33 +1108,51 @@ func (x *AddBackendReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddBackendReq.ProtoReflect.Descriptor instead.
-func (*AddBackendReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
+func (*Backend) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *AddBackendReq) GetPattern() string {
-	if x != nil {
-		return x.Pattern
+func (m *Backend) GetBackend() isBackend_Backend {
+	if m != nil {
+		return m.Backend
 	}
-	return ""
+	return nil
 }
 
-func (x *AddBackendReq) GetBackend() *Backend {
-	if x != nil {
-		return x.Backend
+func (x *Backend) GetIpBackend() *IPBackend {
+	if x, ok := x.GetBackend().(*Backend_IpBackend); ok {
+		return x.IpBackend
 	}
 	return nil
 }
 
-type AddBackendResp struct {
+type isBackend_Backend interface {
+	isBackend_Backend()
+}
+
+type Backend_IpBackend struct {
+	IpBackend *IPBackend `protobuf:"bytes,1,opt,name=ip_backend,json=ipBackend,proto3,oneof"`
+}
+
+func (*Backend_IpBackend) isBackend_Backend() {}
+
+// IPBackend provides a Backend that has its endpoint as an ip:port.
+type IPBackend struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// The IP(v4 or v6) to use as the host.
+	Ip string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// The port number to connect on.
+	Port int32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	// The url_path to forward to. Generally this is empty.
+	UrlPath string `protobuf:"bytes,3,opt,name=url_path,json=urlPath,proto3" json:"url_path,omitempty"`
 }
 
-func (x *AddBackendResp) Reset() {
-	*x = AddBackendResp{}
+func (x *IPBackend) Reset() {
+	*x = IPBackend{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -872,13 +1160,13 @@ func (x *AddBackendResp) Reset() {
 	}
 }
 
-func (x *AddBackendResp) String() string {
+func (x *IPBackend) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddBackendResp) ProtoMessage() {}
+func (*IPBackend) ProtoMessage() {}
 
-func (x *AddBackendResp) ProtoReflect() protoreflect.Message {
+func (x *IPBackend) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -890,25 +1178,47 @@ func (x *AddBackendResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddBackendResp.ProtoReflect.Descriptor instead.
-func (*AddBackendResp) Descriptor() ([]byte, []int) {
+// Deprecated: Use IPBackend.ProtoReflect.Descriptor instead.
+func (*IPBackend) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{12}
 }
 
-// RemoveBackendReq is used to remove a Backend from a Pool.
-type RemoveBackendReq struct {
+func (x *IPBackend) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *IPBackend) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *IPBackend) GetUrlPath() string {
+	if x != nil {
+		return x.UrlPath
+	}
+	return ""
+}
+
+// PoolHealth details the health status of a pool.
+type PoolHealth struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The pool pattern to remove the backend from.
-	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	// The backend to remove from the pool.
-	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The status of the pool.
+	Status PoolStatus `protobuf:"varint,1,opt,name=status,proto3,enum=rollout.lb.PoolStatus" json:"status,omitempty"`
+	// The pool's backend health status. This can contain all backends
+	// or only sick/healthy backends, depending on the request.
+	Backends []*BackendHealth `protobuf:"bytes,2,rep,name=backends,proto3" json:"backends,omitempty"`
 }
 
-func (x *RemoveBackendReq) Reset() {
-	*x = RemoveBackendReq{}
+func (x *PoolHealth) Reset() {
+	*x = PoolHealth{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -916,13 +1226,13 @@ func (x *RemoveBackendReq) Reset() {
 	}
 }
 
-func (x *RemoveBackendReq) String() string {
+func (x *PoolHealth) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveBackendReq) ProtoMessage() {}
+func (*PoolHealth) ProtoMessage() {}
 
-func (x *RemoveBackendReq) ProtoReflect() protoreflect.Message {
+func (x *PoolHealth) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -934,34 +1244,42 @@ func (x *RemoveBackendReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveBackendReq.ProtoReflect.Descriptor instead.
-func (*RemoveBackendReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use PoolHealth.ProtoReflect.Descriptor instead.
+func (*PoolHealth) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *RemoveBackendReq) GetPattern() string {
+func (x *PoolHealth) GetStatus() PoolStatus {
 	if x != nil {
-		return x.Pattern
+		return x.Status
 	}
-	return ""
+	return PoolStatus_PS_UNKNOWN
 }
 
-func (x *RemoveBackendReq) GetBackend() *Backend {
+func (x *PoolHealth) GetBackends() []*BackendHealth {
 	if x != nil {
-		return x.Backend
+		return x.Backends
 	}
 	return nil
 }
 
-// RemoveBackendResp is the response to removing a Backend.
-type RemoveBackendResp struct {
+// BackendHealth details the health status of a backend.
+type BackendHealth struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Backend *Backend      `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	Status  BackendStatus `protobuf:"varint,2,opt,name=status,proto3,enum=rollout.lb.BackendStatus" json:"status,omitempty"`
+	// The weight of the backend. Only set for PT_WEIGHTED pools.
+	Weight int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Why a BS_SICK backend is out of service, such as failing its health checks or
+	// being ejected by outlier detection.
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
 }
 
-func (x *RemoveBackendResp) Reset() {
-	*x = RemoveBackendResp{}
+func (x *BackendHealth) Reset() {
+	*x = BackendHealth{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -969,13 +1287,13 @@ func (x *RemoveBackendResp) Reset() {
 	}
 }
 
-func (x *RemoveBackendResp) String() string {
+func (x *BackendHealth) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveBackendResp) ProtoMessage() {}
+func (*BackendHealth) ProtoMessage() {}
 
-func (x *RemoveBackendResp) ProtoReflect() protoreflect.Message {
+func (x *BackendHealth) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -987,27 +1305,66 @@ func (x *RemoveBackendResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveBackendResp.ProtoReflect.Descriptor instead.
-func (*RemoveBackendResp) Descriptor() ([]byte, []int) {
+// Deprecated: Use BackendHealth.ProtoReflect.Descriptor instead.
+func (*BackendHealth) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{14}
 }
 
-// PoolHealthReq is a request to get the health of a pool.
-type PoolHealthReq struct {
+func (x *BackendHealth) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *BackendHealth) GetStatus() BackendStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BackendStatus_BS_UNKNOWN
+}
+
+func (x *BackendHealth) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *BackendHealth) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// AddPoolReq requests to create a pool for handling requests.
+type AddPoolReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Pattern is the pool pattern you are getting health for.
+	// The URL pattern to direct traffic too.
 	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	// If set to true, will return the backends that are healthy.
-	Healthy bool `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	// If set to true, will return backends that are sick.
-	Sick bool `protobuf:"varint,4,opt,name=sick,proto3" json:"sick,omitempty"`
+	// The type of traffic distribution pool to use.
+	PoolType PoolType `protobuf:"varint,2,opt,name=pool_type,json=poolType,proto3,enum=rollout.lb.PoolType" json:"pool_type,omitempty"`
+	// Health checks to against backends.
+	HealthChecks *HealthChecks `protobuf:"bytes,4,opt,name=health_checks,json=healthChecks,proto3" json:"health_checks,omitempty"`
+	// What to hash to choose a backend. Required for PT_CONSISTENT_HASH pools.
+	HashKey *HashKey `protobuf:"bytes,5,opt,name=hash_key,json=hashKey,proto3" json:"hash_key,omitempty"`
+	// If set, backends with too many errors in their live traffic are ejected.
+	OutlierDetection *OutlierDetection `protobuf:"bytes,6,opt,name=outlier_detection,json=outlierDetection,proto3" json:"outlier_detection,omitempty"`
+	// If set, the pool is not registered at pattern and only receives requests
+	// from Routes. The pattern is then only the pool's name.
+	RouteOnly bool `protobuf:"varint,7,opt,name=route_only,json=routeOnly,proto3" json:"route_only,omitempty"`
+	// If set, failed requests are retried on other backends.
+	RetryPolicy *RetryPolicy `protobuf:"bytes,8,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	// If set, backends are connected to with TLS.
+	BackendTls *BackendTLS `protobuf:"bytes,9,opt,name=backend_tls,json=backendTls,proto3" json:"backend_tls,omitempty"`
 }
 
-func (x *PoolHealthReq) Reset() {
-	*x = PoolHealthReq{}
+func (x *AddPoolReq) Reset() {
+	*x = AddPoolReq{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_lb_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1015,13 +1372,13 @@ func (x *PoolHealthReq) Reset() {
 	}
 }
 
-func (x *PoolHealthReq) String() string {
+func (x *AddPoolReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolHealthReq) ProtoMessage() {}
+func (*AddPoolReq) ProtoMessage() {}
 
-func (x *PoolHealthReq) ProtoReflect() protoreflect.Message {
+func (x *AddPoolReq) ProtoReflect() protoreflect.Message {
 	mi := &file_lb_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1033,23 +1390,1516 @@ func (x *PoolHealthReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolHealthReq.ProtoReflect.Descriptor instead.
-func (*PoolHealthReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddPoolReq.ProtoReflect.Descriptor instead.
+func (*AddPoolReq) Descriptor() ([]byte, []int) {
 	return file_lb_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *PoolHealthReq) GetPattern() string {
+func (x *AddPoolReq) GetPattern() string {
 	if x != nil {
 		return x.Pattern
 	}
 	return ""
 }
 
-func (x *PoolHealthReq) GetHealthy() bool {
+func (x *AddPoolReq) GetPoolType() PoolType {
 	if x != nil {
-		return x.Healthy
+		return x.PoolType
 	}
-	return false
+	return PoolType_PT_UNKNOWN
+}
+
+func (x *AddPoolReq) GetHealthChecks() *HealthChecks {
+	if x != nil {
+		return x.HealthChecks
+	}
+	return nil
+}
+
+func (x *AddPoolReq) GetHashKey() *HashKey {
+	if x != nil {
+		return x.HashKey
+	}
+	return nil
+}
+
+func (x *AddPoolReq) GetOutlierDetection() *OutlierDetection {
+	if x != nil {
+		return x.OutlierDetection
+	}
+	return nil
+}
+
+func (x *AddPoolReq) GetRouteOnly() bool {
+	if x != nil {
+		return x.RouteOnly
+	}
+	return false
+}
+
+func (x *AddPoolReq) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *AddPoolReq) GetBackendTls() *BackendTLS {
+	if x != nil {
+		return x.BackendTls
+	}
+	return nil
+}
+
+// AddPoolResp is the response to adding a pool.
+type AddPoolResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddPoolResp) Reset() {
+	*x = AddPoolResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddPoolResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPoolResp) ProtoMessage() {}
+
+func (x *AddPoolResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPoolResp.ProtoReflect.Descriptor instead.
+func (*AddPoolResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{16}
+}
+
+// RemovePoolReq is used to remove a pool by its pattern.
+type RemovePoolReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Pattern is the pattern to remove.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+}
+
+func (x *RemovePoolReq) Reset() {
+	*x = RemovePoolReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemovePoolReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePoolReq) ProtoMessage() {}
+
+func (x *RemovePoolReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePoolReq.ProtoReflect.Descriptor instead.
+func (*RemovePoolReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RemovePoolReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+// RemovePoolResp is the response to removing a pool.
+type RemovePoolResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemovePoolResp) Reset() {
+	*x = RemovePoolResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemovePoolResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePoolResp) ProtoMessage() {}
+
+func (x *RemovePoolResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePoolResp.ProtoReflect.Descriptor instead.
+func (*RemovePoolResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{18}
+}
+
+// AddBackendReq adds a backend to a pool.
+type AddBackendReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pattern to add a backend to.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The backend to add to the pool.
+	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The weight of the backend. This can only be set for PT_WEIGHTED pools and
+	// defaults to 100.
+	Weight int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (x *AddBackendReq) Reset() {
+	*x = AddBackendReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBackendReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBackendReq) ProtoMessage() {}
+
+func (x *AddBackendReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBackendReq.ProtoReflect.Descriptor instead.
+func (*AddBackendReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AddBackendReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *AddBackendReq) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *AddBackendReq) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type AddBackendResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddBackendResp) Reset() {
+	*x = AddBackendResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBackendResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBackendResp) ProtoMessage() {}
+
+func (x *AddBackendResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBackendResp.ProtoReflect.Descriptor instead.
+func (*AddBackendResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{20}
+}
+
+// RemoveBackendReq is used to remove a Backend from a Pool.
+type RemoveBackendReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pool pattern to remove the backend from.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The backend to remove from the pool.
+	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+}
+
+func (x *RemoveBackendReq) Reset() {
+	*x = RemoveBackendReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveBackendReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBackendReq) ProtoMessage() {}
+
+func (x *RemoveBackendReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBackendReq.ProtoReflect.Descriptor instead.
+func (*RemoveBackendReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RemoveBackendReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *RemoveBackendReq) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+// RemoveBackendResp is the response to removing a Backend.
+type RemoveBackendResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveBackendResp) Reset() {
+	*x = RemoveBackendResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveBackendResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBackendResp) ProtoMessage() {}
+
+func (x *RemoveBackendResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBackendResp.ProtoReflect.Descriptor instead.
+func (*RemoveBackendResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{22}
+}
+
+// SetBackendWeightReq changes the weight of a Backend in a PT_WEIGHTED pool.
+type SetBackendWeightReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pool pattern the backend is in.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The backend to change the weight of.
+	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The new weight. Must be at least 1.
+	Weight int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (x *SetBackendWeightReq) Reset() {
+	*x = SetBackendWeightReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetBackendWeightReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBackendWeightReq) ProtoMessage() {}
+
+func (x *SetBackendWeightReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBackendWeightReq.ProtoReflect.Descriptor instead.
+func (*SetBackendWeightReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetBackendWeightReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *SetBackendWeightReq) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *SetBackendWeightReq) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// SetBackendWeightResp is the response to setting a Backend's weight.
+type SetBackendWeightResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetBackendWeightResp) Reset() {
+	*x = SetBackendWeightResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetBackendWeightResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBackendWeightResp) ProtoMessage() {}
+
+func (x *SetBackendWeightResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBackendWeightResp.ProtoReflect.Descriptor instead.
+func (*SetBackendWeightResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{24}
+}
+
+// DrainBackendReq is used to stop sending new requests to a Backend and wait
+// for its in-flight requests to finish.
+type DrainBackendReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pool pattern the backend is in.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The backend to drain.
+	Backend *Backend `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The maximum time to wait for in-flight requests to finish. Defaults to 30.
+	TimeoutSecs int32 `protobuf:"varint,3,opt,name=timeout_secs,json=timeoutSecs,proto3" json:"timeout_secs,omitempty"`
+}
+
+func (x *DrainBackendReq) Reset() {
+	*x = DrainBackendReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrainBackendReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainBackendReq) ProtoMessage() {}
+
+func (x *DrainBackendReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainBackendReq.ProtoReflect.Descriptor instead.
+func (*DrainBackendReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DrainBackendReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *DrainBackendReq) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *DrainBackendReq) GetTimeoutSecs() int32 {
+	if x != nil {
+		return x.TimeoutSecs
+	}
+	return 0
+}
+
+// DrainBackendResp is the response to draining a Backend.
+type DrainBackendResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The number of requests still in-flight when the timeout passed. If 0, the
+	// backend is fully drained.
+	InFlight int32 `protobuf:"varint,1,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+}
+
+func (x *DrainBackendResp) Reset() {
+	*x = DrainBackendResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrainBackendResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainBackendResp) ProtoMessage() {}
+
+func (x *DrainBackendResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainBackendResp.ProtoReflect.Descriptor instead.
+func (*DrainBackendResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DrainBackendResp) GetInFlight() int32 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+// PoolConfig is the configuration of a pool and its backends.
+type PoolConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The URL pattern the pool serves.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The type of traffic distribution pool to use.
+	PoolType PoolType `protobuf:"varint,2,opt,name=pool_type,json=poolType,proto3,enum=rollout.lb.PoolType" json:"pool_type,omitempty"`
+	// Health checks to run against backends.
+	HealthChecks *HealthChecks `protobuf:"bytes,3,opt,name=health_checks,json=healthChecks,proto3" json:"health_checks,omitempty"`
+	// What to hash to choose a backend. Only for PT_CONSISTENT_HASH pools.
+	HashKey *HashKey `protobuf:"bytes,4,opt,name=hash_key,json=hashKey,proto3" json:"hash_key,omitempty"`
+	// The backends in the pool.
+	Backends []*BackendConfig `protobuf:"bytes,5,rep,name=backends,proto3" json:"backends,omitempty"`
+	// If set, backends with too many errors in their live traffic are ejected.
+	OutlierDetection *OutlierDetection `protobuf:"bytes,6,opt,name=outlier_detection,json=outlierDetection,proto3" json:"outlier_detection,omitempty"`
+	// If set, the pool only receives requests from Routes.
+	RouteOnly bool `protobuf:"varint,7,opt,name=route_only,json=routeOnly,proto3" json:"route_only,omitempty"`
+	// If set, failed requests are retried on other backends.
+	RetryPolicy *RetryPolicy `protobuf:"bytes,8,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	// If set, backends are connected to with TLS.
+	BackendTls *BackendTLS `protobuf:"bytes,9,opt,name=backend_tls,json=backendTls,proto3" json:"backend_tls,omitempty"`
+}
+
+func (x *PoolConfig) Reset() {
+	*x = PoolConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PoolConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolConfig) ProtoMessage() {}
+
+func (x *PoolConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolConfig.ProtoReflect.Descriptor instead.
+func (*PoolConfig) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PoolConfig) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PoolConfig) GetPoolType() PoolType {
+	if x != nil {
+		return x.PoolType
+	}
+	return PoolType_PT_UNKNOWN
+}
+
+func (x *PoolConfig) GetHealthChecks() *HealthChecks {
+	if x != nil {
+		return x.HealthChecks
+	}
+	return nil
+}
+
+func (x *PoolConfig) GetHashKey() *HashKey {
+	if x != nil {
+		return x.HashKey
+	}
+	return nil
+}
+
+func (x *PoolConfig) GetBackends() []*BackendConfig {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *PoolConfig) GetOutlierDetection() *OutlierDetection {
+	if x != nil {
+		return x.OutlierDetection
+	}
+	return nil
+}
+
+func (x *PoolConfig) GetRouteOnly() bool {
+	if x != nil {
+		return x.RouteOnly
+	}
+	return false
+}
+
+func (x *PoolConfig) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *PoolConfig) GetBackendTls() *BackendTLS {
+	if x != nil {
+		return x.BackendTls
+	}
+	return nil
+}
+
+// BackendConfig is the configuration of a backend in a pool.
+type BackendConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Backend *Backend `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The weight of the backend. Only for PT_WEIGHTED pools.
+	Weight int32 `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (x *BackendConfig) Reset() {
+	*x = BackendConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendConfig) ProtoMessage() {}
+
+func (x *BackendConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendConfig.ProtoReflect.Descriptor instead.
+func (*BackendConfig) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *BackendConfig) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *BackendConfig) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// LBConfig is the configuration of all the pools and routes in the load balancer.
+type LBConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pools  []*PoolConfig `protobuf:"bytes,1,rep,name=pools,proto3" json:"pools,omitempty"`
+	Routes []*Route      `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes,omitempty"`
+}
+
+func (x *LBConfig) Reset() {
+	*x = LBConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LBConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LBConfig) ProtoMessage() {}
+
+func (x *LBConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LBConfig.ProtoReflect.Descriptor instead.
+func (*LBConfig) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LBConfig) GetPools() []*PoolConfig {
+	if x != nil {
+		return x.Pools
+	}
+	return nil
+}
+
+func (x *LBConfig) GetRoutes() []*Route {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+// ExportConfigReq is a request for the load balancer's current configuration.
+type ExportConfigReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ExportConfigReq) Reset() {
+	*x = ExportConfigReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportConfigReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConfigReq) ProtoMessage() {}
+
+func (x *ExportConfigReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConfigReq.ProtoReflect.Descriptor instead.
+func (*ExportConfigReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{30}
+}
+
+// ExportConfigResp is the response to exporting the configuration. Backends
+// that are draining are not included.
+type ExportConfigResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config *LBConfig `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *ExportConfigResp) Reset() {
+	*x = ExportConfigResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportConfigResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportConfigResp) ProtoMessage() {}
+
+func (x *ExportConfigResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportConfigResp.ProtoReflect.Descriptor instead.
+func (*ExportConfigResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ExportConfigResp) GetConfig() *LBConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// ApplyConfigReq replaces all the pools in the load balancer with the ones in config.
+// Either all of config is applied or none of it is.
+type ApplyConfigReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config *LBConfig `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *ApplyConfigReq) Reset() {
+	*x = ApplyConfigReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyConfigReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyConfigReq) ProtoMessage() {}
+
+func (x *ApplyConfigReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyConfigReq.ProtoReflect.Descriptor instead.
+func (*ApplyConfigReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ApplyConfigReq) GetConfig() *LBConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// ApplyConfigResp is the response to applying a configuration.
+type ApplyConfigResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ApplyConfigResp) Reset() {
+	*x = ApplyConfigResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyConfigResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyConfigResp) ProtoMessage() {}
+
+func (x *ApplyConfigResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyConfigResp.ProtoReflect.Descriptor instead.
+func (*ApplyConfigResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{33}
+}
+
+// Route sends requests that match it to a pool. A request matches if it matches
+// every field that is set. Routes are checked before the patterns pools are
+// registered at, highest priority first. Requests that match no route are sent
+// to the pool whose pattern matches.
+type Route struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The unique name of the route.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Routes with a higher priority are checked first. Routes with the same
+	// priority are checked in order of name.
+	Priority int32 `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	// The request's host, without the port. "*.example.com" matches any subdomain
+	// of example.com. This is not case sensitive.
+	Host string `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	// Matches requests whose URL path starts with this.
+	PathPrefix string `protobuf:"bytes,4,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	// Matches requests that have all of these headers.
+	Headers []*HeaderMatch `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty"`
+	// Matches requests that use any of these methods, such as "GET".
+	Methods []string `protobuf:"bytes,6,rep,name=methods,proto3" json:"methods,omitempty"`
+	// The pattern of the pool to send matching requests to.
+	Pool string `protobuf:"bytes,7,opt,name=pool,proto3" json:"pool,omitempty"`
+}
+
+func (x *Route) Reset() {
+	*x = Route{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Route) ProtoMessage() {}
+
+func (x *Route) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Route.ProtoReflect.Descriptor instead.
+func (*Route) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *Route) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Route) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Route) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Route) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *Route) GetHeaders() []*HeaderMatch {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Route) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *Route) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+// HeaderMatch matches a request header.
+type HeaderMatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the header.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The header's value must equal this. If empty, the header only needs to be present.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *HeaderMatch) Reset() {
+	*x = HeaderMatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HeaderMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderMatch) ProtoMessage() {}
+
+func (x *HeaderMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderMatch.ProtoReflect.Descriptor instead.
+func (*HeaderMatch) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *HeaderMatch) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HeaderMatch) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// AddRouteReq adds a route to the load balancer.
+type AddRouteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Route *Route `protobuf:"bytes,1,opt,name=route,proto3" json:"route,omitempty"`
+}
+
+func (x *AddRouteReq) Reset() {
+	*x = AddRouteReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddRouteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRouteReq) ProtoMessage() {}
+
+func (x *AddRouteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRouteReq.ProtoReflect.Descriptor instead.
+func (*AddRouteReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *AddRouteReq) GetRoute() *Route {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+// AddRouteResp is the response to adding a route.
+type AddRouteResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddRouteResp) Reset() {
+	*x = AddRouteResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddRouteResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRouteResp) ProtoMessage() {}
+
+func (x *AddRouteResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRouteResp.ProtoReflect.Descriptor instead.
+func (*AddRouteResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{37}
+}
+
+// RemoveRouteReq removes a route by its name.
+type RemoveRouteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *RemoveRouteReq) Reset() {
+	*x = RemoveRouteReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveRouteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRouteReq) ProtoMessage() {}
+
+func (x *RemoveRouteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRouteReq.ProtoReflect.Descriptor instead.
+func (*RemoveRouteReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RemoveRouteReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// RemoveRouteResp is the response to removing a route.
+type RemoveRouteResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveRouteResp) Reset() {
+	*x = RemoveRouteResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveRouteResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRouteResp) ProtoMessage() {}
+
+func (x *RemoveRouteResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRouteResp.ProtoReflect.Descriptor instead.
+func (*RemoveRouteResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{39}
+}
+
+// ListRoutesReq is a request for all the routes in the load balancer.
+type ListRoutesReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListRoutesReq) Reset() {
+	*x = ListRoutesReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRoutesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRoutesReq) ProtoMessage() {}
+
+func (x *ListRoutesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRoutesReq.ProtoReflect.Descriptor instead.
+func (*ListRoutesReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{40}
+}
+
+// ListRoutesResp lists the routes in the order they are checked.
+type ListRoutesResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Routes []*Route `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+}
+
+func (x *ListRoutesResp) Reset() {
+	*x = ListRoutesResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRoutesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRoutesResp) ProtoMessage() {}
+
+func (x *ListRoutesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRoutesResp.ProtoReflect.Descriptor instead.
+func (*ListRoutesResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListRoutesResp) GetRoutes() []*Route {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+// PoolHealthReq is a request to get the health of a pool.
+type PoolHealthReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Pattern is the pool pattern you are getting health for.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// If set to true, will return the backends that are healthy.
+	Healthy bool `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// If set to true, will return backends that are sick.
+	Sick bool `protobuf:"varint,4,opt,name=sick,proto3" json:"sick,omitempty"`
+}
+
+func (x *PoolHealthReq) Reset() {
+	*x = PoolHealthReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PoolHealthReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolHealthReq) ProtoMessage() {}
+
+func (x *PoolHealthReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolHealthReq.ProtoReflect.Descriptor instead.
+func (*PoolHealthReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *PoolHealthReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PoolHealthReq) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
 }
 
 func (x *PoolHealthReq) GetSick() bool {
@@ -1070,7 +2920,7 @@ type PoolHealthResp struct {
 func (x *PoolHealthResp) Reset() {
 	*x = PoolHealthResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_lb_proto_msgTypes[16]
+		mi := &file_lb_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1083,7 +2933,7 @@ func (x *PoolHealthResp) String() string {
 func (*PoolHealthResp) ProtoMessage() {}
 
 func (x *PoolHealthResp) ProtoReflect() protoreflect.Message {
-	mi := &file_lb_proto_msgTypes[16]
+	mi := &file_lb_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1096,7 +2946,7 @@ func (x *PoolHealthResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PoolHealthResp.ProtoReflect.Descriptor instead.
 func (*PoolHealthResp) Descriptor() ([]byte, []int) {
-	return file_lb_proto_rawDescGZIP(), []int{16}
+	return file_lb_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *PoolHealthResp) GetHealth() *PoolHealth {
@@ -1106,131 +2956,624 @@ func (x *PoolHealthResp) GetHealth() *PoolHealth {
 	return nil
 }
 
+// WatchPoolHealthReq is a request to watch the health of a pool.
+type WatchPoolHealthReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pattern of the pool to watch.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+}
+
+func (x *WatchPoolHealthReq) Reset() {
+	*x = WatchPoolHealthReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchPoolHealthReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPoolHealthReq) ProtoMessage() {}
+
+func (x *WatchPoolHealthReq) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPoolHealthReq.ProtoReflect.Descriptor instead.
+func (*WatchPoolHealthReq) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *WatchPoolHealthReq) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+// BackendEvent is a change to a backend in a pool.
+type BackendEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The pattern of the pool the backend is in.
+	Pattern string           `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Backend *Backend         `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	Type    BackendEventType `protobuf:"varint,3,opt,name=type,proto3,enum=rollout.lb.BackendEventType" json:"type,omitempty"`
+	// The status of the backend after the change. Not set for BET_REMOVED.
+	Status BackendStatus `protobuf:"varint,4,opt,name=status,proto3,enum=rollout.lb.BackendStatus" json:"status,omitempty"`
+	// Why the backend became BS_SICK.
+	Reason string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// When the change happened, in nanoseconds since the Unix epoch.
+	TimeUnixNano int64 `protobuf:"varint,6,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+}
+
+func (x *BackendEvent) Reset() {
+	*x = BackendEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendEvent) ProtoMessage() {}
+
+func (x *BackendEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendEvent.ProtoReflect.Descriptor instead.
+func (*BackendEvent) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *BackendEvent) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *BackendEvent) GetBackend() *Backend {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *BackendEvent) GetType() BackendEventType {
+	if x != nil {
+		return x.Type
+	}
+	return BackendEventType_BET_UNKNOWN
+}
+
+func (x *BackendEvent) GetStatus() BackendStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BackendStatus_BS_UNKNOWN
+}
+
+func (x *BackendEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BackendEvent) GetTimeUnixNano() int64 {
+	if x != nil {
+		return x.TimeUnixNano
+	}
+	return 0
+}
+
+// WatchPoolHealthResp is a message in the WatchPoolHealth stream. The first
+// message has the pool's health, with all its backends. Every message after
+// that has an event.
+type WatchPoolHealthResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Msg:
+	//	*WatchPoolHealthResp_Health
+	//	*WatchPoolHealthResp_Event
+	Msg isWatchPoolHealthResp_Msg `protobuf_oneof:"msg"`
+}
+
+func (x *WatchPoolHealthResp) Reset() {
+	*x = WatchPoolHealthResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lb_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchPoolHealthResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPoolHealthResp) ProtoMessage() {}
+
+func (x *WatchPoolHealthResp) ProtoReflect() protoreflect.Message {
+	mi := &file_lb_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPoolHealthResp.ProtoReflect.Descriptor instead.
+func (*WatchPoolHealthResp) Descriptor() ([]byte, []int) {
+	return file_lb_proto_rawDescGZIP(), []int{46}
+}
+
+func (m *WatchPoolHealthResp) GetMsg() isWatchPoolHealthResp_Msg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (x *WatchPoolHealthResp) GetHealth() *PoolHealth {
+	if x, ok := x.GetMsg().(*WatchPoolHealthResp_Health); ok {
+		return x.Health
+	}
+	return nil
+}
+
+func (x *WatchPoolHealthResp) GetEvent() *BackendEvent {
+	if x, ok := x.GetMsg().(*WatchPoolHealthResp_Event); ok {
+		return x.Event
+	}
+	return nil
+}
+
+type isWatchPoolHealthResp_Msg interface {
+	isWatchPoolHealthResp_Msg()
+}
+
+type WatchPoolHealthResp_Health struct {
+	Health *PoolHealth `protobuf:"bytes,1,opt,name=health,proto3,oneof"`
+}
+
+type WatchPoolHealthResp_Event struct {
+	Event *BackendEvent `protobuf:"bytes,2,opt,name=event,proto3,oneof"`
+}
+
+func (*WatchPoolHealthResp_Health) isWatchPoolHealthResp_Msg() {}
+
+func (*WatchPoolHealthResp_Event) isWatchPoolHealthResp_Msg() {}
+
 var File_lb_proto protoreflect.FileDescriptor
 
 var file_lb_proto_rawDesc = []byte{
 	0x0a, 0x08, 0x6c, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x72, 0x6f, 0x6c, 0x6c,
-	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x22, 0x71, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x12, 0x3c, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e,
-	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68,
-	0x65, 0x63, 0x6b, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
-	0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x69, 0x6e, 0x74,
-	0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x73, 0x22, 0x5b, 0x0a, 0x0b, 0x48, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x3c, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
-	0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x0e, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x22, 0x4f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61, 0x74,
-	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75, 0x72, 0x6c, 0x50, 0x61, 0x74, 0x68,
-	0x12, 0x25, 0x0a, 0x0e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x5f, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x4c, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x12, 0x36, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
-	0x2e, 0x6c, 0x62, 0x2e, 0x49, 0x50, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x48, 0x00, 0x52,
-	0x09, 0x69, 0x70, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x62, 0x61,
-	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x4a, 0x0a, 0x09, 0x49, 0x50, 0x42, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61,
-	0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75, 0x72, 0x6c, 0x50, 0x61, 0x74,
-	0x68, 0x22, 0x73, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12,
-	0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f,
-	0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
-	0x35, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x08, 0x62, 0x61,
-	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x22, 0x71, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
-	0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
-	0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x98, 0x01, 0x0a, 0x0a, 0x41, 0x64,
-	0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74,
-	0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
-	0x72, 0x6e, 0x12, 0x31, 0x0a, 0x09, 0x70, 0x6f, 0x6f, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
-	0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x6f, 0x6f,
-	0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3d, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f,
-	0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72,
-	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68,
-	0x65, 0x63, 0x6b, 0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52,
-	0x65, 0x73, 0x70, 0x22, 0x29, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f,
-	0x6c, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x22, 0x10,
-	0x0a, 0x0e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70,
-	0x22, 0x58, 0x0a, 0x0d, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65,
-	0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72,
-	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x10, 0x0a, 0x0e, 0x41, 0x64,
-	0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22, 0x5b, 0x0a, 0x10,
-	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71,
-	0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61,
-	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f,
-	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x13, 0x0a, 0x11, 0x52, 0x65, 0x6d,
-	0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22, 0x57,
-	0x0a, 0x0d, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x12,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x22, 0x99, 0x01, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x6c, 0x74,
+	0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x12, 0x3c, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74,
+	0x68, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x69,
+	0x73, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x69, 0x73, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x66, 0x61, 0x6c, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x66, 0x61,
+	0x6c, 0x6c, 0x22, 0xce, 0x02, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x3c, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x12, 0x33, 0x0a, 0x09, 0x74, 0x63, 0x70, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62,
+	0x2e, 0x54, 0x43, 0x50, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x08, 0x74, 0x63, 0x70,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x36, 0x0a, 0x0a, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x47, 0x52, 0x50, 0x43, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x48, 0x00, 0x52, 0x09, 0x67, 0x72, 0x70, 0x63, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x43, 0x0a,
+	0x0f, 0x68, 0x74, 0x74, 0x70, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x54, 0x54, 0x50, 0x43, 0x6f, 0x64, 0x65, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x48, 0x00, 0x52, 0x0d, 0x68, 0x74, 0x74, 0x70, 0x43, 0x6f, 0x64, 0x65, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x3f, 0x0a, 0x0d, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0c, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x42, 0x0e, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x22, 0x4f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75, 0x72, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x12, 0x25, 0x0a,
+	0x0e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x22, 0x0a, 0x0a, 0x08, 0x54, 0x43, 0x50, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x22, 0x39, 0x0a, 0x09, 0x47, 0x52, 0x50, 0x43, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x22, 0x60, 0x0a, 0x0d, 0x48,
+	0x54, 0x54, 0x50, 0x43, 0x6f, 0x64, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08,
+	0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x75, 0x72, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x69, 0x6e, 0x5f, 0x63,
+	0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x69, 0x6e, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x4f, 0x0a,
+	0x0c, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x19, 0x0a,
+	0x08, 0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x75, 0x72, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0c, 0x6d, 0x61, 0x78, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x22, 0xd9,
+	0x01, 0x0a, 0x10, 0x4f, 0x75, 0x74, 0x6c, 0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x61,
+	0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x62, 0x61,
+	0x73, 0x65, 0x5f, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x63, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x62, 0x61, 0x73, 0x65, 0x45, 0x6a, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f,
+	0x65, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x6d, 0x61, 0x78, 0x45, 0x6a, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x73, 0x0a, 0x0b, 0x52, 0x65,
+	0x74, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78,
+	0x5f, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x12, 0x70, 0x65,
+	0x72, 0x5f, 0x74, 0x72, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70, 0x65, 0x72, 0x54, 0x72, 0x79, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x22,
+	0x7e, 0x0a, 0x0a, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x54, 0x4c, 0x53, 0x12, 0x17, 0x0a,
+	0x07, 0x63, 0x61, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x63, 0x61, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x66,
+	0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x65, 0x72, 0x74, 0x46,
+	0x69, 0x6c, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22,
+	0x44, 0x0a, 0x07, 0x48, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x06, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x42, 0x05,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x4c, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x12, 0x36, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c,
+	0x62, 0x2e, 0x49, 0x50, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x48, 0x00, 0x52, 0x09, 0x69,
+	0x70, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x22, 0x4a, 0x0a, 0x09, 0x49, 0x50, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x72, 0x6c, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75, 0x72, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x22,
+	0x73, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x2e, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e,
+	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x35, 0x0a,
+	0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
+	0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xa7, 0x03, 0x0a, 0x0a, 0x41, 0x64, 0x64,
+	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x12, 0x31, 0x0a, 0x09, 0x70, 0x6f, 0x6f, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c,
+	0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x6f, 0x6f, 0x6c,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x3d, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x73, 0x12, 0x2e, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
+	0x6c, 0x62, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x52, 0x07, 0x68, 0x61, 0x73, 0x68,
+	0x4b, 0x65, 0x79, 0x12, 0x49, 0x0a, 0x11, 0x6f, 0x75, 0x74, 0x6c, 0x69, 0x65, 0x72, 0x5f, 0x64,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4f, 0x75, 0x74, 0x6c,
+	0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6f, 0x75,
+	0x74, 0x6c, 0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d,
+	0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x3a, 0x0a,
+	0x0c, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62,
+	0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x0b, 0x72, 0x65,
+	0x74, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x37, 0x0a, 0x0b, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x6c, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x54, 0x4c, 0x53, 0x52, 0x0a, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x54,
+	0x6c, 0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x22, 0x29, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52,
+	0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x22, 0x10, 0x0a, 0x0e,
+	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x22, 0x70,
+	0x0a, 0x0d, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x12,
 	0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x65, 0x61, 0x6c,
-	0x74, 0x68, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x04, 0x73, 0x69, 0x63, 0x6b, 0x22, 0x40, 0x0a, 0x0e, 0x50, 0x6f, 0x6f, 0x6c, 0x48,
-	0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2e, 0x0a, 0x06, 0x68, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
-	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x52, 0x06, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x2a, 0x26, 0x0a, 0x08, 0x50, 0x6f, 0x6f,
-	0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e,
-	0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x50, 0x54, 0x5f, 0x50, 0x32, 0x43, 0x10,
-	0x01, 0x2a, 0x48, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
-	0x0e, 0x0a, 0x0a, 0x50, 0x53, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
-	0x0b, 0x0a, 0x07, 0x50, 0x53, 0x5f, 0x46, 0x55, 0x4c, 0x4c, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08,
-	0x50, 0x53, 0x5f, 0x45, 0x4d, 0x50, 0x54, 0x59, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x50, 0x53,
-	0x5f, 0x44, 0x45, 0x47, 0x52, 0x41, 0x44, 0x45, 0x44, 0x10, 0x03, 0x2a, 0x3c, 0x0a, 0x0d, 0x42,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x0a,
-	0x42, 0x53, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a,
-	0x42, 0x53, 0x5f, 0x48, 0x45, 0x41, 0x4c, 0x54, 0x48, 0x59, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07,
-	0x42, 0x53, 0x5f, 0x53, 0x49, 0x43, 0x4b, 0x10, 0x02, 0x32, 0xf1, 0x02, 0x0a, 0x0c, 0x4c, 0x6f,
-	0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x12, 0x3c, 0x0a, 0x07, 0x41, 0x64,
-	0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
-	0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x17, 0x2e,
-	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x6f,
-	0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f,
-	0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
-	0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65,
-	0x71, 0x1a, 0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52,
-	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12,
-	0x45, 0x0a, 0x0a, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x19, 0x2e,
-	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x42, 0x61,
-	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52,
+	0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x22, 0x10, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65,
+	0x73, 0x70, 0x22, 0x5b, 0x0a, 0x10, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e,
+	0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22,
+	0x13, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x76, 0x0a, 0x13, 0x53, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x16, 0x0a, 0x14,
+	0x53, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x7d, 0x0a, 0x0f, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x63, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53,
+	0x65, 0x63, 0x73, 0x22, 0x2f, 0x0a, 0x10, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x66, 0x6c,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6e, 0x46, 0x6c,
+	0x69, 0x67, 0x68, 0x74, 0x22, 0xde, 0x03, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x31, 0x0a,
+	0x09, 0x70, 0x6f, 0x6f, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f,
+	0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x3d, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x12,
+	0x2e, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48,
+	0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x52, 0x07, 0x68, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x12,
+	0x35, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x49, 0x0a, 0x11, 0x6f, 0x75, 0x74, 0x6c, 0x69, 0x65,
+	0x72, 0x5f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4f,
+	0x75, 0x74, 0x6c, 0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x10, 0x6f, 0x75, 0x74, 0x6c, 0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x4f, 0x6e, 0x6c, 0x79,
+	0x12, 0x3a, 0x0a, 0x0c, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
+	0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x37, 0x0a, 0x0b,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x6c, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x54, 0x4c, 0x53, 0x52, 0x0a, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x54, 0x6c, 0x73, 0x22, 0x56, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x63, 0x0a,
+	0x08, 0x4c, 0x42, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2c, 0x0a, 0x05, 0x70, 0x6f, 0x6f,
+	0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x05, 0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x12, 0x29, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x73, 0x22, 0x11, 0x0a, 0x0f, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x65, 0x71, 0x22, 0x40, 0x0a, 0x10, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2c, 0x0a, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4c, 0x42, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x3e, 0x0a, 0x0e, 0x41, 0x70, 0x70, 0x6c, 0x79,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x12, 0x2c, 0x0a, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4c, 0x42, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x11, 0x0a, 0x0f, 0x41, 0x70, 0x70, 0x6c, 0x79,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x22, 0xcd, 0x01, 0x0a, 0x05, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x74, 0x68,
+	0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70,
+	0x61, 0x74, 0x68, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x31, 0x0a, 0x07, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x6f, 0x6c, 0x22, 0x37, 0x0a, 0x0b, 0x48, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0x36, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x12, 0x27, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x22, 0x0e, 0x0a, 0x0c, 0x41,
+	0x64, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x22, 0x24, 0x0a, 0x0e, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x22, 0x11, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x52, 0x6f, 0x75, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x0f, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x6f, 0x75, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x22, 0x3b, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x29, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x73, 0x22, 0x57, 0x0a, 0x0d, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x63, 0x6b, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x69, 0x63, 0x6b, 0x22, 0x40, 0x0a, 0x0e, 0x50,
+	0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2e, 0x0a,
+	0x06, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x48,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x06, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x22, 0x2e, 0x0a,
+	0x12, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x22, 0xfa, 0x01,
+	0x0a, 0x0c, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2d, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x30, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
+	0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x6e, 0x69,
+	0x78, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x74, 0x69,
+	0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f, 0x22, 0x80, 0x01, 0x0a, 0x13, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x30, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x12, 0x30, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62,
+	0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x2a, 0x76, 0x0a,
+	0x08, 0x50, 0x6f, 0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x54, 0x5f,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x50, 0x54, 0x5f,
+	0x50, 0x32, 0x43, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x50, 0x54, 0x5f, 0x57, 0x45, 0x49, 0x47,
+	0x48, 0x54, 0x45, 0x44, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x54, 0x5f, 0x52, 0x4f, 0x55,
+	0x4e, 0x44, 0x5f, 0x52, 0x4f, 0x42, 0x49, 0x4e, 0x10, 0x03, 0x12, 0x11, 0x0a, 0x0d, 0x50, 0x54,
+	0x5f, 0x4c, 0x45, 0x41, 0x53, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x4e, 0x10, 0x04, 0x12, 0x16, 0x0a,
+	0x12, 0x50, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x5f, 0x48,
+	0x41, 0x53, 0x48, 0x10, 0x05, 0x2a, 0x48, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x53, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
+	0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x53, 0x5f, 0x46, 0x55, 0x4c, 0x4c, 0x10, 0x01,
+	0x12, 0x0c, 0x0a, 0x08, 0x50, 0x53, 0x5f, 0x45, 0x4d, 0x50, 0x54, 0x59, 0x10, 0x02, 0x12, 0x0f,
+	0x0a, 0x0b, 0x50, 0x53, 0x5f, 0x44, 0x45, 0x47, 0x52, 0x41, 0x44, 0x45, 0x44, 0x10, 0x03, 0x2a,
+	0x4d, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x0e, 0x0a, 0x0a, 0x42, 0x53, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00,
+	0x12, 0x0e, 0x0a, 0x0a, 0x42, 0x53, 0x5f, 0x48, 0x45, 0x41, 0x4c, 0x54, 0x48, 0x59, 0x10, 0x01,
+	0x12, 0x0b, 0x0a, 0x07, 0x42, 0x53, 0x5f, 0x53, 0x49, 0x43, 0x4b, 0x10, 0x02, 0x12, 0x0f, 0x0a,
+	0x0b, 0x42, 0x53, 0x5f, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x2a, 0x74,
+	0x0a, 0x10, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x45, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
+	0x4e, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x42, 0x45, 0x54, 0x5f, 0x41, 0x44, 0x44, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x45, 0x54, 0x5f, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45,
+	0x44, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x45, 0x54, 0x5f, 0x48, 0x45, 0x41, 0x4c, 0x54,
+	0x48, 0x59, 0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x42, 0x45, 0x54, 0x5f, 0x53, 0x49, 0x43, 0x4b,
+	0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x42, 0x45, 0x54, 0x5f, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49,
+	0x4e, 0x47, 0x10, 0x05, 0x32, 0xd8, 0x07, 0x0a, 0x0c, 0x4c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x72, 0x12, 0x3c, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6f, 0x6c,
+	0x12, 0x16, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64,
+	0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x17, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f,
+	0x6c, 0x12, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x1a, 0x2e, 0x72,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0a, 0x41, 0x64,
+	0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
 	0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
-	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x1c, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
-	0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e,
-	0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x12, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c,
-	0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x1a,
-	0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f,
-	0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x42, 0x52, 0x5a,
-	0x50, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x50, 0x61, 0x63, 0x6b,
-	0x74, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x2f, 0x47, 0x6f, 0x2d, 0x66,
-	0x6f, 0x72, 0x2d, 0x44, 0x65, 0x76, 0x4f, 0x70, 0x73, 0x2f, 0x63, 0x68, 0x61, 0x70, 0x74, 0x65,
-	0x72, 0x2f, 0x31, 0x2f, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x36, 0x2f, 0x72, 0x6f,
-	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2f, 0x6c, 0x62, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c,
-	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x65, 0x71, 0x1a, 0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62,
+	0x2e, 0x41, 0x64, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22,
+	0x00, 0x12, 0x4e, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x12, 0x1c, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71,
+	0x1a, 0x1d, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22,
+	0x00, 0x12, 0x45, 0x0a, 0x0a, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12,
+	0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f,
+	0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x1a, 0x2e, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x56, 0x0a, 0x0f, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x1e, 0x2e, 0x72, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f,
+	0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x1f, 0x2e, 0x72, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f,
+	0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x30, 0x01,
+	0x12, 0x4b, 0x0a, 0x0c, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x12, 0x1b, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x44, 0x72,
+	0x61, 0x69, 0x6e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x1c, 0x2e,
+	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x44, 0x72, 0x61, 0x69, 0x6e,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x57, 0x0a,
+	0x10, 0x53, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x12, 0x1f, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x53,
+	0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52,
+	0x65, 0x71, 0x1a, 0x20, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x53, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x4b, 0x0a, 0x0c, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1b, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x2e, 0x6c, 0x62, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x65, 0x71, 0x1a, 0x1c, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62,
+	0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73,
+	0x70, 0x22, 0x00, 0x12, 0x48, 0x0a, 0x0b, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x41, 0x70, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x1a, 0x1b,
+	0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x70, 0x70, 0x6c,
+	0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x3f, 0x0a,
+	0x08, 0x41, 0x64, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x1a, 0x18, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e,
+	0x41, 0x64, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x48,
+	0x0a, 0x0b, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x1a, 0x2e,
+	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x1b, 0x2e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x12, 0x19, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x2e, 0x6c, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x1a, 0x1a, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x6c, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x42,
+	0x48, 0x5a, 0x46, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x50, 0x61,
+	0x63, 0x6b, 0x74, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x2f, 0x47, 0x6f,
+	0x2d, 0x66, 0x6f, 0x72, 0x2d, 0x44, 0x65, 0x76, 0x4f, 0x70, 0x73, 0x2f, 0x63, 0x68, 0x61, 0x70,
+	0x74, 0x65, 0x72, 0x2f, 0x36, 0x2f, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x2f, 0x6c, 0x62,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6c, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -1245,58 +3588,135 @@ func file_lb_proto_rawDescGZIP() []byte {
 	return file_lb_proto_rawDescData
 }
 
-var file_lb_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_lb_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_lb_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_lb_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_lb_proto_goTypes = []interface{}{
-	(PoolType)(0),             // 0: rollout.lb.PoolType
-	(PoolStatus)(0),           // 1: rollout.lb.PoolStatus
-	(BackendStatus)(0),        // 2: rollout.lb.BackendStatus
-	(*HealthChecks)(nil),      // 3: rollout.lb.HealthChecks
-	(*HealthCheck)(nil),       // 4: rollout.lb.HealthCheck
-	(*StatusCheck)(nil),       // 5: rollout.lb.StatusCheck
-	(*Backend)(nil),           // 6: rollout.lb.Backend
-	(*IPBackend)(nil),         // 7: rollout.lb.IPBackend
-	(*PoolHealth)(nil),        // 8: rollout.lb.PoolHealth
-	(*BackendHealth)(nil),     // 9: rollout.lb.BackendHealth
-	(*AddPoolReq)(nil),        // 10: rollout.lb.AddPoolReq
-	(*AddPoolResp)(nil),       // 11: rollout.lb.AddPoolResp
-	(*RemovePoolReq)(nil),     // 12: rollout.lb.RemovePoolReq
-	(*RemovePoolResp)(nil),    // 13: rollout.lb.RemovePoolResp
-	(*AddBackendReq)(nil),     // 14: rollout.lb.AddBackendReq
-	(*AddBackendResp)(nil),    // 15: rollout.lb.AddBackendResp
-	(*RemoveBackendReq)(nil),  // 16: rollout.lb.RemoveBackendReq
-	(*RemoveBackendResp)(nil), // 17: rollout.lb.RemoveBackendResp
-	(*PoolHealthReq)(nil),     // 18: rollout.lb.PoolHealthReq
-	(*PoolHealthResp)(nil),    // 19: rollout.lb.PoolHealthResp
+	(PoolType)(0),                // 0: rollout.lb.PoolType
+	(PoolStatus)(0),              // 1: rollout.lb.PoolStatus
+	(BackendStatus)(0),           // 2: rollout.lb.BackendStatus
+	(BackendEventType)(0),        // 3: rollout.lb.BackendEventType
+	(*HealthChecks)(nil),         // 4: rollout.lb.HealthChecks
+	(*HealthCheck)(nil),          // 5: rollout.lb.HealthCheck
+	(*StatusCheck)(nil),          // 6: rollout.lb.StatusCheck
+	(*TCPCheck)(nil),             // 7: rollout.lb.TCPCheck
+	(*GRPCCheck)(nil),            // 8: rollout.lb.GRPCCheck
+	(*HTTPCodeCheck)(nil),        // 9: rollout.lb.HTTPCodeCheck
+	(*LatencyCheck)(nil),         // 10: rollout.lb.LatencyCheck
+	(*OutlierDetection)(nil),     // 11: rollout.lb.OutlierDetection
+	(*RetryPolicy)(nil),          // 12: rollout.lb.RetryPolicy
+	(*BackendTLS)(nil),           // 13: rollout.lb.BackendTLS
+	(*HashKey)(nil),              // 14: rollout.lb.HashKey
+	(*Backend)(nil),              // 15: rollout.lb.Backend
+	(*IPBackend)(nil),            // 16: rollout.lb.IPBackend
+	(*PoolHealth)(nil),           // 17: rollout.lb.PoolHealth
+	(*BackendHealth)(nil),        // 18: rollout.lb.BackendHealth
+	(*AddPoolReq)(nil),           // 19: rollout.lb.AddPoolReq
+	(*AddPoolResp)(nil),          // 20: rollout.lb.AddPoolResp
+	(*RemovePoolReq)(nil),        // 21: rollout.lb.RemovePoolReq
+	(*RemovePoolResp)(nil),       // 22: rollout.lb.RemovePoolResp
+	(*AddBackendReq)(nil),        // 23: rollout.lb.AddBackendReq
+	(*AddBackendResp)(nil),       // 24: rollout.lb.AddBackendResp
+	(*RemoveBackendReq)(nil),     // 25: rollout.lb.RemoveBackendReq
+	(*RemoveBackendResp)(nil),    // 26: rollout.lb.RemoveBackendResp
+	(*SetBackendWeightReq)(nil),  // 27: rollout.lb.SetBackendWeightReq
+	(*SetBackendWeightResp)(nil), // 28: rollout.lb.SetBackendWeightResp
+	(*DrainBackendReq)(nil),      // 29: rollout.lb.DrainBackendReq
+	(*DrainBackendResp)(nil),     // 30: rollout.lb.DrainBackendResp
+	(*PoolConfig)(nil),           // 31: rollout.lb.PoolConfig
+	(*BackendConfig)(nil),        // 32: rollout.lb.BackendConfig
+	(*LBConfig)(nil),             // 33: rollout.lb.LBConfig
+	(*ExportConfigReq)(nil),      // 34: rollout.lb.ExportConfigReq
+	(*ExportConfigResp)(nil),     // 35: rollout.lb.ExportConfigResp
+	(*ApplyConfigReq)(nil),       // 36: rollout.lb.ApplyConfigReq
+	(*ApplyConfigResp)(nil),      // 37: rollout.lb.ApplyConfigResp
+	(*Route)(nil),                // 38: rollout.lb.Route
+	(*HeaderMatch)(nil),          // 39: rollout.lb.HeaderMatch
+	(*AddRouteReq)(nil),          // 40: rollout.lb.AddRouteReq
+	(*AddRouteResp)(nil),         // 41: rollout.lb.AddRouteResp
+	(*RemoveRouteReq)(nil),       // 42: rollout.lb.RemoveRouteReq
+	(*RemoveRouteResp)(nil),      // 43: rollout.lb.RemoveRouteResp
+	(*ListRoutesReq)(nil),        // 44: rollout.lb.ListRoutesReq
+	(*ListRoutesResp)(nil),       // 45: rollout.lb.ListRoutesResp
+	(*PoolHealthReq)(nil),        // 46: rollout.lb.PoolHealthReq
+	(*PoolHealthResp)(nil),       // 47: rollout.lb.PoolHealthResp
+	(*WatchPoolHealthReq)(nil),   // 48: rollout.lb.WatchPoolHealthReq
+	(*BackendEvent)(nil),         // 49: rollout.lb.BackendEvent
+	(*WatchPoolHealthResp)(nil),  // 50: rollout.lb.WatchPoolHealthResp
 }
 var file_lb_proto_depIdxs = []int32{
-	4,  // 0: rollout.lb.HealthChecks.health_checks:type_name -> rollout.lb.HealthCheck
-	5,  // 1: rollout.lb.HealthCheck.status_check:type_name -> rollout.lb.StatusCheck
-	7,  // 2: rollout.lb.Backend.ip_backend:type_name -> rollout.lb.IPBackend
-	1,  // 3: rollout.lb.PoolHealth.status:type_name -> rollout.lb.PoolStatus
-	9,  // 4: rollout.lb.PoolHealth.backends:type_name -> rollout.lb.BackendHealth
-	6,  // 5: rollout.lb.BackendHealth.backend:type_name -> rollout.lb.Backend
-	2,  // 6: rollout.lb.BackendHealth.status:type_name -> rollout.lb.BackendStatus
-	0,  // 7: rollout.lb.AddPoolReq.pool_type:type_name -> rollout.lb.PoolType
-	3,  // 8: rollout.lb.AddPoolReq.health_checks:type_name -> rollout.lb.HealthChecks
-	6,  // 9: rollout.lb.AddBackendReq.backend:type_name -> rollout.lb.Backend
-	6,  // 10: rollout.lb.RemoveBackendReq.backend:type_name -> rollout.lb.Backend
-	8,  // 11: rollout.lb.PoolHealthResp.health:type_name -> rollout.lb.PoolHealth
-	10, // 12: rollout.lb.LoadBalancer.AddPool:input_type -> rollout.lb.AddPoolReq
-	12, // 13: rollout.lb.LoadBalancer.RemovePool:input_type -> rollout.lb.RemovePoolReq
-	14, // 14: rollout.lb.LoadBalancer.AddBackend:input_type -> rollout.lb.AddBackendReq
-	16, // 15: rollout.lb.LoadBalancer.RemoveBackend:input_type -> rollout.lb.RemoveBackendReq
-	18, // 16: rollout.lb.LoadBalancer.PoolHealth:input_type -> rollout.lb.PoolHealthReq
-	11, // 17: rollout.lb.LoadBalancer.AddPool:output_type -> rollout.lb.AddPoolResp
-	13, // 18: rollout.lb.LoadBalancer.RemovePool:output_type -> rollout.lb.RemovePoolResp
-	15, // 19: rollout.lb.LoadBalancer.AddBackend:output_type -> rollout.lb.AddBackendResp
-	17, // 20: rollout.lb.LoadBalancer.RemoveBackend:output_type -> rollout.lb.RemoveBackendResp
-	19, // 21: rollout.lb.LoadBalancer.PoolHealth:output_type -> rollout.lb.PoolHealthResp
-	17, // [17:22] is the sub-list for method output_type
-	12, // [12:17] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	5,  // 0: rollout.lb.HealthChecks.health_checks:type_name -> rollout.lb.HealthCheck
+	6,  // 1: rollout.lb.HealthCheck.status_check:type_name -> rollout.lb.StatusCheck
+	7,  // 2: rollout.lb.HealthCheck.tcp_check:type_name -> rollout.lb.TCPCheck
+	8,  // 3: rollout.lb.HealthCheck.grpc_check:type_name -> rollout.lb.GRPCCheck
+	9,  // 4: rollout.lb.HealthCheck.http_code_check:type_name -> rollout.lb.HTTPCodeCheck
+	10, // 5: rollout.lb.HealthCheck.latency_check:type_name -> rollout.lb.LatencyCheck
+	16, // 6: rollout.lb.Backend.ip_backend:type_name -> rollout.lb.IPBackend
+	1,  // 7: rollout.lb.PoolHealth.status:type_name -> rollout.lb.PoolStatus
+	18, // 8: rollout.lb.PoolHealth.backends:type_name -> rollout.lb.BackendHealth
+	15, // 9: rollout.lb.BackendHealth.backend:type_name -> rollout.lb.Backend
+	2,  // 10: rollout.lb.BackendHealth.status:type_name -> rollout.lb.BackendStatus
+	0,  // 11: rollout.lb.AddPoolReq.pool_type:type_name -> rollout.lb.PoolType
+	4,  // 12: rollout.lb.AddPoolReq.health_checks:type_name -> rollout.lb.HealthChecks
+	14, // 13: rollout.lb.AddPoolReq.hash_key:type_name -> rollout.lb.HashKey
+	11, // 14: rollout.lb.AddPoolReq.outlier_detection:type_name -> rollout.lb.OutlierDetection
+	12, // 15: rollout.lb.AddPoolReq.retry_policy:type_name -> rollout.lb.RetryPolicy
+	13, // 16: rollout.lb.AddPoolReq.backend_tls:type_name -> rollout.lb.BackendTLS
+	15, // 17: rollout.lb.AddBackendReq.backend:type_name -> rollout.lb.Backend
+	15, // 18: rollout.lb.RemoveBackendReq.backend:type_name -> rollout.lb.Backend
+	15, // 19: rollout.lb.SetBackendWeightReq.backend:type_name -> rollout.lb.Backend
+	15, // 20: rollout.lb.DrainBackendReq.backend:type_name -> rollout.lb.Backend
+	0,  // 21: rollout.lb.PoolConfig.pool_type:type_name -> rollout.lb.PoolType
+	4,  // 22: rollout.lb.PoolConfig.health_checks:type_name -> rollout.lb.HealthChecks
+	14, // 23: rollout.lb.PoolConfig.hash_key:type_name -> rollout.lb.HashKey
+	32, // 24: rollout.lb.PoolConfig.backends:type_name -> rollout.lb.BackendConfig
+	11, // 25: rollout.lb.PoolConfig.outlier_detection:type_name -> rollout.lb.OutlierDetection
+	12, // 26: rollout.lb.PoolConfig.retry_policy:type_name -> rollout.lb.RetryPolicy
+	13, // 27: rollout.lb.PoolConfig.backend_tls:type_name -> rollout.lb.BackendTLS
+	15, // 28: rollout.lb.BackendConfig.backend:type_name -> rollout.lb.Backend
+	31, // 29: rollout.lb.LBConfig.pools:type_name -> rollout.lb.PoolConfig
+	38, // 30: rollout.lb.LBConfig.routes:type_name -> rollout.lb.Route
+	33, // 31: rollout.lb.ExportConfigResp.config:type_name -> rollout.lb.LBConfig
+	33, // 32: rollout.lb.ApplyConfigReq.config:type_name -> rollout.lb.LBConfig
+	39, // 33: rollout.lb.Route.headers:type_name -> rollout.lb.HeaderMatch
+	38, // 34: rollout.lb.AddRouteReq.route:type_name -> rollout.lb.Route
+	38, // 35: rollout.lb.ListRoutesResp.routes:type_name -> rollout.lb.Route
+	17, // 36: rollout.lb.PoolHealthResp.health:type_name -> rollout.lb.PoolHealth
+	15, // 37: rollout.lb.BackendEvent.backend:type_name -> rollout.lb.Backend
+	3,  // 38: rollout.lb.BackendEvent.type:type_name -> rollout.lb.BackendEventType
+	2,  // 39: rollout.lb.BackendEvent.status:type_name -> rollout.lb.BackendStatus
+	17, // 40: rollout.lb.WatchPoolHealthResp.health:type_name -> rollout.lb.PoolHealth
+	49, // 41: rollout.lb.WatchPoolHealthResp.event:type_name -> rollout.lb.BackendEvent
+	19, // 42: rollout.lb.LoadBalancer.AddPool:input_type -> rollout.lb.AddPoolReq
+	21, // 43: rollout.lb.LoadBalancer.RemovePool:input_type -> rollout.lb.RemovePoolReq
+	23, // 44: rollout.lb.LoadBalancer.AddBackend:input_type -> rollout.lb.AddBackendReq
+	25, // 45: rollout.lb.LoadBalancer.RemoveBackend:input_type -> rollout.lb.RemoveBackendReq
+	46, // 46: rollout.lb.LoadBalancer.PoolHealth:input_type -> rollout.lb.PoolHealthReq
+	48, // 47: rollout.lb.LoadBalancer.WatchPoolHealth:input_type -> rollout.lb.WatchPoolHealthReq
+	29, // 48: rollout.lb.LoadBalancer.DrainBackend:input_type -> rollout.lb.DrainBackendReq
+	27, // 49: rollout.lb.LoadBalancer.SetBackendWeight:input_type -> rollout.lb.SetBackendWeightReq
+	34, // 50: rollout.lb.LoadBalancer.ExportConfig:input_type -> rollout.lb.ExportConfigReq
+	36, // 51: rollout.lb.LoadBalancer.ApplyConfig:input_type -> rollout.lb.ApplyConfigReq
+	40, // 52: rollout.lb.LoadBalancer.AddRoute:input_type -> rollout.lb.AddRouteReq
+	42, // 53: rollout.lb.LoadBalancer.RemoveRoute:input_type -> rollout.lb.RemoveRouteReq
+	44, // 54: rollout.lb.LoadBalancer.ListRoutes:input_type -> rollout.lb.ListRoutesReq
+	20, // 55: rollout.lb.LoadBalancer.AddPool:output_type -> rollout.lb.AddPoolResp
+	22, // 56: rollout.lb.LoadBalancer.RemovePool:output_type -> rollout.lb.RemovePoolResp
+	24, // 57: rollout.lb.LoadBalancer.AddBackend:output_type -> rollout.lb.AddBackendResp
+	26, // 58: rollout.lb.LoadBalancer.RemoveBackend:output_type -> rollout.lb.RemoveBackendResp
+	47, // 59: rollout.lb.LoadBalancer.PoolHealth:output_type -> rollout.lb.PoolHealthResp
+	50, // 60: rollout.lb.LoadBalancer.WatchPoolHealth:output_type -> rollout.lb.WatchPoolHealthResp
+	30, // 61: rollout.lb.LoadBalancer.DrainBackend:output_type -> rollout.lb.DrainBackendResp
+	28, // 62: rollout.lb.LoadBalancer.SetBackendWeight:output_type -> rollout.lb.SetBackendWeightResp
+	35, // 63: rollout.lb.LoadBalancer.ExportConfig:output_type -> rollout.lb.ExportConfigResp
+	37, // 64: rollout.lb.LoadBalancer.ApplyConfig:output_type -> rollout.lb.ApplyConfigResp
+	41, // 65: rollout.lb.LoadBalancer.AddRoute:output_type -> rollout.lb.AddRouteResp
+	43, // 66: rollout.lb.LoadBalancer.RemoveRoute:output_type -> rollout.lb.RemoveRouteResp
+	45, // 67: rollout.lb.LoadBalancer.ListRoutes:output_type -> rollout.lb.ListRoutesResp
+	55, // [55:68] is the sub-list for method output_type
+	42, // [42:55] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
 }
 
 func init() { file_lb_proto_init() }
@@ -1317,8 +3737,104 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthCheck); i {
+		file_lb_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TCPCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GRPCCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HTTPCodeCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatencyCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutlierDetection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendTLS); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1329,8 +3845,8 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatusCheck); i {
+		file_lb_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashKey); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1341,7 +3857,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Backend); i {
 			case 0:
 				return &v.state
@@ -1353,7 +3869,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*IPBackend); i {
 			case 0:
 				return &v.state
@@ -1365,7 +3881,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PoolHealth); i {
 			case 0:
 				return &v.state
@@ -1377,7 +3893,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BackendHealth); i {
 			case 0:
 				return &v.state
@@ -1389,7 +3905,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AddPoolReq); i {
 			case 0:
 				return &v.state
@@ -1401,7 +3917,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AddPoolResp); i {
 			case 0:
 				return &v.state
@@ -1413,7 +3929,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RemovePoolReq); i {
 			case 0:
 				return &v.state
@@ -1425,7 +3941,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RemovePoolResp); i {
 			case 0:
 				return &v.state
@@ -1437,7 +3953,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AddBackendReq); i {
 			case 0:
 				return &v.state
@@ -1449,7 +3965,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AddBackendResp); i {
 			case 0:
 				return &v.state
@@ -1461,7 +3977,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RemoveBackendReq); i {
 			case 0:
 				return &v.state
@@ -1473,7 +3989,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RemoveBackendResp); i {
 			case 0:
 				return &v.state
@@ -1485,7 +4001,235 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetBackendWeightReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetBackendWeightResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainBackendReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainBackendResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PoolConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LBConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportConfigReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportConfigResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplyConfigReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplyConfigResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Route); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HeaderMatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddRouteReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddRouteResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveRouteReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveRouteResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRoutesReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRoutesResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PoolHealthReq); i {
 			case 0:
 				return &v.state
@@ -1497,7 +4241,7 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
-		file_lb_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_lb_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PoolHealthResp); i {
 			case 0:
 				return &v.state
@@ -1509,20 +4253,68 @@ func file_lb_proto_init() {
 				return nil
 			}
 		}
+		file_lb_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchPoolHealthReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lb_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchPoolHealthResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_lb_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*HealthCheck_StatusCheck)(nil),
+		(*HealthCheck_TcpCheck)(nil),
+		(*HealthCheck_GrpcCheck)(nil),
+		(*HealthCheck_HttpCodeCheck)(nil),
+		(*HealthCheck_LatencyCheck)(nil),
 	}
-	file_lb_proto_msgTypes[3].OneofWrappers = []interface{}{
+	file_lb_proto_msgTypes[10].OneofWrappers = []interface{}{
+		(*HashKey_Header)(nil),
+		(*HashKey_Cookie)(nil),
+	}
+	file_lb_proto_msgTypes[11].OneofWrappers = []interface{}{
 		(*Backend_IpBackend)(nil),
 	}
+	file_lb_proto_msgTypes[46].OneofWrappers = []interface{}{
+		(*WatchPoolHealthResp_Health)(nil),
+		(*WatchPoolHealthResp_Event)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_lb_proto_rawDesc,
-			NumEnums:      3,
-			NumMessages:   17,
+			NumEnums:      4,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
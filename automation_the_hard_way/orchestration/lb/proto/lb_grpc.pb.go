@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.18.0
+// source: lb.proto
 
 package lb
 
@@ -23,6 +27,17 @@ type LoadBalancerClient interface {
 	AddBackend(ctx context.Context, in *AddBackendReq, opts ...grpc.CallOption) (*AddBackendResp, error)
 	RemoveBackend(ctx context.Context, in *RemoveBackendReq, opts ...grpc.CallOption) (*RemoveBackendResp, error)
 	PoolHealth(ctx context.Context, in *PoolHealthReq, opts ...grpc.CallOption) (*PoolHealthResp, error)
+	// WatchPoolHealth streams changes to the backends in a pool. The stream ends
+	// if the pool is removed or the client can't keep up, after which the
+	// client should watch again.
+	WatchPoolHealth(ctx context.Context, in *WatchPoolHealthReq, opts ...grpc.CallOption) (LoadBalancer_WatchPoolHealthClient, error)
+	DrainBackend(ctx context.Context, in *DrainBackendReq, opts ...grpc.CallOption) (*DrainBackendResp, error)
+	SetBackendWeight(ctx context.Context, in *SetBackendWeightReq, opts ...grpc.CallOption) (*SetBackendWeightResp, error)
+	ExportConfig(ctx context.Context, in *ExportConfigReq, opts ...grpc.CallOption) (*ExportConfigResp, error)
+	ApplyConfig(ctx context.Context, in *ApplyConfigReq, opts ...grpc.CallOption) (*ApplyConfigResp, error)
+	AddRoute(ctx context.Context, in *AddRouteReq, opts ...grpc.CallOption) (*AddRouteResp, error)
+	RemoveRoute(ctx context.Context, in *RemoveRouteReq, opts ...grpc.CallOption) (*RemoveRouteResp, error)
+	ListRoutes(ctx context.Context, in *ListRoutesReq, opts ...grpc.CallOption) (*ListRoutesResp, error)
 }
 
 type loadBalancerClient struct {
@@ -78,6 +93,101 @@ func (c *loadBalancerClient) PoolHealth(ctx context.Context, in *PoolHealthReq,
 	return out, nil
 }
 
+func (c *loadBalancerClient) WatchPoolHealth(ctx context.Context, in *WatchPoolHealthReq, opts ...grpc.CallOption) (LoadBalancer_WatchPoolHealthClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LoadBalancer_ServiceDesc.Streams[0], "/rollout.lb.LoadBalancer/WatchPoolHealth", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &loadBalancerWatchPoolHealthClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LoadBalancer_WatchPoolHealthClient interface {
+	Recv() (*WatchPoolHealthResp, error)
+	grpc.ClientStream
+}
+
+type loadBalancerWatchPoolHealthClient struct {
+	grpc.ClientStream
+}
+
+func (x *loadBalancerWatchPoolHealthClient) Recv() (*WatchPoolHealthResp, error) {
+	m := new(WatchPoolHealthResp)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *loadBalancerClient) DrainBackend(ctx context.Context, in *DrainBackendReq, opts ...grpc.CallOption) (*DrainBackendResp, error) {
+	out := new(DrainBackendResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/DrainBackend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) SetBackendWeight(ctx context.Context, in *SetBackendWeightReq, opts ...grpc.CallOption) (*SetBackendWeightResp, error) {
+	out := new(SetBackendWeightResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/SetBackendWeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) ExportConfig(ctx context.Context, in *ExportConfigReq, opts ...grpc.CallOption) (*ExportConfigResp, error) {
+	out := new(ExportConfigResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/ExportConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) ApplyConfig(ctx context.Context, in *ApplyConfigReq, opts ...grpc.CallOption) (*ApplyConfigResp, error) {
+	out := new(ApplyConfigResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/ApplyConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) AddRoute(ctx context.Context, in *AddRouteReq, opts ...grpc.CallOption) (*AddRouteResp, error) {
+	out := new(AddRouteResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/AddRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) RemoveRoute(ctx context.Context, in *RemoveRouteReq, opts ...grpc.CallOption) (*RemoveRouteResp, error) {
+	out := new(RemoveRouteResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/RemoveRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loadBalancerClient) ListRoutes(ctx context.Context, in *ListRoutesReq, opts ...grpc.CallOption) (*ListRoutesResp, error) {
+	out := new(ListRoutesResp)
+	err := c.cc.Invoke(ctx, "/rollout.lb.LoadBalancer/ListRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LoadBalancerServer is the server API for LoadBalancer service.
 // All implementations must embed UnimplementedLoadBalancerServer
 // for forward compatibility
@@ -87,6 +197,17 @@ type LoadBalancerServer interface {
 	AddBackend(context.Context, *AddBackendReq) (*AddBackendResp, error)
 	RemoveBackend(context.Context, *RemoveBackendReq) (*RemoveBackendResp, error)
 	PoolHealth(context.Context, *PoolHealthReq) (*PoolHealthResp, error)
+	// WatchPoolHealth streams changes to the backends in a pool. The stream ends
+	// if the pool is removed or the client can't keep up, after which the
+	// client should watch again.
+	WatchPoolHealth(*WatchPoolHealthReq, LoadBalancer_WatchPoolHealthServer) error
+	DrainBackend(context.Context, *DrainBackendReq) (*DrainBackendResp, error)
+	SetBackendWeight(context.Context, *SetBackendWeightReq) (*SetBackendWeightResp, error)
+	ExportConfig(context.Context, *ExportConfigReq) (*ExportConfigResp, error)
+	ApplyConfig(context.Context, *ApplyConfigReq) (*ApplyConfigResp, error)
+	AddRoute(context.Context, *AddRouteReq) (*AddRouteResp, error)
+	RemoveRoute(context.Context, *RemoveRouteReq) (*RemoveRouteResp, error)
+	ListRoutes(context.Context, *ListRoutesReq) (*ListRoutesResp, error)
 	mustEmbedUnimplementedLoadBalancerServer()
 }
 
@@ -109,6 +230,30 @@ func (UnimplementedLoadBalancerServer) RemoveBackend(context.Context, *RemoveBac
 func (UnimplementedLoadBalancerServer) PoolHealth(context.Context, *PoolHealthReq) (*PoolHealthResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PoolHealth not implemented")
 }
+func (UnimplementedLoadBalancerServer) WatchPoolHealth(*WatchPoolHealthReq, LoadBalancer_WatchPoolHealthServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPoolHealth not implemented")
+}
+func (UnimplementedLoadBalancerServer) DrainBackend(context.Context, *DrainBackendReq) (*DrainBackendResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainBackend not implemented")
+}
+func (UnimplementedLoadBalancerServer) SetBackendWeight(context.Context, *SetBackendWeightReq) (*SetBackendWeightResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBackendWeight not implemented")
+}
+func (UnimplementedLoadBalancerServer) ExportConfig(context.Context, *ExportConfigReq) (*ExportConfigResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportConfig not implemented")
+}
+func (UnimplementedLoadBalancerServer) ApplyConfig(context.Context, *ApplyConfigReq) (*ApplyConfigResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyConfig not implemented")
+}
+func (UnimplementedLoadBalancerServer) AddRoute(context.Context, *AddRouteReq) (*AddRouteResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddRoute not implemented")
+}
+func (UnimplementedLoadBalancerServer) RemoveRoute(context.Context, *RemoveRouteReq) (*RemoveRouteResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveRoute not implemented")
+}
+func (UnimplementedLoadBalancerServer) ListRoutes(context.Context, *ListRoutesReq) (*ListRoutesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoutes not implemented")
+}
 func (UnimplementedLoadBalancerServer) mustEmbedUnimplementedLoadBalancerServer() {}
 
 // UnsafeLoadBalancerServer may be embedded to opt out of forward compatibility for this service.
@@ -212,6 +357,153 @@ func _LoadBalancer_PoolHealth_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LoadBalancer_WatchPoolHealth_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPoolHealthReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LoadBalancerServer).WatchPoolHealth(m, &loadBalancerWatchPoolHealthServer{stream})
+}
+
+type LoadBalancer_WatchPoolHealthServer interface {
+	Send(*WatchPoolHealthResp) error
+	grpc.ServerStream
+}
+
+type loadBalancerWatchPoolHealthServer struct {
+	grpc.ServerStream
+}
+
+func (x *loadBalancerWatchPoolHealthServer) Send(m *WatchPoolHealthResp) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LoadBalancer_DrainBackend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainBackendReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).DrainBackend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/DrainBackend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).DrainBackend(ctx, req.(*DrainBackendReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_SetBackendWeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBackendWeightReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).SetBackendWeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/SetBackendWeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).SetBackendWeight(ctx, req.(*SetBackendWeightReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_ExportConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportConfigReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).ExportConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/ExportConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).ExportConfig(ctx, req.(*ExportConfigReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_ApplyConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyConfigReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).ApplyConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/ApplyConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).ApplyConfig(ctx, req.(*ApplyConfigReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_AddRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRouteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).AddRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/AddRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).AddRoute(ctx, req.(*AddRouteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_RemoveRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveRouteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).RemoveRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/RemoveRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).RemoveRoute(ctx, req.(*RemoveRouteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoadBalancer_ListRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRoutesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoadBalancerServer).ListRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rollout.lb.LoadBalancer/ListRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoadBalancerServer).ListRoutes(ctx, req.(*ListRoutesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LoadBalancer_ServiceDesc is the grpc.ServiceDesc for LoadBalancer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -239,7 +531,41 @@ var LoadBalancer_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PoolHealth",
 			Handler:    _LoadBalancer_PoolHealth_Handler,
 		},
+		{
+			MethodName: "DrainBackend",
+			Handler:    _LoadBalancer_DrainBackend_Handler,
+		},
+		{
+			MethodName: "SetBackendWeight",
+			Handler:    _LoadBalancer_SetBackendWeight_Handler,
+		},
+		{
+			MethodName: "ExportConfig",
+			Handler:    _LoadBalancer_ExportConfig_Handler,
+		},
+		{
+			MethodName: "ApplyConfig",
+			Handler:    _LoadBalancer_ApplyConfig_Handler,
+		},
+		{
+			MethodName: "AddRoute",
+			Handler:    _LoadBalancer_AddRoute_Handler,
+		},
+		{
+			MethodName: "RemoveRoute",
+			Handler:    _LoadBalancer_RemoveRoute_Handler,
+		},
+		{
+			MethodName: "ListRoutes",
+			Handler:    _LoadBalancer_ListRoutes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPoolHealth",
+			Handler:       _LoadBalancer_WatchPoolHealth_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "lb.proto",
 }
@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// AuditEntry is a record of a request that tried to change the load balancer.
+type AuditEntry struct {
+	// Time is when the request finished.
+	Time time.Time
+	// Identity is who made the request.
+	Identity string
+	// Method is the RPC that was called, such as "RemovePool".
+	Method string
+	// Request is the request, as protojson.
+	Request json.RawMessage
+	// Error is why the request failed. It is empty if the request succeeded.
+	Error string `json:",omitempty"`
+}
+
+// AuditLog writes AuditEntry(s) as JSON lines to a file. The file is only ever appended to.
+type AuditLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenAuditLog opens the AuditLog at p, creating it if it doesn't exist.
+func OpenAuditLog(p string) (*AuditLog, error) {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log(%s): %w", p, err)
+	}
+	return &AuditLog{f: f}, nil
+}
+
+// Write writes e to the log and syncs it to disk.
+func (a *AuditLog) Write(e AuditEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.f.Write(b); err != nil {
+		return err
+	}
+	return a.f.Sync()
+}
+
+// Close closes the log.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.f.Close()
+}
+
+// audit writes an entry to s.Audit, if it is set, for a call by id to fullMethod with req.
+// err is the error the call returned.
+func (s *Server) audit(id Identity, fullMethod string, req any, err error) {
+	if s.Audit == nil {
+		return
+	}
+
+	e := AuditEntry{
+		Time:     time.Now().UTC(),
+		Identity: id.String(),
+		Method:   path.Base(fullMethod),
+		Request:  json.RawMessage("null"),
+	}
+	if m, ok := req.(proto.Message); ok {
+		b, merr := protojson.Marshal(m)
+		if merr == nil {
+			e.Request = b
+		}
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if err := s.Audit.Write(e); err != nil {
+		log.Printf("could not write audit log entry(%+v): %s", e, err)
+	}
+}
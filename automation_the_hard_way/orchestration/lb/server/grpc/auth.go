@@ -0,0 +1,349 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+// Identity is who made a request.
+type Identity struct {
+	// Name is the name of the caller, such as a token's name or a certificate's common name.
+	Name string
+	// Method is how the caller was authenticated, such as "token" or "mtls".
+	Method string
+}
+
+func (i Identity) String() string {
+	if i.Method == "" {
+		return i.Name
+	}
+	return i.Method + ":" + i.Name
+}
+
+// anonymous is the Identity of callers when the Server has no Authenticator.
+var anonymous = Identity{Name: "anonymous"}
+
+// Authenticator finds out who made the request in ctx. It returns an error if the
+// caller can't be authenticated.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (Identity, error)
+}
+
+// Authorizer decides if id can call the RPC method. patterns are the pool patterns the
+// request affects. If there are none, the request affects all pools. write is true if
+// the request changes the load balancer.
+type Authorizer interface {
+	Authorize(id Identity, method string, patterns []string, write bool) error
+}
+
+// TokenAuth is an Authenticator for callers that send a bearer token in the "authorization"
+// metadata. Tokens should only be used over TLS.
+type TokenAuth struct {
+	tokens map[string]string // name -> token
+}
+
+// NewTokenAuth creates a TokenAuth. tokens is keyed by the name of the caller.
+func NewTokenAuth(tokens map[string]string) (*TokenAuth, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("must have at least one token")
+	}
+	for name, tok := range tokens {
+		if strings.TrimSpace(name) == "" || len(tok) < 16 {
+			return nil, fmt.Errorf("token for (%s) must have a name and be at least 16 characters", name)
+		}
+	}
+	return &TokenAuth{tokens: tokens}, nil
+}
+
+// LoadTokens creates a TokenAuth from a file that has a "name token" pair on each line.
+// Empty lines and lines starting with # are ignored.
+func LoadTokens(p string) (*TokenAuth, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("token file(%s) line %d: must be 'name token'", p, i)
+		}
+		if _, ok := tokens[fields[0]]; ok {
+			return nil, fmt.Errorf("token file(%s) line %d: name(%s) is listed twice", p, i, fields[0])
+		}
+		tokens[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewTokenAuth(tokens)
+}
+
+// Authenticate implements Authenticator.Authenticate().
+func (t *TokenAuth) Authenticate(ctx context.Context) (Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	vals := md.Get("authorization")
+	if len(vals) == 0 {
+		return Identity{}, fmt.Errorf("no authorization token")
+	}
+	tok, ok := strings.CutPrefix(vals[0], "Bearer ")
+	if !ok {
+		return Identity{}, fmt.Errorf("authorization must be a Bearer token")
+	}
+
+	// We check every token so that how long this takes doesn't tell a caller anything.
+	name := ""
+	for n, want := range t.tokens {
+		if subtle.ConstantTimeCompare([]byte(tok), []byte(want)) == 1 {
+			name = n
+		}
+	}
+	if name == "" {
+		return Identity{}, fmt.Errorf("invalid authorization token")
+	}
+	return Identity{Name: name, Method: "token"}, nil
+}
+
+// MTLSAuth is an Authenticator for callers that connected with a client certificate. The
+// certificate has already been verified by TLS, so the caller is its common name. Use this
+// with TLSCreds().
+type MTLSAuth struct{}
+
+// Authenticate implements Authenticator.Authenticate().
+func (MTLSAuth) Authenticate(ctx context.Context) (Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, fmt.Errorf("no peer information")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return Identity{}, fmt.Errorf("connection does not use TLS")
+	}
+	if len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return Identity{}, fmt.Errorf("no verified client certificate")
+	}
+	cn := info.State.VerifiedChains[0][0].Subject.CommonName
+	if cn == "" {
+		return Identity{}, fmt.Errorf("client certificate has no common name")
+	}
+	return Identity{Name: cn, Method: "mtls"}, nil
+}
+
+// MultiAuth is an Authenticator that tries each of its Authenticators in order and uses
+// the first that succeeds.
+type MultiAuth []Authenticator
+
+// Authenticate implements Authenticator.Authenticate().
+func (m MultiAuth) Authenticate(ctx context.Context) (Identity, error) {
+	var errs []string
+	for _, a := range m {
+		id, err := a.Authenticate(ctx)
+		if err == nil {
+			return id, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return Identity{}, fmt.Errorf("%s", strings.Join(errs, ", "))
+}
+
+// ACLRule gives identities access to pools.
+type ACLRule struct {
+	// Identities are the names of the callers the rule is for. "*" is any caller that
+	// authenticated.
+	Identities []string
+	// Patterns are the pool patterns the rule gives access to. A pattern ending in "*"
+	// matches patterns with that prefix. "*" matches all patterns and is needed for
+	// requests that affect all pools, such as ApplyConfig.
+	Patterns []string
+	// Write allows requests that change the pools. Otherwise only reads are allowed.
+	Write bool
+}
+
+// ACL is an Authorizer made of rules. A request is allowed if, for every pattern it affects,
+// a rule for the caller gives access to that pattern. Everything else is denied.
+type ACL struct {
+	Rules []ACLRule
+}
+
+// LoadACL reads an ACL stored as JSON from p.
+func LoadACL(p string) (*ACL, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	acl := &ACL{}
+	if err := json.Unmarshal(b, acl); err != nil {
+		return nil, fmt.Errorf("ACL file(%s) is invalid: %w", p, err)
+	}
+	return acl, nil
+}
+
+// Authorize implements Authorizer.Authorize().
+func (a *ACL) Authorize(id Identity, method string, patterns []string, write bool) error {
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+	for _, p := range patterns {
+		if !a.allowed(id, p, write) {
+			return fmt.Errorf("%s is not allowed to call %s on pattern(%s)", id, path.Base(method), p)
+		}
+	}
+	return nil
+}
+
+// allowed returns true if a rule gives id access to pattern.
+func (a *ACL) allowed(id Identity, pattern string, write bool) bool {
+	for _, r := range a.Rules {
+		if write && !r.Write {
+			continue
+		}
+		if !matchAny(r.Identities, id.Name) {
+			continue
+		}
+		if matchAny(r.Patterns, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny returns true if s matches any of globs. A glob is matched exactly, unless it ends
+// in "*" to match a prefix. A prefix never matches "*", which stands for all pools.
+func matchAny(globs []string, s string) bool {
+	for _, g := range globs {
+		switch {
+		case g == "*":
+			return true
+		case strings.HasSuffix(g, "*") && s != "*":
+			if strings.HasPrefix(s, strings.TrimSuffix(g, "*")) {
+				return true
+			}
+		case g == s:
+			return true
+		}
+	}
+	return false
+}
+
+// readMethods are the RPCs that don't change the load balancer. Every other RPC is
+// treated as a write.
+var readMethods = map[string]bool{
+	"PoolHealth":      true,
+	"WatchPoolHealth": true,
+	"ExportConfig":    true,
+	"ListRoutes":      true,
+}
+
+// isWrite returns true if the RPC fullMethod changes the load balancer.
+func isWrite(fullMethod string) bool {
+	return !readMethods[path.Base(fullMethod)]
+}
+
+// requestPatterns returns the pool patterns req affects. nil means it affects all pools.
+func requestPatterns(req any) []string {
+	switch r := req.(type) {
+	case *pb.AddRouteReq:
+		return []string{r.GetRoute().GetPool()}
+	case interface{ GetPattern() string }:
+		return []string{r.GetPattern()}
+	}
+	return nil
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the Identity of the caller of an RPC.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// authorize authenticates and authorizes a call to fullMethod with req. It returns a ctx
+// holding the caller's Identity.
+func (s *Server) authorize(ctx context.Context, fullMethod string, req any) (context.Context, Identity, error) {
+	id := anonymous
+	if s.Authenticator != nil {
+		var err error
+		id, err = s.Authenticator.Authenticate(ctx)
+		if err != nil {
+			return ctx, Identity{Name: "unauthenticated"}, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+	}
+	if s.Authorizer != nil {
+		if err := s.Authorizer.Authorize(id, fullMethod, requestPatterns(req), isWrite(fullMethod)); err != nil {
+			return ctx, id, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+	}
+	return context.WithValue(ctx, identityKey{}, id), id, nil
+}
+
+// unaryInterceptor authorizes every unary RPC and audits the ones that change the load balancer.
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, id, err := s.authorize(ctx, info.FullMethod, req)
+
+	var resp any
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	// Requests that were denied are audited too, as they may be an attack.
+	if isWrite(info.FullMethod) {
+		s.audit(id, info.FullMethod, req, err)
+	}
+	return resp, err
+}
+
+// streamInterceptor authorizes every streaming RPC once its request is received.
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &authStream{ServerStream: ss, s: s, method: info.FullMethod})
+}
+
+// authStream is a grpc.ServerStream that authorizes the request when it is received.
+// Our streaming RPCs all have a single request.
+type authStream struct {
+	grpc.ServerStream
+
+	s      *Server
+	method string
+	ctx    context.Context
+}
+
+func (a *authStream) Context() context.Context {
+	if a.ctx != nil {
+		return a.ctx
+	}
+	return a.ServerStream.Context()
+}
+
+func (a *authStream) RecvMsg(m any) error {
+	if err := a.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	ctx, _, err := a.s.authorize(a.ServerStream.Context(), a.method, m)
+	if err != nil {
+		return err
+	}
+	a.ctx = ctx
+	return nil
+}
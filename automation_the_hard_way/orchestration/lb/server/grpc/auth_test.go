@@ -0,0 +1,344 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+const (
+	methodPrefix = "/rollout.lb.LoadBalancer/"
+
+	adminToken  = "admin-token-0123456789"
+	webToken    = "web-token-0123456789ab"
+	readerToken = "reader-token-0123456789"
+)
+
+// testACL gives "admin" write access to everything, "web" write access to patterns
+// starting with "/web" and "reader" read access to everything.
+func testACL() *ACL {
+	return &ACL{
+		Rules: []ACLRule{
+			{Identities: []string{"admin"}, Patterns: []string{"*"}, Write: true},
+			{Identities: []string{"web"}, Patterns: []string{"/web*"}, Write: true},
+			{Identities: []string{"reader"}, Patterns: []string{"*"}},
+		},
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		desc  string
+		globs []string
+		s     string
+		want  bool
+	}{
+		{desc: "exact match", globs: []string{"/web"}, s: "/web", want: true},
+		{desc: "exact does not match prefix", globs: []string{"/web"}, s: "/web/api"},
+		{desc: "prefix match", globs: []string{"/web*"}, s: "/web/api", want: true},
+		{desc: "prefix does not match other", globs: []string{"/web*"}, s: "/api"},
+		{desc: "prefix never matches all pools", globs: []string{"/web*"}, s: "*"},
+		{desc: "empty prefix never matches all pools", globs: []string{"/*"}, s: "*"},
+		{desc: "star matches all pools", globs: []string{"*"}, s: "*", want: true},
+		{desc: "star matches anything", globs: []string{"*"}, s: "/api", want: true},
+		{desc: "any of the globs", globs: []string{"/api", "/web*"}, s: "/web", want: true},
+		{desc: "no globs", s: "/web"},
+	}
+
+	for _, test := range tests {
+		if got := matchAny(test.globs, test.s); got != test.want {
+			t.Errorf("TestMatchAny(%s): got %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestACLAuthorize(t *testing.T) {
+	acl := testACL()
+
+	tests := []struct {
+		desc     string
+		id       string
+		patterns []string
+		write    bool
+		wantErr  bool
+	}{
+		{desc: "admin writes a pool", id: "admin", patterns: []string{"/api"}, write: true},
+		{desc: "admin writes all pools", id: "admin", write: true},
+		{desc: "web writes its pool", id: "web", patterns: []string{"/web/api"}, write: true},
+		{desc: "web reads its pool", id: "web", patterns: []string{"/web"}},
+		{desc: "web writes another pool", id: "web", patterns: []string{"/api"}, write: true, wantErr: true},
+		{desc: "web writes its pool and another", id: "web", patterns: []string{"/web", "/api"}, write: true, wantErr: true},
+		{desc: "web request with no pattern needs *", id: "web", write: true, wantErr: true},
+		{desc: "web read with no pattern needs *", id: "web", wantErr: true},
+		{desc: "reader reads a pool", id: "reader", patterns: []string{"/api"}},
+		{desc: "reader reads all pools", id: "reader"},
+		{desc: "reader cannot write", id: "reader", patterns: []string{"/api"}, write: true, wantErr: true},
+		{desc: "unknown identity", id: "nobody", patterns: []string{"/api"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		err := acl.Authorize(Identity{Name: test.id, Method: "token"}, methodPrefix+"Test", test.patterns, test.write)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestACLAuthorize(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestACLAuthorize(%s): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
+
+func TestRequestPatterns(t *testing.T) {
+	tests := []struct {
+		desc string
+		req  any
+		want []string
+	}{
+		{desc: "pattern", req: &pb.RemovePoolReq{Pattern: "/web"}, want: []string{"/web"}},
+		{desc: "route uses its pool", req: &pb.AddRouteReq{Route: &pb.Route{Name: "r", Pool: "/web"}}, want: []string{"/web"}},
+		{desc: "config affects all pools", req: &pb.ApplyConfigReq{}},
+	}
+
+	for _, test := range tests {
+		got := requestPatterns(test.req)
+		if len(got) != len(test.want) || (len(got) == 1 && got[0] != test.want[0]) {
+			t.Errorf("TestRequestPatterns(%s): got %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestIsWrite(t *testing.T) {
+	for _, m := range []string{"PoolHealth", "WatchPoolHealth", "ExportConfig", "ListRoutes"} {
+		if isWrite(methodPrefix + m) {
+			t.Errorf("TestIsWrite(%s): got true, want false", m)
+		}
+	}
+	for _, m := range []string{"AddPool", "RemovePool", "AddBackend", "RemoveBackend", "DrainBackend", "SetBackendWeight", "ApplyConfig", "AddRoute", "RemoveRoute"} {
+		if !isWrite(methodPrefix + m) {
+			t.Errorf("TestIsWrite(%s): got false, want true", m)
+		}
+	}
+}
+
+// testServer returns a Server that authenticates with tokens, authorizes with testACL()
+// and audits to a file whose path is returned.
+func testServer(t *testing.T) (*Server, string) {
+	t.Helper()
+
+	ta, err := NewTokenAuth(map[string]string{"admin": adminToken, "web": webToken, "reader": readerToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "audit.log")
+	al, err := OpenAuditLog(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { al.Close() })
+
+	return &Server{Authenticator: ta, Authorizer: testACL(), Audit: al}, p
+}
+
+// tokenCtx returns an incoming context with tok as the bearer token. If tok is empty,
+// there is no token.
+func tokenCtx(tok string) context.Context {
+	if tok == "" {
+		return context.Background()
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tok))
+}
+
+// readAudit reads the entries in the audit log at p.
+func readAudit(t *testing.T, p string) []AuditEntry {
+	t.Helper()
+
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("audit log has bad entry(%s): %s", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		desc      string
+		tok       string
+		method    string
+		req       any
+		wantCode  codes.Code
+		wantAudit string // The Identity of the audit entry, empty if there shouldn't be one.
+	}{
+		{
+			desc:      "no token",
+			method:    "RemovePool",
+			req:       &pb.RemovePoolReq{Pattern: "/web"},
+			wantCode:  codes.Unauthenticated,
+			wantAudit: "unauthenticated",
+		},
+		{
+			desc:      "bad token",
+			tok:       "not-a-valid-token-at-all",
+			method:    "RemovePool",
+			req:       &pb.RemovePoolReq{Pattern: "/web"},
+			wantCode:  codes.Unauthenticated,
+			wantAudit: "unauthenticated",
+		},
+		{
+			desc:      "write denied",
+			tok:       webToken,
+			method:    "RemovePool",
+			req:       &pb.RemovePoolReq{Pattern: "/api"},
+			wantCode:  codes.PermissionDenied,
+			wantAudit: "token:web",
+		},
+		{
+			desc:      "write to all pools denied",
+			tok:       webToken,
+			method:    "ApplyConfig",
+			req:       &pb.ApplyConfigReq{},
+			wantCode:  codes.PermissionDenied,
+			wantAudit: "token:web",
+		},
+		{
+			desc:      "write allowed",
+			tok:       webToken,
+			method:    "RemovePool",
+			req:       &pb.RemovePoolReq{Pattern: "/web"},
+			wantAudit: "token:web",
+		},
+		{
+			desc:   "read allowed is not audited",
+			tok:    readerToken,
+			method: "PoolHealth",
+			req:    &pb.PoolHealthReq{Pattern: "/api"},
+		},
+		{
+			desc:     "read denied is not audited",
+			tok:      webToken,
+			method:   "PoolHealth",
+			req:      &pb.PoolHealthReq{Pattern: "/api"},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		s, p := testServer(t)
+
+		called := false
+		var gotID Identity
+		handler := func(ctx context.Context, req any) (any, error) {
+			called = true
+			gotID, _ = IdentityFromContext(ctx)
+			return nil, nil
+		}
+
+		_, err := s.unaryInterceptor(tokenCtx(test.tok), test.req, &grpc.UnaryServerInfo{FullMethod: methodPrefix + test.method}, handler)
+		if got := status.Code(err); got != test.wantCode {
+			t.Errorf("TestUnaryInterceptor(%s): got code %s, want %s", test.desc, got, test.wantCode)
+		}
+		if wantCalled := test.wantCode == codes.OK; called != wantCalled {
+			t.Errorf("TestUnaryInterceptor(%s): handler called == %v, want %v", test.desc, called, wantCalled)
+		}
+		if called && gotID.Name == "" {
+			t.Errorf("TestUnaryInterceptor(%s): handler's context had no Identity", test.desc)
+		}
+
+		entries := readAudit(t, p)
+		if test.wantAudit == "" {
+			if len(entries) != 0 {
+				t.Errorf("TestUnaryInterceptor(%s): got %d audit entries, want 0", test.desc, len(entries))
+			}
+			continue
+		}
+		if len(entries) != 1 {
+			t.Errorf("TestUnaryInterceptor(%s): got %d audit entries, want 1", test.desc, len(entries))
+			continue
+		}
+		e := entries[0]
+		if e.Identity != test.wantAudit || e.Method != test.method {
+			t.Errorf("TestUnaryInterceptor(%s): got audit entry for %s calling %s, want %s calling %s", test.desc, e.Identity, e.Method, test.wantAudit, test.method)
+		}
+		if gotErr := e.Error != ""; gotErr != (test.wantCode != codes.OK) {
+			t.Errorf("TestUnaryInterceptor(%s): got audit entry error %q, want error == %v", test.desc, e.Error, test.wantCode != codes.OK)
+		}
+	}
+}
+
+// fakeStream is a grpc.ServerStream that receives req.
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	req proto.Message
+}
+
+func (f *fakeStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), f.req)
+	return nil
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	tests := []struct {
+		desc     string
+		tok      string
+		pattern  string
+		wantCode codes.Code
+	}{
+		{desc: "no token", pattern: "/web", wantCode: codes.Unauthenticated},
+		{desc: "pattern denied", tok: webToken, pattern: "/api", wantCode: codes.PermissionDenied},
+		{desc: "pattern allowed", tok: webToken, pattern: "/web"},
+	}
+
+	for _, test := range tests {
+		s, _ := testServer(t)
+
+		stream := &fakeStream{ctx: tokenCtx(test.tok), req: &pb.WatchPoolHealthReq{Pattern: test.pattern}}
+		used := false
+		handler := func(srv any, ss grpc.ServerStream) error {
+			req := &pb.WatchPoolHealthReq{}
+			// Our handlers return when RecvMsg() fails, so they never use a request that
+			// isn't authorized.
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+			used = true
+			if _, ok := IdentityFromContext(ss.Context()); !ok {
+				t.Errorf("TestStreamInterceptor(%s): stream context had no Identity after RecvMsg()", test.desc)
+			}
+			return nil
+		}
+
+		err := s.streamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: methodPrefix + "WatchPoolHealth"}, handler)
+		if got := status.Code(err); got != test.wantCode {
+			t.Errorf("TestStreamInterceptor(%s): got code %s, want %s", test.desc, got, test.wantCode)
+		}
+		if wantUsed := test.wantCode == codes.OK; used != wantUsed {
+			t.Errorf("TestStreamInterceptor(%s): handler used request == %v, want %v", test.desc, used, wantUsed)
+		}
+	}
+}
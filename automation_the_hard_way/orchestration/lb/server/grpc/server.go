@@ -3,9 +3,13 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +17,11 @@ import (
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/http"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
@@ -21,27 +30,69 @@ import (
 type Server struct {
 	pb.UnimplementedLoadBalancerServer
 
+	// StateFile, if set, is where the load balancer's configuration is saved after every
+	// change. Use Load() with this file on startup to restore the configuration.
+	StateFile string
+	// Authenticator, if set, must authenticate every request. Otherwise anyone who can
+	// reach the server can use it.
+	Authenticator Authenticator
+	// Authorizer, if set, decides which requests each caller can make.
+	Authorizer Authorizer
+	// Audit, if set, records every request that tries to change the load balancer.
+	Audit *AuditLog
+
 	addr       string
 	lb         *http.LoadBalancer
 	grpcServer *grpc.Server
 
 	mu sync.Mutex
-}
 
-// New creates a new instance of Server.
-func New(addr string, lb *http.LoadBalancer) (*Server, error) {
-	var opts []grpc.ServerOption
+	// configMu protects pools and serializes changes to the load balancer, so that what
+	// we save is consistent.
+	configMu sync.Mutex
+	pools    map[string]*pb.PoolConfig // The pool configs without backends, keyed by pattern.
+}
 
+// New creates a new instance of Server. Without options, the server has no security. Use
+// TLSCreds() to serve with TLS and require client certificates and set the Authenticator
+// and Authorizer to control who can do what.
+func New(addr string, lb *http.LoadBalancer, opts ...grpc.ServerOption) (*Server, error) {
 	s := &Server{
-		addr:       addr,
-		lb:         lb,
-		grpcServer: grpc.NewServer(opts...),
+		addr:  addr,
+		lb:    lb,
+		pools: map[string]*pb.PoolConfig{},
 	}
+	opts = append(
+		opts,
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	s.grpcServer = grpc.NewServer(opts...)
 	s.grpcServer.RegisterService(&pb.LoadBalancer_ServiceDesc, s)
 
 	return s, nil
 }
 
+// TLSCreds returns a grpc.ServerOption that serves TLS with the certificates in cs and
+// requires clients to send a certificate signed by a CA in caFile. Certificates in cs
+// are reloaded when their files change.
+func TLSCreds(cs *http.CertStore, caFile string) (grpc.ServerOption, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("caFile(%s) had no certificates", caFile)
+	}
+
+	tc := cs.TLSConfig()
+	tc.NextProtos = nil
+	tc.ClientCAs = pool
+	tc.ClientAuth = tls.RequireAndVerifyClientCert
+	return grpc.Creds(credentials.NewTLS(tc)), nil
+}
+
 // Start starts the server and blocks.
 func (s *Server) Start() error {
 	s.mu.Lock()
@@ -70,16 +121,49 @@ func (s *Server) AddPool(ctx context.Context, req *pb.AddPoolReq) (*pb.AddPoolRe
 		return nil, fmt.Errorf("pattern must not be empty")
 	}
 
-	if req.PoolType == pb.PoolType_PT_UNKNOWN {
+	pc := &pb.PoolConfig{
+		Pattern:          req.Pattern,
+		PoolType:         req.PoolType,
+		HealthChecks:     req.HealthChecks,
+		HashKey:          req.HashKey,
+		OutlierDetection: req.OutlierDetection,
+		RouteOnly:        req.RouteOnly,
+		RetryPolicy:      req.RetryPolicy,
+		BackendTls:       req.BackendTls,
+	}
+	pool, err := newPool(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	add := s.lb.AddPool
+	if req.RouteOnly {
+		add = s.lb.AddRouteOnlyPool
+	}
+	if err := add(req.Pattern, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	s.pools[req.Pattern] = pc
+	s.save(ctx)
+	return &pb.AddPoolResp{}, nil
+}
+
+// newPool creates the pool described by pc. Backends in pc are not added.
+func newPool(pc *pb.PoolConfig) (http.Pool, error) {
+	if pc.PoolType == pb.PoolType_PT_UNKNOWN {
 		return nil, fmt.Errorf("must set a pool_type")
 	}
 
-	if len(req.HealthChecks.HealthChecks) == 0 {
+	if len(pc.HealthChecks.GetHealthChecks()) == 0 {
 		return nil, fmt.Errorf("must have at least 1 health_check")
 	}
 
 	var hcs []http.HealthCheck
-	for _, hc := range req.HealthChecks.HealthChecks {
+	for _, hc := range pc.HealthChecks.GetHealthChecks() {
 		switch {
 		case hc.GetStatusCheck() != nil:
 			scr := hc.GetStatusCheck()
@@ -88,15 +172,38 @@ func (s *Server) AddPool(ctx context.Context, req *pb.AddPoolReq) (*pb.AddPoolRe
 				return nil, err
 			}
 			hcs = append(hcs, sc)
+		case hc.GetTcpCheck() != nil:
+			hcs = append(hcs, http.TCPCheck())
+		case hc.GetGrpcCheck() != nil:
+			gcr := hc.GetGrpcCheck()
+			gc, err := http.GRPCCheck(gcr.Port, gcr.Service)
+			if err != nil {
+				return nil, err
+			}
+			hcs = append(hcs, gc)
+		case hc.GetHttpCodeCheck() != nil:
+			ccr := hc.GetHttpCodeCheck()
+			cc, err := http.HTTPCodeCheck(ccr.UrlPath, ccr.MinCode, ccr.MaxCode)
+			if err != nil {
+				return nil, err
+			}
+			hcs = append(hcs, cc)
+		case hc.GetLatencyCheck() != nil:
+			lcr := hc.GetLatencyCheck()
+			lc, err := http.LatencyCheck(lcr.UrlPath, time.Duration(lcr.MaxLatencyMs)*time.Millisecond)
+			if err != nil {
+				return nil, err
+			}
+			hcs = append(hcs, lc)
 		default:
 			return nil, fmt.Errorf("a health_check is missing its concrete type")
 		}
 	}
-	interval := time.Duration(req.HealthChecks.IntervalSecs) * time.Second
+	interval := time.Duration(pc.HealthChecks.GetIntervalSecs()) * time.Second
 
 	var pool http.Pool
 
-	switch req.PoolType {
+	switch pc.PoolType {
 	case pb.PoolType_PT_P2C:
 		var err error
 		pool, err = http.NewP2C(
@@ -106,14 +213,97 @@ func (s *Server) AddPool(ctx context.Context, req *pb.AddPoolReq) (*pb.AddPoolRe
 		if err != nil {
 			return nil, err
 		}
+	case pb.PoolType_PT_WEIGHTED:
+		var err error
+		pool, err = http.NewWeighted(
+			http.HealthMultiplexer(hcs...),
+			interval,
+		)
+		if err != nil {
+			return nil, err
+		}
+	case pb.PoolType_PT_ROUND_ROBIN:
+		var err error
+		pool, err = http.NewRoundRobin(
+			http.HealthMultiplexer(hcs...),
+			interval,
+		)
+		if err != nil {
+			return nil, err
+		}
+	case pb.PoolType_PT_LEAST_CONN:
+		var err error
+		pool, err = http.NewLeastConn(
+			http.HealthMultiplexer(hcs...),
+			interval,
+		)
+		if err != nil {
+			return nil, err
+		}
+	case pb.PoolType_PT_CONSISTENT_HASH:
+		if pc.HashKey == nil {
+			return nil, fmt.Errorf("pool_type PT_CONSISTENT_HASH must have a hash_key")
+		}
+		var err error
+		pool, err = http.NewConsistentHash(
+			http.HashKey{Header: pc.HashKey.GetHeader(), Cookie: pc.HashKey.GetCookie()},
+			http.HealthMultiplexer(hcs...),
+			interval,
+		)
+		if err != nil {
+			return nil, err
+		}
 	default:
-		return nil, fmt.Errorf("unknown pool_type(%v", req.PoolType)
+		return nil, fmt.Errorf("unknown pool_type(%v)", pc.PoolType)
 	}
 
-	if err := s.lb.AddPool(req.Pattern, pool); err != nil {
+	if err := pool.SetThresholds(pc.HealthChecks.GetRise(), pc.HealthChecks.GetFall()); err != nil {
+		pool.Close()
 		return nil, err
 	}
-	return &pb.AddPoolResp{}, nil
+	if od := pc.OutlierDetection; od != nil {
+		err := pool.SetOutlierDetection(
+			http.OutlierDetection{
+				ErrorRate:          od.ErrorRate,
+				MinRequests:        od.MinRequests,
+				Interval:           time.Duration(od.IntervalSecs) * time.Second,
+				BaseEjection:       time.Duration(od.BaseEjectionSecs) * time.Second,
+				MaxEjectionPercent: od.MaxEjectionPercent,
+			},
+		)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	if bt := pc.BackendTls; bt != nil {
+		err := pool.SetBackendTLS(
+			http.BackendTLS{
+				CAFile:     bt.CaFile,
+				CertFile:   bt.CertFile,
+				KeyFile:    bt.KeyFile,
+				ServerName: bt.ServerName,
+			},
+		)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	if rp := pc.RetryPolicy; rp != nil {
+		err := pool.SetRetryPolicy(
+			http.RetryPolicy{
+				MaxRetries:    rp.MaxRetries,
+				PerTryTimeout: time.Duration(rp.PerTryTimeoutMs) * time.Millisecond,
+				Budget:        rp.Budget,
+			},
+		)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	return pool, nil
 }
 
 // RemovePool removes a pool as defined in req.
@@ -121,9 +311,15 @@ func (s *Server) RemovePool(ctx context.Context, req *pb.RemovePoolReq) (*pb.Rem
 	if strings.TrimSpace(req.Pattern) == "" {
 		return nil, fmt.Errorf("pattern must not be empty")
 	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
 	if err := s.lb.RemovePool(req.Pattern); err != nil {
 		return nil, err
 	}
+	delete(s.pools, req.Pattern)
+	s.save(ctx)
 	return &pb.RemovePoolResp{}, nil
 }
 
@@ -158,9 +354,25 @@ func (s *Server) AddBackend(ctx context.Context, req *pb.AddBackendReq) (*pb.Add
 	default:
 		return nil, fmt.Errorf("a backend is missing its concrete type")
 	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if req.Weight != 0 {
+		wp, ok := pool.(http.WeightedPool)
+		if !ok {
+			return nil, fmt.Errorf("pool(%s) is not a weighted pool, cannot set weight", req.Pattern)
+		}
+		if err := wp.AddWeighted(ctx, back, req.Weight); err != nil {
+			return nil, err
+		}
+		s.save(ctx)
+		return &pb.AddBackendResp{}, nil
+	}
 	if err := pool.Add(ctx, back); err != nil {
 		return nil, err
 	}
+	s.save(ctx)
 	return &pb.AddBackendResp{}, nil
 }
 
@@ -196,9 +408,13 @@ func (s *Server) RemoveBackend(ctx context.Context, req *pb.RemoveBackendReq) (*
 		return nil, err
 	}
 
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
 	if err := pool.Remove(ctx, back); err != nil {
 		return nil, err
 	}
+	s.save(ctx)
 	return &pb.RemoveBackendResp{}, nil
 }
 
@@ -212,3 +428,411 @@ func (s *Server) PoolHealth(ctx context.Context, req *pb.PoolHealthReq) (*pb.Poo
 
 	return &pb.PoolHealthResp{Health: ph}, nil
 }
+
+// WatchPoolHealth streams the health of the pool in req. The pool's health is sent first,
+// followed by an event for every change to its backends.
+func (s *Server) WatchPoolHealth(req *pb.WatchPoolHealthReq, stream pb.LoadBalancer_WatchPoolHealthServer) error {
+	log.Println("watching pool health")
+	pool, err := s.lb.GetPool(req.Pattern)
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+
+	// We start watching before getting the health so that no changes are missed.
+	events := pool.Watch(ctx)
+
+	ph, err := pool.Health(ctx, &pb.PoolHealthReq{Pattern: req.Pattern, Healthy: true, Sick: true})
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.WatchPoolHealthResp{Msg: &pb.WatchPoolHealthResp_Health{Health: ph}}); err != nil {
+		return err
+	}
+
+	for ev := range events {
+		ev.Pattern = req.Pattern
+		if err := stream.Send(&pb.WatchPoolHealthResp{Msg: &pb.WatchPoolHealthResp_Event{Event: ev}}); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return status.Errorf(codes.Unavailable, "pool(%s) stopped sending events, it was removed or the watcher fell behind", req.Pattern)
+}
+
+// DrainBackend drains a backend as defined in req.
+func (s *Server) DrainBackend(ctx context.Context, req *pb.DrainBackendReq) (*pb.DrainBackendResp, error) {
+	log.Println("draining backend")
+	if strings.TrimSpace(req.Pattern) == "" {
+		return nil, fmt.Errorf("pattern must not be empty")
+	}
+	if req.TimeoutSecs < 0 {
+		return nil, fmt.Errorf("timeout_secs cannot be negative")
+	}
+
+	var back http.Backend
+
+	switch {
+	case req.Backend.GetIpBackend() != nil:
+		v := req.Backend.GetIpBackend()
+		ip := net.ParseIP(v.Ip)
+		if ip == nil {
+			return nil, fmt.Errorf("backend ip is invalid")
+		}
+		if v.Port < 1 || v.Port > 65534 {
+			return nil, fmt.Errorf("port is invalid")
+		}
+		b, err := http.NewIPBackend(ip, v.Port, v.UrlPath)
+		if err != nil {
+			return nil, err
+		}
+		back = b
+	default:
+		return nil, fmt.Errorf("a backend is missing its concrete type")
+	}
+
+	pool, err := s.lb.GetPool(req.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 30 * time.Second
+	if req.TimeoutSecs > 0 {
+		timeout = time.Duration(req.TimeoutSecs) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	inFlight, err := pool.Drain(ctx, back)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DrainBackendResp{InFlight: inFlight}, nil
+}
+
+// SetBackendWeight sets the weight of a backend as defined in req.
+func (s *Server) SetBackendWeight(ctx context.Context, req *pb.SetBackendWeightReq) (*pb.SetBackendWeightResp, error) {
+	log.Println("setting backend weight")
+	if strings.TrimSpace(req.Pattern) == "" {
+		return nil, fmt.Errorf("pattern must not be empty")
+	}
+
+	var back http.Backend
+
+	switch {
+	case req.Backend.GetIpBackend() != nil:
+		v := req.Backend.GetIpBackend()
+		ip := net.ParseIP(v.Ip)
+		if ip == nil {
+			return nil, fmt.Errorf("backend ip is invalid")
+		}
+		if v.Port < 1 || v.Port > 65534 {
+			return nil, fmt.Errorf("port is invalid")
+		}
+		b, err := http.NewIPBackend(ip, v.Port, v.UrlPath)
+		if err != nil {
+			return nil, err
+		}
+		back = b
+	default:
+		return nil, fmt.Errorf("a backend is missing its concrete type")
+	}
+
+	pool, err := s.lb.GetPool(req.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	wp, ok := pool.(http.WeightedPool)
+	if !ok {
+		return nil, fmt.Errorf("pool(%s) is not a weighted pool", req.Pattern)
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if err := wp.SetWeight(ctx, back, req.Weight); err != nil {
+		return nil, err
+	}
+	s.save(ctx)
+	return &pb.SetBackendWeightResp{}, nil
+}
+
+// ExportConfig returns the configuration of all pools and their backends.
+func (s *Server) ExportConfig(ctx context.Context, req *pb.ExportConfigReq) (*pb.ExportConfigResp, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	config, err := s.export(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ExportConfigResp{Config: config}, nil
+}
+
+// export returns the current configuration. configMu must be held.
+func (s *Server) export(ctx context.Context) (*pb.LBConfig, error) {
+	patterns := make([]string, 0, len(s.pools))
+	for p := range s.pools {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	config := &pb.LBConfig{}
+	for _, r := range s.lb.Routes() {
+		config.Routes = append(config.Routes, routeToPB(r))
+	}
+	for _, p := range patterns {
+		ph, err := s.lb.PoolHealth(ctx, &pb.PoolHealthReq{Pattern: p, Healthy: true, Sick: true})
+		if err != nil {
+			return nil, fmt.Errorf("could not get backends for pool(%s): %w", p, err)
+		}
+
+		pc := proto.Clone(s.pools[p]).(*pb.PoolConfig)
+		for _, bh := range ph.Backends {
+			// A draining backend is being taken out of service.
+			if bh.Status == pb.BackendStatus_BS_DRAINING {
+				continue
+			}
+			pc.Backends = append(pc.Backends, &pb.BackendConfig{Backend: bh.Backend, Weight: bh.Weight})
+		}
+		config.Pools = append(config.Pools, pc)
+	}
+	return config, nil
+}
+
+// ApplyConfig replaces all pools and routes with the ones in req. The new pools and their backends
+// are all created before they replace the existing pools. If any of them fail, nothing
+// is changed. Backends that fail their health checks are added as sick.
+func (s *Server) ApplyConfig(ctx context.Context, req *pb.ApplyConfigReq) (*pb.ApplyConfigResp, error) {
+	log.Println("applying config")
+	if req.Config == nil {
+		return nil, fmt.Errorf("config must be set")
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	pools := map[string]http.Pool{}
+	configs := map[string]*pb.PoolConfig{}
+	routeOnly := map[string]bool{}
+
+	closeAll := func() {
+		for _, p := range pools {
+			p.Close()
+		}
+	}
+
+	for _, pc := range req.Config.GetPools() {
+		if err := s.applyPool(ctx, pc, pools, configs); err != nil {
+			closeAll()
+			return nil, fmt.Errorf("pool(%s): %w", pc.Pattern, err)
+		}
+		if pc.RouteOnly {
+			routeOnly[pc.Pattern] = true
+		}
+	}
+
+	routes := make([]http.Route, 0, len(req.Config.GetRoutes()))
+	for _, r := range req.Config.GetRoutes() {
+		routes = append(routes, routeFromPB(r))
+	}
+
+	if err := s.lb.ReplacePools(pools, routeOnly, routes); err != nil {
+		closeAll()
+		return nil, err
+	}
+	s.pools = configs
+	s.save(ctx)
+	return &pb.ApplyConfigResp{}, nil
+}
+
+// applyPool creates the pool and backends in pc and adds them to pools and configs.
+func (s *Server) applyPool(ctx context.Context, pc *pb.PoolConfig, pools map[string]http.Pool, configs map[string]*pb.PoolConfig) error {
+	if strings.TrimSpace(pc.Pattern) == "" {
+		return fmt.Errorf("pattern must not be empty")
+	}
+	if _, ok := pools[pc.Pattern]; ok {
+		return fmt.Errorf("pattern is listed more than once")
+	}
+
+	pool, err := newPool(pc)
+	if err != nil {
+		return err
+	}
+	pools[pc.Pattern] = pool
+
+	for i, bc := range pc.Backends {
+		back, err := backend(bc.Backend)
+		if err != nil {
+			return fmt.Errorf("backends[%d]: %w", i, err)
+		}
+		if err := pool.Restore(ctx, back); err != nil {
+			return fmt.Errorf("backends[%d]: %w", i, err)
+		}
+		if bc.Weight == 0 {
+			continue
+		}
+		wp, ok := pool.(http.WeightedPool)
+		if !ok {
+			return fmt.Errorf("pool is not a weighted pool, cannot set weight")
+		}
+		if err := wp.SetWeight(ctx, back, bc.Weight); err != nil {
+			return err
+		}
+	}
+
+	c := proto.Clone(pc).(*pb.PoolConfig)
+	c.Backends = nil
+	configs[pc.Pattern] = c
+	return nil
+}
+
+// AddRoute adds the route in req.
+func (s *Server) AddRoute(ctx context.Context, req *pb.AddRouteReq) (*pb.AddRouteResp, error) {
+	log.Println("adding route")
+	if req.Route == nil {
+		return nil, fmt.Errorf("route must be set")
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if err := s.lb.AddRoute(routeFromPB(req.Route)); err != nil {
+		return nil, err
+	}
+	s.save(ctx)
+	return &pb.AddRouteResp{}, nil
+}
+
+// RemoveRoute removes the route named in req.
+func (s *Server) RemoveRoute(ctx context.Context, req *pb.RemoveRouteReq) (*pb.RemoveRouteResp, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name must not be empty")
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if err := s.lb.RemoveRoute(req.Name); err != nil {
+		return nil, err
+	}
+	s.save(ctx)
+	return &pb.RemoveRouteResp{}, nil
+}
+
+// ListRoutes returns all routes in the order they are checked.
+func (s *Server) ListRoutes(ctx context.Context, req *pb.ListRoutesReq) (*pb.ListRoutesResp, error) {
+	resp := &pb.ListRoutesResp{}
+	for _, r := range s.lb.Routes() {
+		resp.Routes = append(resp.Routes, routeToPB(r))
+	}
+	return resp, nil
+}
+
+// routeFromPB converts a *pb.Route to an http.Route.
+func routeFromPB(r *pb.Route) http.Route {
+	var headers map[string]string
+	if len(r.Headers) > 0 {
+		headers = make(map[string]string, len(r.Headers))
+		for _, h := range r.Headers {
+			headers[h.Name] = h.Value
+		}
+	}
+	return http.Route{
+		Name:       r.Name,
+		Priority:   r.Priority,
+		Host:       r.Host,
+		PathPrefix: r.PathPrefix,
+		Headers:    headers,
+		Methods:    r.Methods,
+		Pool:       r.Pool,
+	}
+}
+
+// routeToPB converts an http.Route to a *pb.Route.
+func routeToPB(r http.Route) *pb.Route {
+	names := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pr := &pb.Route{
+		Name:       r.Name,
+		Priority:   r.Priority,
+		Host:       r.Host,
+		PathPrefix: r.PathPrefix,
+		Methods:    r.Methods,
+		Pool:       r.Pool,
+	}
+	for _, k := range names {
+		pr.Headers = append(pr.Headers, &pb.HeaderMatch{Name: k, Value: r.Headers[k]})
+	}
+	return pr
+}
+
+// backend converts a *pb.Backend to an http.Backend.
+func backend(b *pb.Backend) (http.Backend, error) {
+	switch {
+	case b.GetIpBackend() != nil:
+		v := b.GetIpBackend()
+		ip := net.ParseIP(v.Ip)
+		if ip == nil {
+			return nil, fmt.Errorf("backend ip is invalid")
+		}
+		if v.Port < 1 || v.Port > 65534 {
+			return nil, fmt.Errorf("port is invalid")
+		}
+		return http.NewIPBackend(ip, v.Port, v.UrlPath)
+	}
+	return nil, fmt.Errorf("a backend is missing its concrete type")
+}
+
+// Load reads a configuration written to StateFile, or a hand written configuration in the
+// same format, and applies it with ApplyConfig(). This should be called before Start().
+func (s *Server) Load(ctx context.Context, p string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+
+	config := &pb.LBConfig{}
+	if err := protojson.Unmarshal(b, config); err != nil {
+		return fmt.Errorf("config file(%s) is invalid: %w", p, err)
+	}
+
+	if _, err := s.ApplyConfig(ctx, &pb.ApplyConfigReq{Config: config}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// save writes the current configuration to StateFile, if it is set. The file is replaced
+// atomically so that a crash doesn't leave a partial file. configMu must be held.
+func (s *Server) save(ctx context.Context) {
+	if s.StateFile == "" {
+		return
+	}
+
+	config, err := s.export(ctx)
+	if err != nil {
+		log.Printf("could not export config to save: %s", err)
+		return
+	}
+	b, err := protojson.MarshalOptions{Multiline: true}.Marshal(config)
+	if err != nil {
+		log.Printf("could not marshal config to save: %s", err)
+		return
+	}
+
+	tmp := s.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, b, 0640); err != nil {
+		log.Printf("could not save config: %s", err)
+		return
+	}
+	if err := os.Rename(tmp, s.StateFile); err != nil {
+		log.Printf("could not save config: %s", err)
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkTimeout is how long a health check has to complete, unless the check has its own.
+const checkTimeout = 1 * time.Second
+
+// TCPCheck returns a HealthCheck that checks that a TCP connection can be made to the backend.
+func TCPCheck() HealthCheck {
+	return func(ctx context.Context, endpoint string) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", u.Host)
+		if err != nil {
+			return fmt.Errorf("not healthy, could not connect: %w", err)
+		}
+		return conn.Close()
+	}
+}
+
+// GRPCCheck returns a HealthCheck that uses the grpc.health.v1.Health service on the backend.
+// port is the port the gRPC service is on, if 0 this is the backend's port. service is the
+// service to check, if empty the overall health of the server is checked.
+func GRPCCheck(port int32, service string) (HealthCheck, error) {
+	if port < 0 || port > 65535 {
+		return nil, fmt.Errorf("port(%d) is invalid", port)
+	}
+	return func(ctx context.Context, endpoint string) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		addr := u.Host
+		if port != 0 {
+			addr = net.JoinHostPort(u.Hostname(), strconv.Itoa(int(port)))
+		}
+
+		creds := insecure.NewCredentials()
+		if bt := getBackendTLS(ctx); bt != nil {
+			creds = credentials.NewTLS(bt.config)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+
+		conn, err := grpc.DialContext(
+			ctx,
+			addr,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithBlock(),
+		)
+		if err != nil {
+			return fmt.Errorf("not healthy, could not connect: %w", err)
+		}
+		defer conn.Close()
+
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return fmt.Errorf("not healthy, health check failed: %w", err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("not healthy, got status(%s)", resp.Status)
+		}
+		return nil
+	}, nil
+}
+
+// HTTPCodeCheck returns a HealthCheck that checks the status code of a GET to urlPath
+// is between minCode and maxCode, inclusive. If minCode is 0, it is 200. If maxCode is 0,
+// it is 399.
+func HTTPCodeCheck(urlPath string, minCode, maxCode int32) (HealthCheck, error) {
+	if minCode == 0 {
+		minCode = 200
+	}
+	if maxCode == 0 {
+		maxCode = 399
+	}
+	if minCode < 100 || maxCode > 599 || minCode > maxCode {
+		return nil, fmt.Errorf("status code range %d-%d is invalid", minCode, maxCode)
+	}
+	return func(ctx context.Context, endpoint string) error {
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+
+		resp, err := get(ctx, endpoint, urlPath)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if int32(resp.StatusCode) < minCode || int32(resp.StatusCode) > maxCode {
+			return fmt.Errorf("not healthy, got status code(%d)", resp.StatusCode)
+		}
+		return nil
+	}, nil
+}
+
+// LatencyCheck returns a HealthCheck that checks that a GET to urlPath returns its full
+// response within maxLatency. A 5xx status code also fails the check.
+func LatencyCheck(urlPath string, maxLatency time.Duration) (HealthCheck, error) {
+	if maxLatency <= 0 {
+		return nil, fmt.Errorf("max latency must be greater than 0")
+	}
+	return func(ctx context.Context, endpoint string) error {
+		ctx, cancel := context.WithTimeout(ctx, maxLatency)
+		defer cancel()
+
+		start := time.Now()
+		resp, err := get(ctx, endpoint, urlPath)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			return fmt.Errorf("not healthy, response took longer than %v: %w", maxLatency, err)
+		}
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("not healthy, got status code(%d)", resp.StatusCode)
+		}
+		if d := time.Since(start); d > maxLatency {
+			return fmt.Errorf("not healthy, response took %v", d)
+		}
+		return nil
+	}, nil
+}
+
+// get does a GET to urlPath on the backend at endpoint.
+func get(ctx context.Context, endpoint, urlPath string) (*http.Response, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	scheme, c := checkClient(ctx)
+	u.Scheme = scheme
+	u.Path = urlPath
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
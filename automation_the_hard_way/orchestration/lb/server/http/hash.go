@@ -0,0 +1,105 @@
+package http
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HashKey is the part of a request that a ConsistentHash pool hashes. Only one
+// of Header or Cookie may be set.
+type HashKey struct {
+	// Header is the name of a header whose value is hashed.
+	Header string
+	// Cookie is the name of a cookie whose value is hashed.
+	Cookie string
+}
+
+func (h HashKey) validate() error {
+	switch {
+	case strings.TrimSpace(h.Header) == "" && strings.TrimSpace(h.Cookie) == "":
+		return fmt.Errorf("HashKey must have a Header or Cookie")
+	case h.Header != "" && h.Cookie != "":
+		return fmt.Errorf("HashKey cannot have both a Header and Cookie")
+	}
+	return nil
+}
+
+// key returns the value to hash for r. If r doesn't have the key, this is
+// the client's IP.
+func (h HashKey) key(r *http.Request) string {
+	switch {
+	case h.Header != "":
+		if v := r.Header.Get(h.Header); v != "" {
+			return v
+		}
+	case h.Cookie != "":
+		if c, err := r.Cookie(h.Cookie); err == nil && c.Value != "" {
+			return c.Value
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ConsistentHash implements Pool by hashing a key from each request to choose a
+// backend. Requests with the same key go to the same backend for as long as it is
+// healthy, which gives session affinity.
+//
+// This uses rendezvous hashing: each healthy backend is scored by hashing the key
+// with the backend's url and the highest score wins. When a backend leaves or
+// joins the healthy list, only the keys that map to that backend move.
+// Health checking and draining work the same as P2C.
+type ConsistentHash struct {
+	*P2C
+
+	hk HashKey
+}
+
+// NewConsistentHash creates a new ConsistentHash instance. hk is what to hash in
+// each request. hc is the health check to perform on the backend to make sure its
+// healthy and interval is how often to do the health check.
+func NewConsistentHash(hk HashKey, hc HealthCheck, interval time.Duration) (*ConsistentHash, error) {
+	if err := hk.validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := NewP2C(hc, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsistentHash{P2C: p, hk: hk}, nil
+}
+
+// ServeHTTP implements Pool.ServeHTTP().
+func (c *ConsistentHash) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, c.pick)
+}
+
+// pick implements picker. When a request is retried, the tried backends are not in
+// backs, so the backend with the next highest score is picked.
+func (c *ConsistentHash) pick(backs []*weightedBackend, r *http.Request) *weightedBackend {
+
+	key := c.hk.key(r)
+
+	var (
+		chosen *weightedBackend
+		best   uint64
+	)
+	for _, b := range backs {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte(b.url().String()))
+		if score := h.Sum64(); chosen == nil || score > best {
+			chosen, best = b, score
+		}
+	}
+	return chosen
+}
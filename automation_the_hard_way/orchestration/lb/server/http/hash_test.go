@@ -0,0 +1,119 @@
+package http
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newHashPicker returns the picker of a ConsistentHash pool that hashes the X-User header.
+func newHashPicker(t *testing.T) picker {
+	t.Helper()
+
+	c, err := NewConsistentHash(HashKey{Header: "X-User"}, passCheck, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c.pick
+}
+
+// userPicks returns the backend pick chooses for each of n users.
+func userPicks(pick picker, backs []*weightedBackend, n int) []*weightedBackend {
+	picks := make([]*weightedBackend, n)
+	for i := range picks {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-User", fmt.Sprintf("user%d", i))
+		picks[i] = pick(backs, r)
+	}
+	return picks
+}
+
+func TestConsistentHashPick(t *testing.T) {
+	const users = 1000
+
+	pick := newHashPicker(t)
+	var backs []*weightedBackend
+	for i := 0; i < 4; i++ {
+		backs = append(backs, &weightedBackend{Backend: newBackend(t, int32(8000+i))})
+	}
+
+	first := userPicks(pick, backs, users)
+
+	// Every backend gets some of the users.
+	counts := map[*weightedBackend]int{}
+	for _, b := range first {
+		counts[b]++
+	}
+	for i, b := range backs {
+		if counts[b] < users/10 {
+			t.Errorf("TestConsistentHashPick: backend %d got %d of %d users, want at least %d", i, counts[b], users, users/10)
+		}
+	}
+
+	// Users stick to their backend.
+	again := userPicks(pick, backs, users)
+	for i := range first {
+		if first[i] != again[i] {
+			t.Fatalf("TestConsistentHashPick: user%d moved backends with no change to the pool", i)
+		}
+	}
+
+	// When a backend leaves, only its users move.
+	gone := backs[1]
+	left := []*weightedBackend{backs[0], backs[2], backs[3]}
+	after := userPicks(pick, left, users)
+	for i := range first {
+		if first[i] != gone && after[i] != first[i] {
+			t.Errorf("TestConsistentHashPick: user%d moved when a backend it didn't use left", i)
+		}
+	}
+}
+
+func TestHashKey(t *testing.T) {
+	tests := []struct {
+		desc   string
+		hk     HashKey
+		header string
+		cookie string
+		want   string
+	}{
+		{
+			desc:   "header",
+			hk:     HashKey{Header: "X-User"},
+			header: "alice",
+			want:   "alice",
+		},
+		{
+			desc:   "cookie",
+			hk:     HashKey{Cookie: "session"},
+			cookie: "abc",
+			want:   "abc",
+		},
+		{
+			desc: "missing header uses the client IP",
+			hk:   HashKey{Header: "X-User"},
+			want: "192.0.2.1",
+		},
+		{
+			desc: "missing cookie uses the client IP",
+			hk:   HashKey{Cookie: "session"},
+			want: "192.0.2.1",
+		},
+	}
+
+	for _, test := range tests {
+		// httptest requests come from 192.0.2.1:1234.
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("X-User", test.header)
+		}
+		if test.cookie != "" {
+			r.Header.Set("Cookie", "session="+test.cookie)
+		}
+		if got := test.hk.key(r); got != test.want {
+			t.Errorf("TestHashKey(%s): got %q, want %q", test.desc, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// LeastConn implements Pool by sending each request to the healthy backend with
+// the fewest in-flight requests. Ties are broken at random. Health checking and
+// draining work the same as P2C.
+type LeastConn struct {
+	*P2C
+}
+
+// NewLeastConn creates a new LeastConn instance. hc is the health check
+// to perform on the backend to make sure its healthy and interval is how often to do
+// the health check.
+func NewLeastConn(hc HealthCheck, interval time.Duration) (*LeastConn, error) {
+	p, err := NewP2C(hc, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &LeastConn{P2C: p}, nil
+}
+
+// ServeHTTP implements Pool.ServeHTTP().
+func (l *LeastConn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.serve(w, r, l.pick)
+}
+
+// pick implements picker.
+func (l *LeastConn) pick(backs []*weightedBackend, r *http.Request) *weightedBackend {
+	var (
+		least  *weightedBackend
+		leastN int32
+		ties   int
+	)
+	for _, b := range backs {
+		n := b.get()
+		switch {
+		case least == nil || n < leastN:
+			least, leastN, ties = b, n, 1
+		case n == leastN:
+			// Replacing the pick with a 1 in ties chance gives each of the tied backends
+			// the same chance of being picked.
+			ties++
+			if rand.Intn(ties) == 0 {
+				least = b
+			}
+		}
+	}
+	return least
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// OutlierDetection configures passive health checking, where backends whose live traffic
+// has too many errors are ejected from service. A request is an error if the backend can't
+// be reached or it returns a 5xx status code. An ejected backend is put on the sick list
+// for BaseEjection times the number of times in a row it has been ejected. After that it
+// returns to service once it passes its health checks.
+type OutlierDetection struct {
+	// ErrorRate is the fraction of requests, greater than 0 and at most 1, that must fail
+	// in an Interval for a backend to be ejected.
+	ErrorRate float64
+	// MinRequests is the number of requests a backend must receive in an Interval before
+	// it can be ejected. Defaults to 10.
+	MinRequests int32
+	// Interval is how often the error rates are checked. Defaults to 10 seconds.
+	Interval time.Duration
+	// BaseEjection is how long a backend is ejected for the first time. Defaults to 30 seconds.
+	BaseEjection time.Duration
+	// MaxEjectionPercent is the maximum percentage of the pool's backends that can be
+	// ejected at a time, rounded down. Defaults to 50.
+	MaxEjectionPercent int32
+}
+
+func (o *OutlierDetection) defaults() {
+	if o.MinRequests == 0 {
+		o.MinRequests = 10
+	}
+	if o.Interval == 0 {
+		o.Interval = 10 * time.Second
+	}
+	if o.BaseEjection == 0 {
+		o.BaseEjection = 30 * time.Second
+	}
+	if o.MaxEjectionPercent == 0 {
+		o.MaxEjectionPercent = 50
+	}
+}
+
+func (o OutlierDetection) validate() error {
+	if o.ErrorRate <= 0 || o.ErrorRate > 1 {
+		return fmt.Errorf("ErrorRate(%v) must be greater than 0 and at most 1", o.ErrorRate)
+	}
+	if o.MinRequests < 0 {
+		return fmt.Errorf("MinRequests(%d) is invalid", o.MinRequests)
+	}
+	if o.Interval < 0 || o.BaseEjection < 0 {
+		return fmt.Errorf("Interval and BaseEjection cannot be negative")
+	}
+	if o.MaxEjectionPercent < 0 || o.MaxEjectionPercent > 100 {
+		return fmt.Errorf("MaxEjectionPercent(%d) is invalid", o.MaxEjectionPercent)
+	}
+	return nil
+}
+
+// SetOutlierDetection implements Pool.SetOutlierDetection().
+func (s *P2C) SetOutlierDetection(od OutlierDetection) error {
+	od.defaults()
+	if err := od.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.od != nil {
+		return fmt.Errorf("outlier detection is already set")
+	}
+	s.od = &od
+	go s.outlierLoop()
+	return nil
+}
+
+func (s *P2C) outlierLoop() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-time.After(s.od.Interval):
+			s.ejectOutliers()
+		}
+	}
+}
+
+// ejectOutliers ejects healthy backends whose error rate in the last interval is at or
+// above our ErrorRate, unless that would eject more than MaxEjectionPercent of the pool.
+func (s *P2C) ejectOutliers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	healthy := s.healthy.Load().([]*weightedBackend)
+	sick := s.sick.Load().([]*weightedBackend)
+
+	ejected := 0
+	for _, b := range sick {
+		if now.Before(b.ejectedUntil) {
+			ejected++
+		}
+	}
+	total := len(healthy) + len(sick) + len(s.draining.Load().([]*weightedBackend))
+	maxEjected := int(math.Floor(float64(total) * float64(s.od.MaxEjectionPercent) / 100))
+
+	for _, b := range healthy {
+		requests := atomic.SwapInt64(&b.requests, 0)
+		errors := atomic.SwapInt64(&b.errors, 0)
+		if requests < int64(s.od.MinRequests) {
+			continue
+		}
+
+		rate := float64(errors) / float64(requests)
+		if rate < s.od.ErrorRate {
+			b.ejections = 0
+			continue
+		}
+		if ejected >= maxEjected {
+			continue
+		}
+
+		b.ejections++
+		b.ejectedUntil = now.Add(s.od.BaseEjection * time.Duration(b.ejections))
+		ejected++
+		backendEjections.Add(context.Background(), 1, b.attrs())
+		s.healthyToSick(
+			b,
+			fmt.Sprintf(
+				"ejected for %d errors in %d requests until %s",
+				errors,
+				requests,
+				b.ejectedUntil.Format(time.RFC3339),
+			),
+		)
+	}
+}
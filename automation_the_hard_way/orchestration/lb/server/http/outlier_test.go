@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// traffic is the requests and errors a backend had in an outlier detection interval.
+type traffic struct {
+	requests, errors int64
+}
+
+// record sets the traffic of b for this interval.
+func (tr traffic) record(b *weightedBackend) {
+	atomic.StoreInt64(&b.requests, tr.requests)
+	atomic.StoreInt64(&b.errors, tr.errors)
+}
+
+// newOutlierP2C returns a P2C with od and a healthy backend for each port. Outlier
+// detection is only run when the test calls ejectOutliers().
+func newOutlierP2C(t *testing.T, od OutlierDetection, ports ...int32) (*P2C, []*weightedBackend) {
+	t.Helper()
+
+	p := newP2C(t, passCheck)
+	od.Interval = time.Hour
+	if err := p.SetOutlierDetection(od); err != nil {
+		t.Fatal(err)
+	}
+
+	var backs []*weightedBackend
+	for _, port := range ports {
+		b := newBackend(t, port)
+		if err := p.Add(context.Background(), b); err != nil {
+			t.Fatal(err)
+		}
+		backs = append(backs, p.find(b, p.healthy))
+	}
+	return p, backs
+}
+
+func TestEjectOutliers(t *testing.T) {
+	tests := []struct {
+		desc    string
+		od      OutlierDetection
+		traffic []traffic
+		want    []bool // If each backend is ejected.
+	}{
+		{
+			desc:    "too few requests",
+			od:      OutlierDetection{ErrorRate: 0.5, MinRequests: 10},
+			traffic: []traffic{{5, 5}, {100, 0}, {100, 0}, {100, 0}},
+			want:    []bool{false, false, false, false},
+		},
+		{
+			desc:    "error rate below the limit",
+			od:      OutlierDetection{ErrorRate: 0.5},
+			traffic: []traffic{{100, 49}, {100, 0}, {100, 0}, {100, 0}},
+			want:    []bool{false, false, false, false},
+		},
+		{
+			desc:    "error rate at the limit",
+			od:      OutlierDetection{ErrorRate: 0.5},
+			traffic: []traffic{{100, 50}, {100, 0}, {100, 0}, {100, 0}},
+			want:    []bool{true, false, false, false},
+		},
+		{
+			desc:    "no more than the default 50 percent ejected",
+			od:      OutlierDetection{ErrorRate: 0.5},
+			traffic: []traffic{{100, 100}, {100, 100}, {100, 100}, {100, 0}},
+			want:    []bool{true, true, false, false},
+		},
+		{
+			desc:    "max ejection percent rounds down",
+			od:      OutlierDetection{ErrorRate: 0.5, MaxEjectionPercent: 30},
+			traffic: []traffic{{100, 100}, {100, 100}, {100, 0}, {100, 0}},
+			want:    []bool{true, false, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		p, backs := newOutlierP2C(t, test.od, 8000, 8001, 8002, 8003)
+		for i, tr := range test.traffic {
+			tr.record(backs[i])
+		}
+
+		p.ejectOutliers()
+
+		for i, b := range backs {
+			got := b.health() == sick
+			if got != test.want[i] {
+				t.Errorf("TestEjectOutliers(%s): backend %d: got ejected == %v, want %v", test.desc, i, got, test.want[i])
+				continue
+			}
+			if got && !strings.Contains(b.getReason(), "ejected") {
+				t.Errorf("TestEjectOutliers(%s): backend %d: got reason %q, want it to say it was ejected", test.desc, i, b.getReason())
+			}
+		}
+	}
+}
+
+func TestEjectionTime(t *testing.T) {
+	const base = time.Hour
+
+	p, backs := newOutlierP2C(t, OutlierDetection{ErrorRate: 0.5, BaseEjection: base}, 8000, 8001)
+	b := backs[0]
+
+	for ejection := 1; ejection <= 2; ejection++ {
+		traffic{100, 100}.record(b)
+		p.ejectOutliers()
+		if b.health() != sick {
+			t.Fatalf("TestEjectionTime: ejection %d: backend was not ejected", ejection)
+		}
+		// Each ejection in a row is longer.
+		want := time.Now().Add(base * time.Duration(ejection))
+		if d := want.Sub(b.ejectedUntil); d < 0 || d > time.Minute {
+			t.Errorf("TestEjectionTime: ejection %d: got ejected until %v, want %v", ejection, b.ejectedUntil, want)
+		}
+
+		// Passing health checks doesn't end an ejection early.
+		p.healthChecks(context.Background())
+		if b.health() != sick {
+			t.Fatalf("TestEjectionTime: ejection %d: backend returned to service before its ejection ended", ejection)
+		}
+
+		b.ejectedUntil = time.Now().Add(-time.Second)
+		p.healthChecks(context.Background())
+		if b.health() != healthy {
+			t.Fatalf("TestEjectionTime: ejection %d: backend did not return to service after its ejection", ejection)
+		}
+	}
+}
@@ -18,6 +18,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/telemetry/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
@@ -28,6 +32,31 @@ type weightedBackend struct {
 	Backend
 
 	weight int32
+
+	// passes and fails are the number of health checks in a row the backend has
+	// passed or failed. These are only used in P2C.healthChecks().
+	passes, fails int32
+
+	// requests and errors count the requests sent to the backend and how many of those
+	// failed since the last outlier detection interval.
+	requests, errors int64
+	// ejections is the number of times in a row the backend was ejected and ejectedUntil
+	// is when the backend can return to service. These are protected by P2C.mu.
+	ejections    int32
+	ejectedUntil time.Time
+
+	reason atomic.Value // string
+}
+
+// setReason sets why the backend is out of service.
+func (w *weightedBackend) setReason(reason string) {
+	w.reason.Store(reason)
+}
+
+// getReason returns why the backend is out of service.
+func (w *weightedBackend) getReason() string {
+	r, _ := w.reason.Load().(string)
+	return r
 }
 
 func (w *weightedBackend) get() int32 {
@@ -37,6 +66,7 @@ func (w *weightedBackend) get() int32 {
 func (w *weightedBackend) call() {
 	w.Backend.call()
 	atomic.AddInt32(&w.weight, 1)
+	backendInFlight.Add(context.Background(), 1, w.attrs())
 }
 
 func (w *weightedBackend) done() {
@@ -45,6 +75,12 @@ func (w *weightedBackend) done() {
 	if i < 0 {
 		panic("weightedBackend cannot be < 0")
 	}
+	backendInFlight.Add(context.Background(), -1, w.attrs())
+}
+
+// attrs returns the metric attributes for the backend.
+func (w *weightedBackend) attrs() metric.MeasurementOption {
+	return metric.WithAttributes(attribute.String(metrics.BackendKey, w.url().String()))
 }
 
 func (w *weightedBackend) handler() http.Handler {
@@ -52,19 +88,51 @@ func (w *weightedBackend) handler() http.Handler {
 		func(wr http.ResponseWriter, r *http.Request) {
 			w.call()
 			defer w.done()
-			w.Backend.handler().ServeHTTP(wr, r)
+
+			// The reverse proxy responds with a 502 if it can't reach the backend, so
+			// the status code catches both connection errors and backend errors.
+			sr := &statusRecorder{ResponseWriter: wr, status: http.StatusOK}
+			w.Backend.handler().ServeHTTP(sr, r)
+
+			atomic.AddInt64(&w.requests, 1)
+			if sr.status >= 500 {
+				atomic.AddInt64(&w.errors, 1)
+			}
 		},
 	)
 }
 
+// statusRecorder is an http.ResponseWriter that records the status code written.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher, which the reverse proxy uses for streaming responses.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // P2C implements Pool using the Power of 2 choice selection method.
 type P2C struct {
 	hc       HealthCheck
 	interval time.Duration
 
-	mu            sync.Mutex
-	healthy, sick *atomic.Value // []*weightedBackend
-	rand          *rand.Rand
+	mu                      sync.Mutex
+	healthy, sick, draining *atomic.Value // []*weightedBackend
+	rise, fall              int32
+	od                      *OutlierDetection
+	retry                   atomic.Value // *retrier
+	tls                     atomic.Value // *backendTLS
+	watchers                *watchers
 
 	done chan struct{}
 }
@@ -78,12 +146,16 @@ func NewP2C(hc HealthCheck, interval time.Duration) (*P2C, error) {
 		interval: interval,
 		healthy:  &atomic.Value{},
 		sick:     &atomic.Value{},
-		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		draining: &atomic.Value{},
+		rise:     1,
+		fall:     1,
+		watchers: newWatchers(),
 		done:     make(chan struct{}),
 	}
 
 	sp.healthy.Store([]*weightedBackend{})
 	sp.sick.Store([]*weightedBackend{})
+	sp.draining.Store([]*weightedBackend{})
 	go sp.healthLoop()
 
 	return sp, nil
@@ -92,6 +164,7 @@ func NewP2C(hc HealthCheck, interval time.Duration) (*P2C, error) {
 // Close implements Pool.Close().
 func (s *P2C) Close() error {
 	close(s.done)
+	s.watchers.close()
 	return nil
 }
 
@@ -101,7 +174,8 @@ func (s *P2C) Add(ctx context.Context, b Backend) error {
 		return ctx.Err()
 	}
 
-	if err := s.hc(ctx, b.url().String()); err != nil {
+	s.useTLS(b)
+	if err := s.check(ctx, b); err != nil {
 		b.setHealth(sick)
 		return fmt.Errorf("backend is sick: %w", err)
 	}
@@ -110,34 +184,157 @@ func (s *P2C) Add(ctx context.Context, b Backend) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.find(b, s.sick) != nil {
+		return fmt.Errorf("backend already exists")
+	}
+	// If the backend was drained and not removed, adding it puts it back into service.
+	s.removeFromValue(b, s.draining)
 	if err := s.addToValue(&weightedBackend{Backend: b}, s.healthy); err != nil {
 		return err
 	}
+	s.watchers.send(b, pb.BackendEventType_BET_ADDED, "")
 
 	return nil
 }
 
+// Restore implements Pool.Restore().
+func (s *P2C) Restore(ctx context.Context, b Backend) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	s.useTLS(b)
+	v := s.healthy
+	if err := s.check(ctx, b); err != nil {
+		log.Printf("restored backend %s is sick: %s", b.url(), err)
+		b.setHealth(sick)
+		v = s.sick
+	} else {
+		b.setHealth(healthy)
+	}
+	wb := &weightedBackend{Backend: b}
+	if v == s.sick {
+		wb.setReason("failed health check when restored")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(b, s.healthy) != nil || s.find(b, s.sick) != nil {
+		return fmt.Errorf("backend already exists")
+	}
+	s.removeFromValue(b, s.draining)
+	if err := s.addToValue(wb, v); err != nil {
+		return err
+	}
+	s.watchers.send(b, pb.BackendEventType_BET_ADDED, wb.getReason())
+	return nil
+}
+
+// check runs our health check against b.
+func (s *P2C) check(ctx context.Context, b Backend) error {
+	bt, _ := s.tls.Load().(*backendTLS)
+	return s.hc(withBackendTLS(ctx, bt), b.url().String())
+}
+
+// useTLS has b use TLS if the pool's backends use TLS.
+func (s *P2C) useTLS(b Backend) {
+	if bt, _ := s.tls.Load().(*backendTLS); bt != nil {
+		b.setTLS(bt)
+	}
+}
+
+// SetThresholds implements Pool.SetThresholds().
+func (s *P2C) SetThresholds(rise, fall int32) error {
+	if rise < 0 || fall < 0 {
+		return fmt.Errorf("rise(%d) and fall(%d) cannot be negative", rise, fall)
+	}
+	if rise == 0 {
+		rise = 1
+	}
+	if fall == 0 {
+		fall = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rise, s.fall = rise, fall
+	return nil
+}
+
 // Remove implements Pool.Remove().
 func (s *P2C) Remove(ctx context.Context, b Backend) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.removeFromValue(b, s.healthy)
-	s.removeFromValue(b, s.sick)
+	found := false
+	for _, v := range []*atomic.Value{s.healthy, s.sick, s.draining} {
+		if s.removeFromValue(b, v) == nil {
+			found = true
+		}
+	}
+	if found {
+		s.watchers.send(b, pb.BackendEventType_BET_REMOVED, "")
+	}
 	return nil
 }
 
+// Drain implements Pool.Drain().
+func (s *P2C) Drain(ctx context.Context, b Backend) (int32, error) {
+	s.mu.Lock()
+	wb := s.find(b, s.healthy)
+	if wb == nil {
+		wb = s.find(b, s.sick)
+	}
+	if wb == nil {
+		wb = s.find(b, s.draining)
+	}
+	if wb == nil {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("could not find backend(%s)", b.url().String())
+	}
+	// Moving the backend to our draining list removes it from the list ServeHTTP()
+	// picks from and stops the healthLoop() from putting it back.
+	if wb.health() != draining {
+		s.removeFromValue(wb, s.healthy)
+		s.removeFromValue(wb, s.sick)
+		wb.setHealth(draining)
+		recordTransition(wb, draining)
+		s.watchers.send(wb, pb.BackendEventType_BET_DRAINING, "")
+		if err := s.addToValue(wb, s.draining); err != nil {
+			s.mu.Unlock()
+			return 0, err
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("backend %s is draining", wb.url())
+	for {
+		inFlight := wb.get()
+		if inFlight == 0 {
+			return 0, nil
+		}
+		select {
+		case <-ctx.Done():
+			return inFlight, nil
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
 // Health implements Pool.Health().
 func (s *P2C) Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth, error) {
 	status := pb.PoolStatus_PS_FULL
 
 	healthy := s.healthy.Load().([]*weightedBackend)
 	sick := s.sick.Load().([]*weightedBackend)
+	drain := s.draining.Load().([]*weightedBackend)
 
 	healthyNodes := len(healthy)
 	sickNodes := len(sick)
 
-	if sickNodes == 0 && healthyNodes == 0 {
+	if sickNodes == 0 && healthyNodes == 0 && len(drain) == 0 {
 		return &pb.PoolHealth{
 			Status: pb.PoolStatus_PS_EMPTY,
 		}, nil
@@ -172,6 +369,28 @@ func (s *P2C) Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth
 				return nil, fmt.Errorf("an unknown healthy backend type found(%T)", wb.Backend)
 			}
 		}
+		// Draining backends are returned with the healthy backends, as they are
+		// still serving the requests they have.
+		for _, wb := range drain {
+			switch v := wb.Backend.(type) {
+			case *IPBackend:
+				h := &pb.BackendHealth{
+					Status: pb.BackendStatus_BS_DRAINING,
+					Backend: &pb.Backend{
+						Backend: &pb.Backend_IpBackend{
+							IpBackend: &pb.IPBackend{
+								Ip:      v.ip.String(),
+								Port:    v.port,
+								UrlPath: v.urlPath,
+							},
+						},
+					},
+				}
+				ph.Backends = append(ph.Backends, h)
+			default:
+				return nil, fmt.Errorf("an unknown draining backend type found(%T)", wb.Backend)
+			}
+		}
 	}
 	if req.Sick {
 		for _, wb := range sick {
@@ -179,6 +398,7 @@ func (s *P2C) Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth
 			case *IPBackend:
 				h := &pb.BackendHealth{
 					Status: pb.BackendStatus_BS_SICK,
+					Reason: wb.getReason(),
 					Backend: &pb.Backend{
 						Backend: &pb.Backend_IpBackend{
 							IpBackend: &pb.IPBackend{
@@ -198,6 +418,16 @@ func (s *P2C) Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth
 	return ph, nil
 }
 
+// find returns the *weightedBackend in v that is for b or nil if not found.
+func (s *P2C) find(b Backend, v *atomic.Value) *weightedBackend {
+	for _, back := range v.Load().([]*weightedBackend) {
+		if b.url().String() == back.url().String() {
+			return back
+		}
+	}
+	return nil
+}
+
 func (s *P2C) addToValue(b *weightedBackend, v *atomic.Value) error {
 	backs := (*v).Load().([]*weightedBackend)
 	n := make([]*weightedBackend, 0, len(backs)+1)
@@ -241,19 +471,19 @@ func (s *P2C) removeFromValue(b Backend, v *atomic.Value) error {
 
 // ServeHTTP implements Pool.ServeHTTP().
 func (s *P2C) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	backs := s.healthy.Load().([]*weightedBackend)
-	if len(backs) == 0 {
-		http.Error(w, "no backends available", http.StatusInternalServerError)
-		return
-	}
-	x := s.rand.Int31n(int32(len(backs)))
-	y := s.rand.Int31n(int32(len(backs)))
+	s.serve(w, r, s.pick)
+}
 
-	if backs[x].weight < backs[y].weight {
-		backs[x].handler().ServeHTTP(w, r)
-		return
+// pick implements picker. This is called by concurrent requests, so it uses the
+// top-level rand functions, which are safe for concurrent use.
+func (s *P2C) pick(backs []*weightedBackend, r *http.Request) *weightedBackend {
+	x := rand.Int31n(int32(len(backs)))
+	y := rand.Int31n(int32(len(backs)))
+
+	if backs[x].get() < backs[y].get() {
+		return backs[x]
 	}
-	backs[y].handler().ServeHTTP(w, r)
+	return backs[y]
 }
 
 func (s *P2C) healthLoop() {
@@ -269,37 +499,57 @@ func (s *P2C) healthLoop() {
 	}
 }
 
+// healthChecks checks all healthy and sick backends. A healthy backend that fails s.fall
+// checks in a row becomes sick and a sick backend that passes s.rise checks in a row becomes
+// healthy. This keeps a single bad check from flapping a backend in and out of service.
 func (s *P2C) healthChecks(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	healthy := s.healthy.Load().([]*weightedBackend)
+	sick := s.sick.Load().([]*weightedBackend)
+	backs := make([]*weightedBackend, 0, len(healthy)+len(sick))
+	backs = append(backs, healthy...)
+	backs = append(backs, sick...)
+
+	results := make([]error, len(backs))
 	wg := sync.WaitGroup{}
-	for _, b := range s.healthy.Load().([]*weightedBackend) {
-		b := b
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := s.hc(ctx, b.url().String()); err != nil {
-				s.healthyToSick(b)
-			}
-		}()
-	}
-	for _, b := range s.sick.Load().([]*weightedBackend) {
-		b := b
+	for i, b := range backs {
+		i, b := i, b
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := s.hc(ctx, b.url().String()); err == nil {
-				s.sickToHealthy(b)
-			}
+			results[i] = s.check(ctx, b)
 		}()
 	}
 	wg.Wait()
+
+	// We change states after all checks are done, as moving backends between our lists
+	// concurrently could lose updates.
+	for i, b := range backs {
+		if results[i] != nil {
+			b.passes = 0
+			b.fails++
+		} else {
+			b.fails = 0
+			b.passes++
+		}
+
+		switch {
+		case i < len(healthy) && b.fails >= s.fall:
+			s.healthyToSick(b, fmt.Sprintf("failed %d health checks: %s", b.fails, results[i]))
+		// An ejected backend must wait out its ejection, even if it passes its health checks.
+		case i >= len(healthy) && b.passes >= s.rise && time.Now().After(b.ejectedUntil):
+			s.sickToHealthy(b)
+		}
+	}
 }
 
-func (s *P2C) healthyToSick(b *weightedBackend) {
-	log.Printf("backend %s became sick", b.url())
+func (s *P2C) healthyToSick(b *weightedBackend, reason string) {
+	log.Printf("backend %s became sick: %s", b.url(), reason)
 	b.setHealth(sick)
+	recordTransition(b, sick)
+	b.setReason(reason)
 	if err := s.removeFromValue(b, s.healthy); err != nil {
 		log.Println(err)
 		return
@@ -307,11 +557,14 @@ func (s *P2C) healthyToSick(b *weightedBackend) {
 	if err := s.addToValue(b, s.sick); err != nil {
 		panic(err)
 	}
+	s.watchers.send(b, pb.BackendEventType_BET_SICK, reason)
 }
 
 func (s *P2C) sickToHealthy(b *weightedBackend) {
 	log.Printf("backend %s became healthy", b.url())
 	b.setHealth(healthy)
+	recordTransition(b, healthy)
+	b.setReason("")
 	if err := s.removeFromValue(b, s.sick); err != nil {
 		log.Println(err)
 		return
@@ -319,4 +572,5 @@ func (s *P2C) sickToHealthy(b *weightedBackend) {
 	if err := s.addToValue(b, s.healthy); err != nil {
 		panic(err)
 	}
+	s.watchers.send(b, pb.BackendEventType_BET_HEALTHY, "")
 }
@@ -0,0 +1,320 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+// testBackend starts an HTTP server that handles requests with h and returns a Backend for it.
+func testBackend(t *testing.T, h http.HandlerFunc) *IPBackend {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newBackend(t, int32(port))
+}
+
+// newP2C returns a P2C pool that is closed when the test ends. Its health checks are only
+// run when the test calls healthChecks().
+func newP2C(t *testing.T, hc HealthCheck) *P2C {
+	t.Helper()
+
+	p, err := NewP2C(hc, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// waitInFlight waits for b to have n requests in-flight in p.
+func waitInFlight(t *testing.T, p *P2C, b Backend, n int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		wb := p.find(b, p.healthy)
+		if wb != nil && wb.get() == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backend never had %d requests in-flight", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		desc     string
+		inFlight int32
+		// finish is how long the in-flight requests take after Drain() is called. If 0,
+		// they don't finish before Drain() times out.
+		finish time.Duration
+		want   int32
+	}{
+		{
+			desc: "no requests in-flight",
+		},
+		{
+			desc:     "requests finish",
+			inFlight: 2,
+			finish:   100 * time.Millisecond,
+		},
+		{
+			desc:     "requests still in-flight at timeout",
+			inFlight: 2,
+			want:     2,
+		},
+	}
+
+	for _, test := range tests {
+		release := make(chan struct{})
+		b := testBackend(t, func(w http.ResponseWriter, r *http.Request) { <-release })
+		p := newP2C(t, passCheck)
+		if err := p.Add(context.Background(), b); err != nil {
+			t.Fatalf("TestDrain(%s): Add(): got err == %s", test.desc, err)
+		}
+
+		wg := sync.WaitGroup{}
+		for i := int32(0); i < test.inFlight; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+			}()
+		}
+		waitInFlight(t, p, b, test.inFlight)
+
+		if test.finish > 0 {
+			time.AfterFunc(test.finish, func() { close(release) })
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		got, err := p.Drain(ctx, b)
+		cancel()
+		if test.finish == 0 {
+			close(release)
+		}
+		wg.Wait()
+
+		if err != nil {
+			t.Errorf("TestDrain(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("TestDrain(%s): got %d requests in-flight, want %d", test.desc, got, test.want)
+		}
+
+		// A draining backend doesn't get new requests, but is still in the pool.
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("TestDrain(%s): got status %d for a request after draining, want %d", test.desc, rec.Code, http.StatusInternalServerError)
+		}
+		ph, err := p.Health(context.Background(), &pb.PoolHealthReq{Healthy: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ph.Backends) != 1 || ph.Backends[0].Status != pb.BackendStatus_BS_DRAINING {
+			t.Errorf("TestDrain(%s): got backends %v, want the backend draining", test.desc, ph.Backends)
+		}
+	}
+}
+
+func TestDrainNotFound(t *testing.T) {
+	p := newP2C(t, passCheck)
+	if _, err := p.Drain(context.Background(), newBackend(t, 80)); err == nil {
+		t.Errorf("TestDrainNotFound: got err == nil, want err != nil")
+	}
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		desc string
+		// pool returns the picker of the pool type being tested.
+		pool     func(t *testing.T) picker
+		inFlight []int32
+		want     []float64
+	}{
+		{
+			desc:     "P2C with no load",
+			pool:     func(t *testing.T) picker { return newP2C(t, passCheck).pick },
+			inFlight: []int32{0, 0, 0},
+			want:     []float64{1.0 / 3, 1.0 / 3, 1.0 / 3},
+		},
+		{
+			// The loaded backend is only picked when it is both of the choices.
+			desc:     "P2C with a loaded backend",
+			pool:     func(t *testing.T) picker { return newP2C(t, passCheck).pick },
+			inFlight: []int32{10, 0, 0},
+			want:     []float64{1.0 / 9, 4.0 / 9, 4.0 / 9},
+		},
+		{
+			desc: "RoundRobin",
+			pool: func(t *testing.T) picker {
+				rr, err := NewRoundRobin(passCheck, time.Hour)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { rr.Close() })
+				return rr.pick
+			},
+			inFlight: []int32{10, 0, 0},
+			want:     []float64{1.0 / 3, 1.0 / 3, 1.0 / 3},
+		},
+		{
+			desc:     "LeastConn with no load",
+			pool:     newLeastConnPicker,
+			inFlight: []int32{0, 0, 0},
+			want:     []float64{1.0 / 3, 1.0 / 3, 1.0 / 3},
+		},
+		{
+			desc:     "LeastConn with a loaded backend",
+			pool:     newLeastConnPicker,
+			inFlight: []int32{10, 0, 0},
+			want:     []float64{0, 0.5, 0.5},
+		},
+		{
+			desc:     "LeastConn with one least loaded backend",
+			pool:     newLeastConnPicker,
+			inFlight: []int32{2, 1, 3},
+			want:     []float64{0, 1, 0},
+		},
+	}
+
+	for _, test := range tests {
+		var backs []*weightedBackend
+		for i, n := range test.inFlight {
+			backs = append(backs, &weightedBackend{Backend: newBackend(t, int32(8000+i)), weight: n})
+		}
+
+		got := pickShares(test.pool(t), backs, 10000)
+		for i := range got {
+			if !closeTo(got[i], test.want[i]) {
+				t.Errorf("TestPick(%s): backend %d got %.3f of requests, want %.3f", test.desc, i, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+func newLeastConnPicker(t *testing.T) picker {
+	l, err := NewLeastConn(passCheck, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l.pick
+}
+
+// switchCheck is a HealthCheck whose result is set by the test.
+type switchCheck struct {
+	fail atomic.Bool
+}
+
+func (s *switchCheck) check(ctx context.Context, endpoint string) error {
+	if s.fail.Load() {
+		return errors.New("check failed")
+	}
+	return nil
+}
+
+func TestHealthThresholds(t *testing.T) {
+	tests := []struct {
+		desc       string
+		rise, fall int32
+		// passes is the result of each round of health checks.
+		passes []bool
+		// want is the backend's state after each round.
+		want []healthState
+	}{
+		{
+			desc:   "one failure makes a backend sick by default",
+			passes: []bool{false},
+			want:   []healthState{sick},
+		},
+		{
+			desc:   "fall of 3",
+			fall:   3,
+			passes: []bool{false, false, false},
+			want:   []healthState{healthy, healthy, sick},
+		},
+		{
+			desc:   "a pass resets the failures",
+			fall:   2,
+			passes: []bool{false, true, false, false},
+			want:   []healthState{healthy, healthy, healthy, sick},
+		},
+		{
+			desc:   "rise of 2",
+			rise:   2,
+			passes: []bool{false, true, true},
+			want:   []healthState{sick, sick, healthy},
+		},
+		{
+			desc:   "a failure resets the passes",
+			rise:   2,
+			passes: []bool{false, true, false, true, true},
+			want:   []healthState{sick, sick, sick, sick, healthy},
+		},
+		{
+			desc:   "rise and fall together",
+			rise:   2,
+			fall:   2,
+			passes: []bool{false, false, true, true, true},
+			want:   []healthState{healthy, sick, sick, healthy, healthy},
+		},
+	}
+
+	for _, test := range tests {
+		sc := &switchCheck{}
+		p := newP2C(t, sc.check)
+		if err := p.SetThresholds(test.rise, test.fall); err != nil {
+			t.Fatalf("TestHealthThresholds(%s): SetThresholds(): got err == %s", test.desc, err)
+		}
+		b := newBackend(t, 8000)
+		if err := p.Add(context.Background(), b); err != nil {
+			t.Fatalf("TestHealthThresholds(%s): Add(): got err == %s", test.desc, err)
+		}
+
+		for i, pass := range test.passes {
+			sc.fail.Store(!pass)
+			p.healthChecks(context.Background())
+
+			if got := b.health(); got != test.want[i] {
+				t.Errorf("TestHealthThresholds(%s): round %d: got %s, want %s", test.desc, i, got, test.want[i])
+				break
+			}
+			inHealthy := p.find(b, p.healthy) != nil
+			if inHealthy != (test.want[i] == healthy) {
+				t.Errorf("TestHealthThresholds(%s): round %d: got in healthy list == %v, want %v", test.desc, i, inHealthy, !inHealthy)
+				break
+			}
+		}
+	}
+}
+
+func TestSetThresholdsNegative(t *testing.T) {
+	p := newP2C(t, passCheck)
+	if err := p.SetThresholds(-1, 1); err == nil {
+		t.Errorf("TestSetThresholdsNegative: got err == nil, want err != nil")
+	}
+}
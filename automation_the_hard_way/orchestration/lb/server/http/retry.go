@@ -0,0 +1,288 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/telemetry/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// retryBurst is the most retries a pool can bank. A new pool starts with this many, so
+	// that a pool with little traffic can still retry.
+	retryBurst = 10
+	// maxRetryBody is the largest request body we buffer so that the request can be retried.
+	// Requests with larger bodies are not retried.
+	maxRetryBody = 1 << 20
+)
+
+// RetryPolicy configures retrying requests that a backend failed on a different backend.
+// Only requests with idempotent methods are retried. A try fails if the backend can't be
+// reached, the try times out or the backend returns a 502, 503 or 504.
+type RetryPolicy struct {
+	// MaxRetries is the most times a request is retried. Must be at least 1.
+	MaxRetries int32
+	// PerTryTimeout is how long each try, including reading the response, can take.
+	// If 0, tries only end when the request does.
+	PerTryTimeout time.Duration
+	// Budget is the number of retries allowed for each request the pool receives, which
+	// keeps retries from multiplying the load on backends that are already failing.
+	// 0.2 allows retries to add 20% more requests. Defaults to 0.2.
+	Budget float64
+}
+
+func (r *RetryPolicy) defaults() {
+	if r.Budget == 0 {
+		r.Budget = 0.2
+	}
+}
+
+func (r RetryPolicy) validate() error {
+	if r.MaxRetries < 1 {
+		return fmt.Errorf("MaxRetries(%d) must be at least 1", r.MaxRetries)
+	}
+	if r.PerTryTimeout < 0 {
+		return fmt.Errorf("PerTryTimeout cannot be negative")
+	}
+	if r.Budget < 0 || r.Budget > 1 {
+		return fmt.Errorf("Budget(%v) must be between 0 and 1", r.Budget)
+	}
+	return nil
+}
+
+// retrier implements a RetryPolicy for a pool.
+type retrier struct {
+	RetryPolicy
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// deposit adds to the retry budget for a new request.
+func (r *retrier) deposit() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens += r.Budget
+	if r.tokens > retryBurst {
+		r.tokens = retryBurst
+	}
+}
+
+// withdraw takes a retry from the budget. It returns false if the budget is spent.
+func (r *retrier) withdraw() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// SetRetryPolicy implements Pool.SetRetryPolicy().
+func (s *P2C) SetRetryPolicy(rp RetryPolicy) error {
+	rp.defaults()
+	if err := rp.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.retry.Load() != nil {
+		return fmt.Errorf("retry policy is already set")
+	}
+	s.retry.Store(&retrier{RetryPolicy: rp, tokens: retryBurst})
+	return nil
+}
+
+// picker chooses the backend in backs to send r to. backs always has at least one backend.
+type picker func(backs []*weightedBackend, r *http.Request) *weightedBackend
+
+// serve sends r to the healthy backend chosen by pick. If the pool has a RetryPolicy and
+// the request can be retried, failed tries are retried on backends that have not been tried.
+func (s *P2C) serve(w http.ResponseWriter, r *http.Request, pick picker) {
+	backs := s.healthy.Load().([]*weightedBackend)
+	if len(backs) == 0 {
+		http.Error(w, "no backends available", http.StatusInternalServerError)
+		return
+	}
+
+	rt, _ := s.retry.Load().(*retrier)
+	if rt == nil || !idempotent(r.Method) {
+		pick(backs, r).handler().ServeHTTP(w, r)
+		return
+	}
+
+	body, ok := bufferBody(r)
+	if !ok {
+		pick(backs, r).handler().ServeHTTP(w, r)
+		return
+	}
+	rt.deposit()
+
+	tried := make([]*weightedBackend, 0, rt.MaxRetries+1)
+	for i := int32(0); ; i++ {
+		candidates := untried(backs, tried)
+		b := pick(candidates, r)
+		tried = append(tried, b)
+
+		// We can only retry if there is another backend to try, the request is still
+		// wanted and the budget has room.
+		canRetry := func() bool {
+			return i < rt.MaxRetries && len(candidates) > 1 && r.Context().Err() == nil && rt.withdraw()
+		}
+		tw := &tryWriter{w: w, header: http.Header{}, canRetry: canRetry}
+
+		if !s.try(tw, r, b, body, rt.PerTryTimeout) {
+			return
+		}
+		backendRetries.Add(
+			r.Context(),
+			1,
+			metric.WithAttributes(attribute.String(metrics.BackendKey, b.url().String())),
+		)
+	}
+}
+
+// try sends r to b with a timeout. It returns true if the try failed and is to be retried.
+func (s *P2C) try(tw *tryWriter, r *http.Request, b *weightedBackend, body []byte, timeout time.Duration) bool {
+	ctx := r.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req := r.WithContext(ctx)
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	b.handler().ServeHTTP(tw, req)
+	return tw.retry
+}
+
+// untried returns the backends in backs that are not in tried.
+func untried(backs, tried []*weightedBackend) []*weightedBackend {
+	if len(tried) == 0 {
+		return backs
+	}
+	n := make([]*weightedBackend, 0, len(backs))
+	for _, b := range backs {
+		found := false
+		for _, t := range tried {
+			if b == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			n = append(n, b)
+		}
+	}
+	return n
+}
+
+// idempotent returns true if requests with method can be safely sent more than once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// bufferBody reads r's body so that it can be sent on each try. If there is no body, this
+// returns nil. If the body is too large to buffer, this returns false and r.Body is left so
+// that the request can still be sent once.
+func bufferBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > maxRetryBody {
+		return nil, false
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxRetryBody+1))
+	if err != nil || len(b) > maxRetryBody {
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b), r.Body))
+		return nil, false
+	}
+	r.Body.Close()
+	return b, true
+}
+
+// retryStatus returns true if a response with code should be retried.
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// tryWriter is an http.ResponseWriter for a single try of a request. If the try fails with
+// a status we retry and canRetry() is true, the response is thrown away. Otherwise it is
+// written to w. The reverse proxy responds with a 502 if it can't reach the backend or the
+// try times out, so the status code catches those too.
+type tryWriter struct {
+	w        http.ResponseWriter
+	header   http.Header
+	canRetry func() bool
+
+	wrote bool
+	retry bool
+}
+
+func (t *tryWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *tryWriter) WriteHeader(code int) {
+	if t.wrote {
+		return
+	}
+	t.wrote = true
+
+	if retryStatus(code) && t.canRetry() {
+		t.retry = true
+		return
+	}
+	h := t.w.Header()
+	for k, v := range t.header {
+		h[k] = v
+	}
+	t.w.WriteHeader(code)
+}
+
+func (t *tryWriter) Write(b []byte) (int, error) {
+	if !t.wrote {
+		t.WriteHeader(http.StatusOK)
+	}
+	if t.retry {
+		return len(b), nil
+	}
+	return t.w.Write(b)
+}
+
+// Flush implements http.Flusher, which the reverse proxy uses for streaming responses.
+func (t *tryWriter) Flush() {
+	if !t.wrote {
+		t.WriteHeader(http.StatusOK)
+	}
+	if t.retry {
+		return
+	}
+	if f, ok := t.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	tests := []struct {
+		desc     string
+		method   string
+		policy   *RetryPolicy
+		requests int
+		// wantTries is the number of tries the backends get for all the requests.
+		wantTries int64
+	}{
+		{
+			desc:      "no retry policy",
+			method:    "GET",
+			requests:  5,
+			wantTries: 5,
+		},
+		{
+			desc:      "POST is not retried",
+			method:    "POST",
+			policy:    &RetryPolicy{MaxRetries: 1, Budget: 1},
+			requests:  5,
+			wantTries: 5,
+		},
+		{
+			desc:      "each request is retried",
+			method:    "GET",
+			policy:    &RetryPolicy{MaxRetries: 1, Budget: 1},
+			requests:  5,
+			wantTries: 10,
+		},
+		{
+			desc:      "a backend is only tried once per request",
+			method:    "GET",
+			policy:    &RetryPolicy{MaxRetries: 3, Budget: 1},
+			requests:  5,
+			wantTries: 10,
+		},
+		{
+			// The first 19 requests use up the 10 banked retries plus half a retry each.
+			// After that, every other request earns a retry.
+			desc:      "budget of 0.5",
+			method:    "GET",
+			policy:    &RetryPolicy{MaxRetries: 1, Budget: 0.5},
+			requests:  25,
+			wantTries: 25 + 19 + 3,
+		},
+		{
+			// The first 13 requests use up the 10 banked retries plus a quarter retry each.
+			// After that, every fourth request earns a retry.
+			desc:      "budget of 0.25",
+			method:    "GET",
+			policy:    &RetryPolicy{MaxRetries: 1, Budget: 0.25},
+			requests:  25,
+			wantTries: 25 + 13 + 3,
+		},
+	}
+
+	for _, test := range tests {
+		var tries atomic.Int64
+		unavailable := func(w http.ResponseWriter, r *http.Request) {
+			tries.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		p := newP2C(t, passCheck)
+		for i := 0; i < 2; i++ {
+			if err := p.Add(context.Background(), testBackend(t, unavailable)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if test.policy != nil {
+			if err := p.SetRetryPolicy(*test.policy); err != nil {
+				t.Fatalf("TestRetryBudget(%s): SetRetryPolicy(): got err == %s", test.desc, err)
+			}
+		}
+
+		for i := 0; i < test.requests; i++ {
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(test.method, "/", nil))
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("TestRetryBudget(%s): got status %d, want %d", test.desc, rec.Code, http.StatusServiceUnavailable)
+				break
+			}
+		}
+
+		if got := tries.Load(); got != test.wantTries {
+			t.Errorf("TestRetryBudget(%s): got %d tries, want %d", test.desc, got, test.wantTries)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		desc   string
+		policy RetryPolicy
+		// bad handles requests on the backend that fails.
+		bad http.HandlerFunc
+	}{
+		{
+			desc:   "backend unavailable",
+			policy: RetryPolicy{MaxRetries: 1},
+			bad: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		},
+		{
+			desc:   "try times out",
+			policy: RetryPolicy{MaxRetries: 1, PerTryTimeout: 50 * time.Millisecond},
+			bad: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		p := newP2C(t, passCheck)
+		good := func(w http.ResponseWriter, r *http.Request) {}
+		for _, h := range []http.HandlerFunc{test.bad, good} {
+			if err := p.Add(context.Background(), testBackend(t, h)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := p.SetRetryPolicy(test.policy); err != nil {
+			t.Fatalf("TestRetry(%s): SetRetryPolicy(): got err == %s", test.desc, err)
+		}
+
+		// Whichever backend is picked first, the request ends on the good one.
+		for i := 0; i < 5; i++ {
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("TestRetry(%s): got status %d, want %d", test.desc, rec.Code, http.StatusOK)
+				break
+			}
+		}
+	}
+}
+
+func TestSetRetryPolicy(t *testing.T) {
+	tests := []struct {
+		desc    string
+		policy  RetryPolicy
+		wantErr bool
+	}{
+		{desc: "valid", policy: RetryPolicy{MaxRetries: 1}},
+		{desc: "no retries", policy: RetryPolicy{}, wantErr: true},
+		{desc: "negative timeout", policy: RetryPolicy{MaxRetries: 1, PerTryTimeout: -1}, wantErr: true},
+		{desc: "budget too large", policy: RetryPolicy{MaxRetries: 1, Budget: 1.5}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		p := newP2C(t, passCheck)
+		err := p.SetRetryPolicy(test.policy)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestSetRetryPolicy(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestSetRetryPolicy(%s): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
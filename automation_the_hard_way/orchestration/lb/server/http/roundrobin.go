@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RoundRobin implements Pool by sending requests to each healthy backend in turn.
+// Health checking and draining work the same as P2C.
+type RoundRobin struct {
+	*P2C
+
+	next uint64
+}
+
+// NewRoundRobin creates a new RoundRobin instance. hc is the health check
+// to perform on the backend to make sure its healthy and interval is how often to do
+// the health check.
+func NewRoundRobin(hc HealthCheck, interval time.Duration) (*RoundRobin, error) {
+	p, err := NewP2C(hc, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &RoundRobin{P2C: p}, nil
+}
+
+// ServeHTTP implements Pool.ServeHTTP().
+func (rr *RoundRobin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rr.serve(w, r, rr.pick)
+}
+
+// pick implements picker.
+func (rr *RoundRobin) pick(backs []*weightedBackend, r *http.Request) *weightedBackend {
+	i := atomic.AddUint64(&rr.next, 1) - 1
+	return backs[i%uint64(len(backs))]
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Route sends requests that match it to a pool. A request matches if it matches every
+// field that is set. Routes are checked before the URL patterns pools are registered with,
+// in order of Priority. Requests that match no Route are handled by the URL patterns.
+type Route struct {
+	// Name is the unique name of the route.
+	Name string
+	// Priority decides the order routes are checked in, highest first. Routes with the
+	// same Priority are checked in order of Name.
+	Priority int32
+	// Host matches the request's host, without the port. A Host of "*.example.com" matches
+	// any subdomain of example.com. This is not case sensitive.
+	Host string
+	// PathPrefix matches requests whose path starts with it.
+	PathPrefix string
+	// Headers match requests that have all these headers. If a header's value is empty,
+	// the header only needs to be present.
+	Headers map[string]string
+	// Methods match requests that use any of these methods, such as "GET".
+	Methods []string
+	// Pool is the name of the pool to send matching requests to.
+	Pool string
+}
+
+func (r Route) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("route must have a Name")
+	}
+	if strings.TrimSpace(r.Pool) == "" {
+		return fmt.Errorf("route(%s) must have a Pool", r.Name)
+	}
+	if r.Host == "" && r.PathPrefix == "" && len(r.Headers) == 0 && len(r.Methods) == 0 {
+		return fmt.Errorf("route(%s) must match on something", r.Name)
+	}
+	if r.Host != "" && strings.Contains(strings.TrimPrefix(r.Host, "*."), "*") {
+		return fmt.Errorf("route(%s) Host(%s) can only have a wildcard as its first label", r.Name, r.Host)
+	}
+	if r.PathPrefix != "" && !strings.HasPrefix(r.PathPrefix, "/") {
+		return fmt.Errorf("route(%s) PathPrefix(%s) must start with /", r.Name, r.PathPrefix)
+	}
+	return nil
+}
+
+// match returns true if req matches the route.
+func (r Route) match(req *http.Request) bool {
+	if r.Host != "" && !matchHost(r.Host, req.Host) {
+		return false
+	}
+	if r.PathPrefix != "" && !strings.HasPrefix(req.URL.Path, r.PathPrefix) {
+		return false
+	}
+	for k, v := range r.Headers {
+		got, ok := req.Header[http.CanonicalHeaderKey(k)]
+		if !ok {
+			return false
+		}
+		if v != "" && (len(got) == 0 || got[0] != v) {
+			return false
+		}
+	}
+	if len(r.Methods) > 0 {
+		found := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, req.Method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchHost returns true if host, which may have a port, matches pattern.
+func matchHost(pattern, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	pattern = strings.ToLower(pattern)
+
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
+
+// route is a Route with the handler for its pool.
+type route struct {
+	Route
+
+	handler http.Handler
+}
+
+// sortRoutes sorts routes in the order they should be checked.
+func sortRoutes(routes []route) {
+	sort.Slice(
+		routes,
+		func(i, j int) bool {
+			if routes[i].Priority != routes[j].Priority {
+				return routes[i].Priority > routes[j].Priority
+			}
+			return routes[i].Name < routes[j].Name
+		},
+	)
+}
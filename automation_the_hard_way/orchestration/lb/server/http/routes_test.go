@@ -0,0 +1,226 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoutePrecedence(t *testing.T) {
+	l, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddPool("/", newNamePool(t, "default")); err != nil {
+		t.Fatal(err)
+	}
+	routes := []Route{
+		{Name: "api", Host: "api.example.com", Pool: "api"},
+		{Name: "wild", Host: "*.example.com", Pool: "wild"},
+		{Name: "beta", Priority: 10, Headers: map[string]string{"X-Beta": ""}, Pool: "beta"},
+		{Name: "admin", Priority: 5, PathPrefix: "/admin", Methods: []string{"POST"}, Pool: "admin"},
+		{Name: "canary", Priority: 5, Headers: map[string]string{"X-Canary": "yes"}, Pool: "canary"},
+	}
+	for _, r := range routes {
+		if err := l.AddRouteOnlyPool(r.Pool, newNamePool(t, r.Pool)); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AddRoute(r); err != nil {
+			t.Fatalf("TestRoutePrecedence: AddRoute(%s): got err == %s", r.Name, err)
+		}
+	}
+
+	tests := []struct {
+		desc    string
+		method  string
+		host    string
+		path    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			desc: "routes with the same priority are checked by name",
+			host: "api.example.com",
+			want: "api",
+		},
+		{
+			desc: "wildcard host",
+			host: "www.example.com",
+			want: "wild",
+		},
+		{
+			desc: "host is not case sensitive and the port is ignored",
+			host: "API.Example.com:8080",
+			want: "api",
+		},
+		{
+			desc: "wildcard doesn't match the domain itself",
+			host: "example.com",
+			want: "default",
+		},
+		{
+			desc:    "higher priority wins",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Beta": "1"},
+			want:    "beta",
+		},
+		{
+			desc:   "all fields must match",
+			method: "POST",
+			host:   "www.example.com",
+			path:   "/admin/users",
+			want:   "admin",
+		},
+		{
+			desc: "method doesn't match",
+			host: "other.org",
+			path: "/admin/users",
+			want: "default",
+		},
+		{
+			desc:    "header value doesn't match",
+			host:    "other.org",
+			headers: map[string]string{"X-Canary": "no"},
+			want:    "default",
+		},
+		{
+			desc:    "header value matches",
+			host:    "other.org",
+			headers: map[string]string{"X-Canary": "yes"},
+			want:    "canary",
+		},
+		{
+			desc: "no route matches",
+			host: "other.org",
+			want: "default",
+		},
+	}
+
+	for _, test := range tests {
+		if test.method == "" {
+			test.method = "GET"
+		}
+		if test.path == "" {
+			test.path = "/"
+		}
+		r := httptest.NewRequest(test.method, test.path, nil)
+		r.Host = test.host
+		for k, v := range test.headers {
+			r.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		l.handler.ServeHTTP(rec, r)
+		if rec.Code != http.StatusOK {
+			t.Errorf("TestRoutePrecedence(%s): got status %d, want %d", test.desc, rec.Code, http.StatusOK)
+			continue
+		}
+		if got := rec.Body.String(); got != test.want {
+			t.Errorf("TestRoutePrecedence(%s): got pool %q, want %q", test.desc, got, test.want)
+		}
+	}
+
+	// Routes() lists the routes in the order they are checked.
+	want := []string{"beta", "admin", "canary", "api", "wild"}
+	got := l.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("TestRoutePrecedence: got %d routes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i] {
+			t.Errorf("TestRoutePrecedence: route %d: got %s, want %s", i, got[i].Name, want[i])
+		}
+	}
+}
+
+func TestAddRoute(t *testing.T) {
+	tests := []struct {
+		desc    string
+		route   Route
+		wantErr bool
+	}{
+		{
+			desc:  "valid",
+			route: Route{Name: "new", Host: "new.example.com", Pool: "pool"},
+		},
+		{
+			desc:    "no name",
+			route:   Route{Host: "new.example.com", Pool: "pool"},
+			wantErr: true,
+		},
+		{
+			desc:    "no pool",
+			route:   Route{Name: "new", Host: "new.example.com"},
+			wantErr: true,
+		},
+		{
+			desc:    "pool doesn't exist",
+			route:   Route{Name: "new", Host: "new.example.com", Pool: "missing"},
+			wantErr: true,
+		},
+		{
+			desc:    "matches nothing",
+			route:   Route{Name: "new", Pool: "pool"},
+			wantErr: true,
+		},
+		{
+			desc:    "wildcard that isn't the first label",
+			route:   Route{Name: "new", Host: "www.*.com", Pool: "pool"},
+			wantErr: true,
+		},
+		{
+			desc:    "path prefix without a slash",
+			route:   Route{Name: "new", PathPrefix: "api", Pool: "pool"},
+			wantErr: true,
+		},
+		{
+			desc:    "name already used",
+			route:   Route{Name: "existing", Host: "new.example.com", Pool: "pool"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		l, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AddRouteOnlyPool("pool", newNamePool(t, "pool")); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AddRoute(Route{Name: "existing", Host: "existing.example.com", Pool: "pool"}); err != nil {
+			t.Fatal(err)
+		}
+
+		err = l.AddRoute(test.route)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestAddRoute(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestAddRoute(%s): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
+
+func TestRemovePoolWithRoute(t *testing.T) {
+	l, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRouteOnlyPool("pool", newNamePool(t, "pool")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRoute(Route{Name: "route", Host: "www.example.com", Pool: "pool"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.RemovePool("pool"); err == nil {
+		t.Errorf("TestRemovePoolWithRoute: got err == nil, want err != nil")
+	}
+	if err := l.RemoveRoute("route"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RemovePool("pool"); err != nil {
+		t.Errorf("TestRemovePoolWithRoute: after RemoveRoute(): got err == %s, want err == nil", err)
+	}
+}
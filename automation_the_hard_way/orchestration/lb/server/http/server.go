@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -13,11 +14,68 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/server/telemetry/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
+var (
+	poolLatency        = metrics.Get.Int64Hist("pool-latency")
+	poolRequests       = metrics.Get.Int64("pool-requests")
+	poolErrors         = metrics.Get.Int64("pool-errors")
+	backendTransitions = metrics.Get.Int64("backend-transitions")
+	backendEjections   = metrics.Get.Int64("backend-ejections")
+	backendRetries     = metrics.Get.Int64("backend-retries")
+	backendInFlight    = metrics.Get.Int64UD("backend-inflight")
+)
+
+// instrument wraps the pool serving pattern to record the metrics for each request.
+func instrument(pattern string, pool Pool) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			pool.ServeHTTP(sr, r)
+
+			ctx := r.Context()
+			attrs := metric.WithAttributes(
+				attribute.String(metrics.PatternKey, pattern),
+				attribute.String(metrics.CodeKey, fmt.Sprintf("%dxx", sr.status/100)),
+			)
+			poolLatency.Record(ctx, time.Since(start).Milliseconds(), attrs)
+			poolRequests.Add(ctx, 1, attrs)
+			if sr.status >= 500 {
+				poolErrors.Add(ctx, 1, attrs)
+			}
+		},
+	)
+}
+
+// recordTransition records that backend b changed to health state hs.
+func recordTransition(b Backend, hs healthState) {
+	backendTransitions.Add(
+		context.Background(),
+		1,
+		metric.WithAttributes(
+			attribute.String(metrics.BackendKey, b.url().String()),
+			attribute.String(metrics.StateKey, hs.String()),
+		),
+	)
+}
+
+// routeTable is the routes and URL patterns a routeHandler serves. It is replaced as a
+// whole, so a request never sees the routes of one configuration and the patterns of another.
+type routeTable struct {
+	mux    *http.ServeMux
+	routes []route
+}
+
+// routeHandler sends requests to the first route they match, or to the pool registered for
+// their URL pattern. Changes must be serialized by the caller, LoadBalancer.mu.
 type routeHandler struct {
-	muxStore atomic.Value // *http.ServeMux
+	table atomic.Value // *routeTable
 }
 
 func newRouteHandler(mux *http.ServeMux) *routeHandler {
@@ -25,23 +83,48 @@ func newRouteHandler(mux *http.ServeMux) *routeHandler {
 		panic("mux cannot be nil")
 	}
 	r := &routeHandler{}
-	r.muxStore.Store(mux)
+	r.table.Store(&routeTable{mux: mux, routes: []route{}})
 	return r
 }
 
+func (r *routeHandler) load() *routeTable {
+	return r.table.Load().(*routeTable)
+}
+
 func (r *routeHandler) mux() *http.ServeMux {
-	return r.muxStore.Load().(*http.ServeMux)
+	return r.load().mux
+}
+
+func (r *routeHandler) routes() []route {
+	return r.load().routes
 }
 
 func (r *routeHandler) replace(mux *http.ServeMux) {
 	if mux == nil {
 		return
 	}
-	r.muxStore.Store(mux)
+	r.table.Store(&routeTable{mux: mux, routes: r.routes()})
+}
+
+// replaceRoutes replaces our routes with routes, which must be sorted.
+func (r *routeHandler) replaceRoutes(routes []route) {
+	r.table.Store(&routeTable{mux: r.mux(), routes: routes})
+}
+
+// replaceAll replaces our mux and routes at the same time. routes must be sorted.
+func (r *routeHandler) replaceAll(mux *http.ServeMux, routes []route) {
+	r.table.Store(&routeTable{mux: mux, routes: routes})
 }
 
 func (r *routeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.muxStore.Load().(http.Handler).ServeHTTP(w, req)
+	t := r.load()
+	for _, rt := range t.routes {
+		if rt.match(req) {
+			rt.handler.ServeHTTP(w, req)
+			return
+		}
+	}
+	t.mux.ServeHTTP(w, req)
 }
 
 func newServ(handler *routeHandler) *http.Server {
@@ -54,24 +137,28 @@ func newServ(handler *routeHandler) *http.Server {
 
 // LoadBalancer is an HTTP reverse proxy load balancer.
 type LoadBalancer struct {
-	mu      sync.Mutex
-	pools   map[string]Pool
-	handler *routeHandler
-	serv    *http.Server
+	mu        sync.Mutex
+	pools     map[string]Pool
+	routeOnly map[string]bool // Pools that are not registered as a URL pattern.
+	routes    map[string]Route
+	handler   *routeHandler
+	serv      *http.Server
 }
 
 // New creates a new LoadBalancer instance.
 func New() (*LoadBalancer, error) {
 	handler := newRouteHandler(http.NewServeMux())
 	return &LoadBalancer{
-		pools:   map[string]Pool{},
-		handler: handler,
-		serv:    newServ(handler),
+		pools:     map[string]Pool{},
+		routeOnly: map[string]bool{},
+		routes:    map[string]Route{},
+		handler:   handler,
+		serv:      newServ(handler),
 	}, nil
 }
 
 // AddPool adds a pool of backends that serve the serveURL listed here. If a pattern
-// is added more than once, this will panic.
+// is added more than once, this returns an error.
 func (l *LoadBalancer) AddPool(pattern string, pool Pool) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -81,10 +168,88 @@ func (l *LoadBalancer) AddPool(pattern string, pool Pool) error {
 	}
 	l.pools[pattern] = pool
 
-	l.handler.mux().Handle(pattern, pool)
+	l.handler.mux().Handle(pattern, instrument(pattern, pool))
 	return nil
 }
 
+// AddRouteOnlyPool adds a pool that only receives requests from Routes. name is not
+// used as a URL pattern, so several of these pools can serve the same paths for
+// different hosts. name must not be used by another pool.
+func (l *LoadBalancer) AddRouteOnlyPool(name string, pool Pool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.pools[name]; ok {
+		return fmt.Errorf("pool(%s) is already registered", name)
+	}
+	l.pools[name] = pool
+	l.routeOnly[name] = true
+	return nil
+}
+
+// RouteOnly returns true if the pool named name was added with AddRouteOnlyPool().
+func (l *LoadBalancer) RouteOnly(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.routeOnly[name]
+}
+
+// AddRoute adds a Route. Its Pool must exist and its Name must not be used by another route.
+func (l *LoadBalancer) AddRoute(r Route) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.routes[r.Name]; ok {
+		return fmt.Errorf("route(%s) already exists", r.Name)
+	}
+	if _, ok := l.pools[r.Pool]; !ok {
+		return fmt.Errorf("route(%s) uses pool(%s), which doesn't exist", r.Name, r.Pool)
+	}
+	l.routes[r.Name] = r
+	l.updateRoutes()
+	return nil
+}
+
+// RemoveRoute removes the Route with name. If it does not exist, the error is still nil.
+func (l *LoadBalancer) RemoveRoute(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.routes, name)
+	l.updateRoutes()
+	return nil
+}
+
+// Routes returns all the routes in the order they are checked.
+func (l *LoadBalancer) Routes() []Route {
+	routes := l.handler.routes()
+	out := make([]Route, 0, len(routes))
+	for _, r := range routes {
+		out = append(out, r.Route)
+	}
+	return out
+}
+
+// updateRoutes updates the routes our handler uses to l.routes. l.mu must be held.
+func (l *LoadBalancer) updateRoutes() {
+	l.handler.replaceRoutes(l.sortedRoutes())
+}
+
+// sortedRoutes returns l.routes in the order they are checked. l.mu must be held.
+func (l *LoadBalancer) sortedRoutes() []route {
+	routes := make([]route, 0, len(l.routes))
+	for _, r := range l.routes {
+		routes = append(routes, route{Route: r, handler: instrument(r.Pool, l.pools[r.Pool])})
+	}
+	sortRoutes(routes)
+	return routes
+}
+
 // GetPool returns a pool by its pattern.
 func (l *LoadBalancer) GetPool(pattern string) (Pool, error) {
 	l.mu.Lock()
@@ -107,19 +272,70 @@ func (l *LoadBalancer) RemovePool(pattern string) error {
 	if !ok {
 		return nil
 	}
+	for _, r := range l.routes {
+		if r.Pool == pattern {
+			return fmt.Errorf("pool(%s) is used by route(%s)", pattern, r.Name)
+		}
+	}
 	p.Close()
 
 	delete(l.pools, pattern)
+	delete(l.routeOnly, pattern)
 
 	mux := http.NewServeMux()
 	for k, v := range l.pools {
-		mux.Handle(k, v)
+		if l.routeOnly[k] {
+			continue
+		}
+		mux.Handle(k, instrument(k, v))
 	}
 
 	l.handler.replace(mux)
 	return nil
 }
 
+// ReplacePools replaces all the pools and routes in the LoadBalancer. pools is keyed by
+// pattern, or by name for pools in routeOnly. The switch is atomic, requests are either
+// served by the old pools or the new ones. The old pools are closed. If the routes are not
+// valid, nothing is changed.
+func (l *LoadBalancer) ReplacePools(pools map[string]Pool, routeOnly map[string]bool, routes []Route) error {
+	byName := map[string]Route{}
+	for _, r := range routes {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if _, ok := byName[r.Name]; ok {
+			return fmt.Errorf("route(%s) is listed more than once", r.Name)
+		}
+		if _, ok := pools[r.Pool]; !ok {
+			return fmt.Errorf("route(%s) uses pool(%s), which doesn't exist", r.Name, r.Pool)
+		}
+		byName[r.Name] = r
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	mux := http.NewServeMux()
+	for k, v := range pools {
+		if routeOnly[k] {
+			continue
+		}
+		mux.Handle(k, instrument(k, v))
+	}
+
+	old := l.pools
+	l.pools = pools
+	l.routeOnly = routeOnly
+	l.routes = byName
+	l.handler.replaceAll(mux, l.sortedRoutes())
+
+	for _, p := range old {
+		p.Close()
+	}
+	return nil
+}
+
 // PoolHealth returns the health of a pool as defined in the req.
 func (l *LoadBalancer) PoolHealth(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth, error) {
 	l.mu.Lock()
@@ -136,19 +352,60 @@ func (l *LoadBalancer) Serve(lis net.Listener) error {
 	return l.serv.Serve(lis)
 }
 
-// ServeTLS will serve HTTPS traffic on lis. See http.Server.ServeTLS for more documentation.
+// ServeTLS will serve HTTPS traffic on lis with the certificate in certFile and keyFile.
+// The certificate is reloaded when the files change. Use ServeCerts() to serve more than
+// one certificate.
 func (l *LoadBalancer) ServeTLS(lis net.Listener, certFile, keyFile string) error {
-	return l.serv.ServeTLS(lis, certFile, keyFile)
+	cs, err := NewCertStore([]CertPair{{CertFile: certFile, KeyFile: keyFile}}, 0)
+	if err != nil {
+		return err
+	}
+	defer cs.Close()
+
+	return l.ServeCerts(lis, cs)
+}
+
+// ServeCerts will serve HTTPS traffic on lis with the certificates in cs, which are chosen
+// by the server name the client asks for.
+func (l *LoadBalancer) ServeCerts(lis net.Listener, cs *CertStore) error {
+	return l.serv.Serve(tls.NewListener(lis, cs.TLSConfig()))
 }
 
-// Pool represents a set of backends that serve a URL.
+// Pool represents a set of backends that serve a URL. Implementations decide how
+// requests are distributed over the healthy backends, such as P2C, Weighted,
+// RoundRobin, LeastConn and ConsistentHash.
 type Pool interface {
 	// Add adds a new Backend to the pool. The Backend must be healthy.
 	Add(ctx context.Context, b Backend) error
+	// Restore adds a Backend to the pool like Add(), but a Backend that fails its health
+	// checks is added as sick instead of returning an error. It is returned to service
+	// when it passes its health checks. This is used when loading a saved configuration.
+	Restore(ctx context.Context, b Backend) error
+	// SetThresholds sets how many health checks in a row a sick backend must pass to be
+	// returned to service (rise) and a healthy backend must fail to be taken out of
+	// service (fall). A value of 0 is 1, which is the default.
+	SetThresholds(rise, fall int32) error
+	// SetOutlierDetection turns on ejecting backends whose live traffic has too many errors.
+	// This can only be called once.
+	SetOutlierDetection(od OutlierDetection) error
+	// SetRetryPolicy turns on retrying failed requests on other backends. This can only
+	// be called once.
+	SetRetryPolicy(rp RetryPolicy) error
+	// SetBackendTLS has the pool connect to its backends with TLS. This can only be called
+	// once, before any backends are added.
+	SetBackendTLS(bt BackendTLS) error
 	// Remove removes a backend from the loadbalancer.
 	Remove(ctx context.Context, b Backend) error
+	// Drain stops new requests from being sent to a backend and waits for its in-flight
+	// requests to finish or ctx to be done. It returns the number of requests that are
+	// still in-flight. The backend stays in the pool until Remove() is called.
+	Drain(ctx context.Context, b Backend) (int32, error)
 	// Health returns the health of a pool.
 	Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth, error)
+	// Watch returns a channel that receives an event whenever a backend is added, removed,
+	// drained or changes health. The channel is closed when ctx is done, the pool is closed
+	// or the events aren't read fast enough.
+	Watch(ctx context.Context) <-chan *pb.BackendEvent
 	// Close closes the pool. It should not be used after this.
 	Close() error
 	// ServeHTTP implements http.Handler.
@@ -194,13 +451,13 @@ func StatusCheck(urlPath string, healthyValues []string) (HealthCheck, error) {
 		if err != nil {
 			return err
 		}
-		u.Scheme = "http"
+		scheme, c := checkClient(ctx)
+		u.Scheme = scheme
 		u.Path = urlPath
 
-		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 		defer cancel()
 
-		c := &http.Client{}
 		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 		if err != nil {
 			return err
@@ -236,8 +493,21 @@ const (
 	unknownHS healthState = 0
 	healthy   healthState = 1
 	sick      healthState = 2
+	draining  healthState = 3
 )
 
+func (h healthState) String() string {
+	switch h {
+	case healthy:
+		return "healthy"
+	case sick:
+		return "sick"
+	case draining:
+		return "draining"
+	}
+	return "unknown"
+}
+
 type Backend interface {
 	// url is the URL of the backend.
 	url() *url.URL
@@ -251,6 +521,9 @@ type Backend interface {
 	done()
 	// handler provides the backends http.Handler.
 	handler() http.Handler
+	// setTLS has the backend's handler connect with TLS. It must be called before the
+	// backend is used.
+	setTLS(bt *backendTLS)
 }
 
 // IPBackend provides a backend to our proxy that will use ip:port as the backend.
@@ -314,6 +587,13 @@ func (i *IPBackend) handler() http.Handler {
 	return i.handle
 }
 
+func (i *IPBackend) setTLS(bt *backendTLS) {
+	u := *i.u
+	u.Scheme = "https"
+	i.handle = httputil.NewSingleHostReverseProxy(&u)
+	i.handle.Transport = bt.transport
+}
+
 func (i *IPBackend) resolveURL() error {
 	u, err := url.Parse(i.urlPath)
 	if err != nil {
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// namePool is a Pool that responds to every request with its name.
+type namePool struct {
+	*P2C
+
+	name string
+}
+
+// newNamePool returns a namePool. It is closed when the test ends, if it wasn't already.
+func newNamePool(t *testing.T, name string) *namePool {
+	t.Helper()
+
+	p, err := NewP2C(passCheck, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	np := &namePool{P2C: p, name: name}
+	t.Cleanup(func() {
+		if !np.closed() {
+			p.Close()
+		}
+	})
+	return np
+}
+
+func (n *namePool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, n.name)
+}
+
+// closed returns true if the pool was closed.
+func (n *namePool) closed() bool {
+	select {
+	case <-n.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// serve sends a GET for host and path to l and returns the body. It is an error if
+// the response isn't a 200.
+func serve(l *LoadBalancer, host, path string) (string, error) {
+	r := httptest.NewRequest("GET", path, nil)
+	r.Host = host
+	rec := httptest.NewRecorder()
+	l.handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		return "", fmt.Errorf("got status %d", rec.Code)
+	}
+	return rec.Body.String(), nil
+}
+
+func TestReplacePools(t *testing.T) {
+	tests := []struct {
+		desc      string
+		pools     []string
+		routeOnly []string
+		routes    []Route
+		// want is the pool each host gets after the replace.
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			desc:      "new pools and routes",
+			pools:     []string{"/"},
+			routeOnly: []string{"api"},
+			routes:    []Route{{Name: "api", Host: "api.example.com", Pool: "api"}},
+			want:      map[string]string{"www.example.com": "new /", "api.example.com": "new api"},
+		},
+		{
+			desc:  "routes are removed",
+			pools: []string{"/"},
+			want:  map[string]string{"www.example.com": "new /", "old.example.com": "new /"},
+		},
+		{
+			desc:    "route to a pool that doesn't exist",
+			pools:   []string{"/"},
+			routes:  []Route{{Name: "api", Host: "api.example.com", Pool: "api"}},
+			want:    map[string]string{"www.example.com": "old /", "old.example.com": "old route"},
+			wantErr: true,
+		},
+		{
+			desc:      "route listed twice",
+			pools:     []string{"/"},
+			routeOnly: []string{"api"},
+			routes: []Route{
+				{Name: "api", Host: "api.example.com", Pool: "api"},
+				{Name: "api", Host: "api2.example.com", Pool: "api"},
+			},
+			want:    map[string]string{"www.example.com": "old /", "old.example.com": "old route"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid route",
+			pools:   []string{"/"},
+			routes:  []Route{{Name: "api", Pool: "/"}},
+			want:    map[string]string{"www.example.com": "old /", "old.example.com": "old route"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		l, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		oldMux, oldRoute := newNamePool(t, "old /"), newNamePool(t, "old route")
+		if err := l.AddPool("/", oldMux); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AddRouteOnlyPool("old", oldRoute); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AddRoute(Route{Name: "old", Host: "old.example.com", Pool: "old"}); err != nil {
+			t.Fatal(err)
+		}
+
+		pools := map[string]Pool{}
+		routeOnly := map[string]bool{}
+		for _, p := range test.pools {
+			pools[p] = newNamePool(t, "new "+p)
+		}
+		for _, p := range test.routeOnly {
+			pools[p] = newNamePool(t, "new "+p)
+			routeOnly[p] = true
+		}
+
+		err = l.ReplacePools(pools, routeOnly, test.routes)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestReplacePools(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestReplacePools(%s): got err == %s, want err == nil", test.desc, err)
+		}
+
+		for host, want := range test.want {
+			got, err := serve(l, host, "/")
+			if err != nil {
+				t.Errorf("TestReplacePools(%s): host %s: %s", test.desc, host, err)
+				continue
+			}
+			if got != want {
+				t.Errorf("TestReplacePools(%s): host %s got pool %q, want %q", test.desc, host, got, want)
+			}
+		}
+
+		// The old pools are closed only if they were replaced.
+		if got := oldMux.closed() && oldRoute.closed(); got == test.wantErr {
+			t.Errorf("TestReplacePools(%s): got old pools closed == %v, want %v", test.desc, got, !test.wantErr)
+		}
+		if len(l.Routes()) != len(test.routes) && !test.wantErr {
+			t.Errorf("TestReplacePools(%s): got %d routes, want %d", test.desc, len(l.Routes()), len(test.routes))
+		}
+	}
+}
+
+// TestReplacePoolsAtomic switches between two configurations while requests are being
+// served. Each request must be served entirely by one configuration.
+func TestReplacePoolsAtomic(t *testing.T) {
+	l, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In configuration "a", host a.example.com has a route. In "b" it goes to "/".
+	configs := []func() error{
+		func() error {
+			return l.ReplacePools(
+				map[string]Pool{"/": newNamePool(t, "a /"), "a": newNamePool(t, "a route")},
+				map[string]bool{"a": true},
+				[]Route{{Name: "a", Host: "a.example.com", Pool: "a"}},
+			)
+		},
+		func() error {
+			return l.ReplacePools(map[string]Pool{"/": newNamePool(t, "b /")}, nil, nil)
+		},
+	}
+	if err := configs[0](); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				got, err := serve(l, "a.example.com", "/")
+				if err == nil && got != "a route" && got != "b /" {
+					err = fmt.Errorf("got pool %q, which is not in either configuration", got)
+				}
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i < 500; i++ {
+		if err := configs[i%2](); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Errorf("TestReplacePoolsAtomic: %s", err)
+	default:
+	}
+}
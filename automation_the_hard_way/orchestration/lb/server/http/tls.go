@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertPair is the files for a certificate and its private key, in PEM format.
+type CertPair struct {
+	CertFile string
+	KeyFile  string
+}
+
+// keyPair is a loaded CertPair.
+type keyPair struct {
+	CertPair
+
+	cert            *tls.Certificate
+	certMod, keyMod time.Time
+	names           []string // The DNS names the certificate is for, lower case.
+}
+
+// load loads the files in k.CertPair. If the files have not changed since they were last
+// loaded, this returns false.
+func (k *keyPair) load() (bool, error) {
+	cs, err := os.Stat(k.CertFile)
+	if err != nil {
+		return false, err
+	}
+	ks, err := os.Stat(k.KeyFile)
+	if err != nil {
+		return false, err
+	}
+	if k.cert != nil && cs.ModTime().Equal(k.certMod) && ks.ModTime().Equal(k.keyMod) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(k.CertFile, k.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("could not load cert(%s) and key(%s): %w", k.CertFile, k.KeyFile, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("could not parse cert(%s): %w", k.CertFile, err)
+	}
+	cert.Leaf = leaf
+
+	var names []string
+	for _, n := range leaf.DNSNames {
+		names = append(names, strings.ToLower(n))
+	}
+	if len(names) == 0 && leaf.Subject.CommonName != "" {
+		names = append(names, strings.ToLower(leaf.Subject.CommonName))
+	}
+
+	k.cert, k.certMod, k.keyMod, k.names = &cert, cs.ModTime(), ks.ModTime(), names
+	return true, nil
+}
+
+// CertStore holds certificates for serving TLS. The certificate for a connection is
+// chosen by the server name the client sends (SNI). Certificates are reloaded when their
+// files change, so they can be renewed without a restart.
+type CertStore struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	pairs []*keyPair
+	certs atomic.Value // map[string]*tls.Certificate, keyed by DNS name
+
+	done chan struct{}
+	once sync.Once
+}
+
+// NewCertStore creates a CertStore for pairs. The first pair is used for clients that
+// don't send a server name or send one no certificate is for. The files are checked for
+// changes every interval, which defaults to 10 seconds.
+func NewCertStore(pairs []CertPair, interval time.Duration) (*CertStore, error) {
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("must have at least one CertPair")
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	cs := &CertStore{interval: interval, done: make(chan struct{})}
+	for _, p := range pairs {
+		kp := &keyPair{CertPair: p}
+		if _, err := kp.load(); err != nil {
+			return nil, err
+		}
+		cs.pairs = append(cs.pairs, kp)
+	}
+	cs.index()
+
+	go cs.reloadLoop()
+	return cs, nil
+}
+
+// Close stops reloading the certificates.
+func (c *CertStore) Close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// TLSConfig returns a *tls.Config that serves our certificates.
+func (c *CertStore) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: c.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate. A certificate for the exact server
+// name is preferred over a wildcard certificate.
+func (c *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certs := c.certs.Load().(map[string]*tls.Certificate)
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if cert, ok := certs[name]; ok {
+		return cert, nil
+	}
+	if i := strings.Index(name, "."); i > 0 {
+		if cert, ok := certs["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+	return certs[""], nil
+}
+
+// index rebuilds our certificate lookup from c.pairs.
+func (c *CertStore) index() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	certs := map[string]*tls.Certificate{"": c.pairs[0].cert}
+	// If two certificates are for the same name, the first one listed wins.
+	for i := len(c.pairs) - 1; i >= 0; i-- {
+		for _, n := range c.pairs[i].names {
+			certs[n] = c.pairs[i].cert
+		}
+	}
+	c.certs.Store(certs)
+}
+
+// reloadLoop reloads any certificates whose files have changed every interval. If a
+// certificate can't be loaded, the one we have is kept.
+func (c *CertStore) reloadLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.interval):
+		}
+
+		changed := false
+		for _, kp := range c.pairs {
+			ok, err := kp.load()
+			if err != nil {
+				log.Printf("could not reload certificate, keeping the old one: %s", err)
+				continue
+			}
+			if ok {
+				log.Printf("reloaded certificate(%s)", kp.CertFile)
+				changed = true
+			}
+		}
+		if changed {
+			c.index()
+		}
+	}
+}
+
+// BackendTLS configures connecting to a pool's backends with TLS. This is used for
+// requests and for health checks.
+type BackendTLS struct {
+	// CAFile is the CA certificate that backend certificates are verified with. If empty,
+	// the system's CAs are used.
+	CAFile string
+	// CertFile and KeyFile are the client certificate and key sent to backends for mutual
+	// TLS. If empty, no client certificate is sent.
+	CertFile, KeyFile string
+	// ServerName is the name backend certificates must be for. If empty, this is the
+	// backend's IP.
+	ServerName string
+}
+
+func (b BackendTLS) config() (*tls.Config, error) {
+	tc := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: b.ServerName,
+	}
+	if b.CAFile != "" {
+		pem, err := os.ReadFile(b.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CAFile(%s) had no certificates", b.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	switch {
+	case b.CertFile != "" && b.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(b.CertFile, b.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	case b.CertFile != "" || b.KeyFile != "":
+		return nil, fmt.Errorf("CertFile and KeyFile must both be set")
+	}
+	return tc, nil
+}
+
+// backendTLS is the TLS setup shared by all the backends in a pool.
+type backendTLS struct {
+	config    *tls.Config
+	transport *http.Transport
+	client    *http.Client
+}
+
+func newBackendTLS(bt BackendTLS) (*backendTLS, error) {
+	tc, err := bt.config()
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tc
+	return &backendTLS{config: tc, transport: t, client: &http.Client{Transport: t}}, nil
+}
+
+// SetBackendTLS implements Pool.SetBackendTLS().
+func (s *P2C) SetBackendTLS(bt BackendTLS) error {
+	b, err := newBackendTLS(bt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tls.Load() != nil {
+		return fmt.Errorf("backend TLS is already set")
+	}
+	if len(s.healthy.Load().([]*weightedBackend))+len(s.sick.Load().([]*weightedBackend)) > 0 {
+		return fmt.Errorf("backend TLS must be set before backends are added")
+	}
+	s.tls.Store(b)
+	return nil
+}
+
+type backendTLSKey struct{}
+
+// withBackendTLS returns a ctx that tells health checks to use bt.
+func withBackendTLS(ctx context.Context, bt *backendTLS) context.Context {
+	if bt == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, backendTLSKey{}, bt)
+}
+
+// getBackendTLS returns the *backendTLS health checks should use or nil if the backend
+// doesn't use TLS.
+func getBackendTLS(ctx context.Context) *backendTLS {
+	bt, _ := ctx.Value(backendTLSKey{}).(*backendTLS)
+	return bt
+}
+
+// checkClient returns the scheme and client that health checks should use for HTTP requests.
+func checkClient(ctx context.Context) (string, *http.Client) {
+	if bt := getBackendTLS(ctx); bt != nil {
+		return "https", bt.client
+	}
+	return "http", http.DefaultClient
+}
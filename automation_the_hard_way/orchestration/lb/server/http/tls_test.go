@@ -0,0 +1,170 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate and its key to dir. The certificate's common
+// name is name and it is for dnsNames. The files are named after file.
+func writeCert(t *testing.T, dir, file, name string, dnsNames ...string) CertPair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp := CertPair{CertFile: filepath.Join(dir, file+".crt"), KeyFile: filepath.Join(dir, file+".key")}
+	if err := os.WriteFile(cp.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cp.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cp
+}
+
+// touch sets the modification time of the files in cp to the future, so that a change is
+// seen even if the filesystem's timestamps are coarse.
+func touch(t *testing.T, cp CertPair, after time.Duration) {
+	t.Helper()
+
+	when := time.Now().Add(after)
+	for _, f := range []string{cp.CertFile, cp.KeyFile} {
+		if err := os.Chtimes(f, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// certName returns the common name of the certificate c serves for serverName.
+func certName(t *testing.T, c *CertStore, serverName string) string {
+	t.Helper()
+
+	cert, err := c.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestGetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	pairs := []CertPair{
+		writeCert(t, dir, "default", "default", "default.example.com"),
+		writeCert(t, dir, "www", "www", "www.example.com"),
+		writeCert(t, dir, "wild", "wild", "*.example.com"),
+		writeCert(t, dir, "dup", "dup", "www.example.com"),
+	}
+	c, err := NewCertStore(pairs, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		desc       string
+		serverName string
+		want       string
+	}{
+		{desc: "exact name", serverName: "www.example.com", want: "www"},
+		{desc: "exact name is preferred over a wildcard", serverName: "default.example.com", want: "default"},
+		{desc: "case and trailing dot are ignored", serverName: "WWW.Example.com.", want: "www"},
+		{desc: "wildcard", serverName: "api.example.com", want: "wild"},
+		{desc: "wildcard only covers one label", serverName: "a.api.example.com", want: "default"},
+		{desc: "no server name", serverName: "", want: "default"},
+		{desc: "no certificate for the name", serverName: "other.org", want: "default"},
+	}
+
+	for _, test := range tests {
+		if got := certName(t, c, test.serverName); got != test.want {
+			t.Errorf("TestGetCertificate(%s): got certificate %q, want %q", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestCertReload(t *testing.T) {
+	dir := t.TempDir()
+	cp := writeCert(t, dir, "www", "old", "www.example.com")
+
+	c, err := NewCertStore([]CertPair{cp}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	writeCert(t, dir, "www", "new", "www.example.com")
+	touch(t, cp, time.Minute)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for certName(t, c, "www.example.com") != "new" {
+		if time.Now().After(deadline) {
+			t.Fatalf("TestCertReload: certificate was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A certificate that can't be loaded doesn't replace the one we have.
+	if err := os.WriteFile(cp.CertFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, cp, 2*time.Minute)
+	time.Sleep(100 * time.Millisecond)
+
+	if got := certName(t, c, "www.example.com"); got != "new" {
+		t.Errorf("TestCertReload: after a bad certificate: got certificate %q, want %q", got, "new")
+	}
+}
+
+func TestNewCertStoreErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := CertPair{CertFile: filepath.Join(dir, "bad.crt"), KeyFile: filepath.Join(dir, "bad.key")}
+	for _, f := range []string{bad.CertFile, bad.KeyFile} {
+		if err := os.WriteFile(f, []byte("garbage"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		desc  string
+		pairs []CertPair
+	}{
+		{desc: "no pairs"},
+		{desc: "missing files", pairs: []CertPair{{CertFile: filepath.Join(dir, "none.crt"), KeyFile: filepath.Join(dir, "none.key")}}},
+		{desc: "bad files", pairs: []CertPair{bad}},
+	}
+
+	for _, test := range tests {
+		c, err := NewCertStore(test.pairs, time.Hour)
+		if err == nil {
+			c.Close()
+			t.Errorf("TestNewCertStoreErrors(%s): got err == nil, want err != nil", test.desc)
+		}
+	}
+}
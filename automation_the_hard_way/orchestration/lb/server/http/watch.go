@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+// watchBuffer is how many events a watcher can fall behind before it is dropped.
+const watchBuffer = 100
+
+// watchers sends a pool's backend events to everyone watching the pool.
+type watchers struct {
+	mu     sync.Mutex
+	chs    map[chan *pb.BackendEvent]struct{}
+	closed bool
+}
+
+func newWatchers() *watchers {
+	return &watchers{chs: map[chan *pb.BackendEvent]struct{}{}}
+}
+
+// add adds a watcher that is removed when ctx is done.
+func (w *watchers) add(ctx context.Context) <-chan *pb.BackendEvent {
+	ch := make(chan *pb.BackendEvent, watchBuffer)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		close(ch)
+		return ch
+	}
+	w.chs[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		w.remove(ch)
+	}()
+	return ch
+}
+
+// remove removes the watcher ch and closes it, if it wasn't already removed.
+func (w *watchers) remove(ch chan *pb.BackendEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.chs[ch]; !ok {
+		return
+	}
+	delete(w.chs, ch)
+	close(ch)
+}
+
+// send sends an event for b to all watchers. A watcher whose buffer is full is removed,
+// as it can no longer be told everything that happened.
+func (w *watchers) send(b Backend, et pb.BackendEventType, reason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.chs) == 0 {
+		return
+	}
+
+	pbb, err := backendToPB(b)
+	if err != nil {
+		log.Printf("could not send backend event: %s", err)
+		return
+	}
+	ev := &pb.BackendEvent{
+		Backend:      pbb,
+		Type:         et,
+		Reason:       reason,
+		TimeUnixNano: time.Now().UnixNano(),
+	}
+	switch et {
+	case pb.BackendEventType_BET_ADDED:
+		ev.Status = statusToPB(b.health())
+	case pb.BackendEventType_BET_HEALTHY:
+		ev.Status = pb.BackendStatus_BS_HEALTHY
+	case pb.BackendEventType_BET_SICK:
+		ev.Status = pb.BackendStatus_BS_SICK
+	case pb.BackendEventType_BET_DRAINING:
+		ev.Status = pb.BackendStatus_BS_DRAINING
+	}
+
+	for ch := range w.chs {
+		select {
+		// Each watcher gets its own copy, as the receiver may change it.
+		case ch <- proto.Clone(ev).(*pb.BackendEvent):
+		default:
+			log.Printf("pool health watcher fell behind, dropping it")
+			delete(w.chs, ch)
+			close(ch)
+		}
+	}
+}
+
+// close removes all watchers. Watchers added after this are closed immediately.
+func (w *watchers) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	for ch := range w.chs {
+		delete(w.chs, ch)
+		close(ch)
+	}
+}
+
+// Watch implements Pool.Watch().
+func (s *P2C) Watch(ctx context.Context) <-chan *pb.BackendEvent {
+	return s.watchers.add(ctx)
+}
+
+// backendToPB converts b to a *pb.Backend.
+func backendToPB(b Backend) (*pb.Backend, error) {
+	if wb, ok := b.(*weightedBackend); ok {
+		b = wb.Backend
+	}
+	switch v := b.(type) {
+	case *IPBackend:
+		return &pb.Backend{
+			Backend: &pb.Backend_IpBackend{
+				IpBackend: &pb.IPBackend{
+					Ip:      v.ip.String(),
+					Port:    v.port,
+					UrlPath: v.urlPath,
+				},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("an unknown backend type found(%T)", b)
+}
+
+// statusToPB converts hs to a pb.BackendStatus.
+func statusToPB(hs healthState) pb.BackendStatus {
+	switch hs {
+	case healthy:
+		return pb.BackendStatus_BS_HEALTHY
+	case sick:
+		return pb.BackendStatus_BS_SICK
+	case draining:
+		return pb.BackendStatus_BS_DRAINING
+	}
+	return pb.BackendStatus_BS_UNKNOWN
+}
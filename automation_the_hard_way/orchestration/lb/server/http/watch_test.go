@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+// nextEvent returns the next event on ch. ok is false if ch was closed.
+func nextEvent(t *testing.T, ch <-chan *pb.BackendEvent) (ev *pb.BackendEvent, ok bool) {
+	t.Helper()
+
+	select {
+	case ev, ok = <-ch:
+		return ev, ok
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a backend event")
+	}
+	return nil, false
+}
+
+func TestWatch(t *testing.T) {
+	sc := &switchCheck{}
+	p := newP2C(t, sc.check)
+	b := newBackend(t, 8000)
+
+	// Both watchers see every event.
+	watches := []<-chan *pb.BackendEvent{p.Watch(context.Background()), p.Watch(context.Background())}
+
+	type want struct {
+		et     pb.BackendEventType
+		status pb.BackendStatus
+		reason string // A substring of the event's reason.
+	}
+	steps := []struct {
+		desc string
+		do   func() error
+		want want
+	}{
+		{
+			desc: "add",
+			do:   func() error { return p.Add(context.Background(), b) },
+			want: want{et: pb.BackendEventType_BET_ADDED, status: pb.BackendStatus_BS_HEALTHY},
+		},
+		{
+			desc: "fails health check",
+			do: func() error {
+				sc.fail.Store(true)
+				p.healthChecks(context.Background())
+				return nil
+			},
+			want: want{et: pb.BackendEventType_BET_SICK, status: pb.BackendStatus_BS_SICK, reason: "check failed"},
+		},
+		{
+			desc: "passes health check",
+			do: func() error {
+				sc.fail.Store(false)
+				p.healthChecks(context.Background())
+				return nil
+			},
+			want: want{et: pb.BackendEventType_BET_HEALTHY, status: pb.BackendStatus_BS_HEALTHY},
+		},
+		{
+			desc: "drain",
+			do: func() error {
+				_, err := p.Drain(context.Background(), b)
+				return err
+			},
+			want: want{et: pb.BackendEventType_BET_DRAINING, status: pb.BackendStatus_BS_DRAINING},
+		},
+		{
+			desc: "remove",
+			do:   func() error { return p.Remove(context.Background(), b) },
+			want: want{et: pb.BackendEventType_BET_REMOVED},
+		},
+		{
+			desc: "restore a sick backend",
+			do: func() error {
+				sc.fail.Store(true)
+				return p.Restore(context.Background(), b)
+			},
+			want: want{et: pb.BackendEventType_BET_ADDED, status: pb.BackendStatus_BS_SICK, reason: "restored"},
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("TestWatch(%s): got err == %s, want err == nil", step.desc, err)
+		}
+		for i, ch := range watches {
+			ev, ok := nextEvent(t, ch)
+			if !ok {
+				t.Fatalf("TestWatch(%s): watcher %d: channel closed", step.desc, i)
+			}
+			if ev.Type != step.want.et || ev.Status != step.want.status {
+				t.Errorf("TestWatch(%s): watcher %d: got event %s/%s, want %s/%s", step.desc, i, ev.Type, ev.Status, step.want.et, step.want.status)
+			}
+			if !strings.Contains(ev.Reason, step.want.reason) {
+				t.Errorf("TestWatch(%s): watcher %d: got reason %q, want it to contain %q", step.desc, i, ev.Reason, step.want.reason)
+			}
+			if got := ev.GetBackend().GetIpBackend().GetPort(); got != 8000 {
+				t.Errorf("TestWatch(%s): watcher %d: got event for port %d, want 8000", step.desc, i, got)
+			}
+		}
+	}
+}
+
+func TestWatchClosed(t *testing.T) {
+	tests := []struct {
+		desc string
+		// closePool ends the watch by closing the pool instead of cancelling the context.
+		closePool bool
+	}{
+		{desc: "context cancelled"},
+		{desc: "pool closed", closePool: true},
+	}
+
+	for _, test := range tests {
+		p, err := NewP2C(passCheck, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := p.Watch(ctx)
+
+		if test.closePool {
+			p.Close()
+		} else {
+			cancel()
+		}
+		if _, ok := nextEvent(t, ch); ok {
+			t.Errorf("TestWatchClosed(%s): got an event, want the channel closed", test.desc)
+		}
+
+		if !test.closePool {
+			p.Close()
+		}
+		cancel()
+	}
+
+	// Watching a closed pool returns a closed channel.
+	p, err := NewP2C(passCheck, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+	if _, ok := nextEvent(t, p.Watch(context.Background())); ok {
+		t.Errorf("TestWatchClosed: got an event after Close(), want the channel closed")
+	}
+}
+
+func TestWatchSlow(t *testing.T) {
+	p := newP2C(t, passCheck)
+	b := newBackend(t, 8000)
+
+	slow := p.Watch(context.Background())
+	fast := p.Watch(context.Background())
+	fastDone := make(chan int)
+	go func() {
+		n := 0
+		for range fast {
+			n++
+			if n == watchBuffer+1 {
+				break
+			}
+		}
+		fastDone <- n
+	}()
+
+	for i := 0; i < watchBuffer+1; i++ {
+		p.watchers.send(b, pb.BackendEventType_BET_HEALTHY, "")
+		// Give the fast watcher time to keep up.
+		if i%10 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The slow watcher gets what fit in its buffer and is then dropped.
+	got := 0
+	for range slow {
+		got++
+	}
+	if got != watchBuffer {
+		t.Errorf("TestWatchSlow: slow watcher got %d events, want %d", got, watchBuffer)
+	}
+
+	select {
+	case n := <-fastDone:
+		if n != watchBuffer+1 {
+			t.Errorf("TestWatchSlow: fast watcher got %d events, want %d", n, watchBuffer+1)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("TestWatchSlow: fast watcher did not get all the events")
+	}
+}
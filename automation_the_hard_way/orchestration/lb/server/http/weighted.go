@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
+)
+
+// DefaultWeight is the weight of a backend added to a Weighted pool without a weight.
+const DefaultWeight = 100
+
+// WeightedPool is a Pool whose backends receive traffic in proportion to their weight.
+type WeightedPool interface {
+	Pool
+
+	// AddWeighted adds a new Backend to the pool with weight. The Backend must be healthy.
+	AddWeighted(ctx context.Context, b Backend, weight int32) error
+	// SetWeight changes the weight of a Backend in the pool.
+	SetWeight(ctx context.Context, b Backend, weight int32) error
+}
+
+// Weighted implements WeightedPool. Each request is sent to a healthy backend picked
+// at random, where a backend's chance of being picked is its weight divided by the
+// sum of the weights of all healthy backends. This is used to send a small amount of
+// traffic to a canary and ramp it up. Health checking and draining work the same as P2C.
+type Weighted struct {
+	*P2C
+
+	mu      sync.Mutex
+	weights map[string]int32 // keyed by the backend's url
+}
+
+// NewWeighted creates a new Weighted instance. hc is the health check
+// to perform on the backend to make sure its healthy and interval is how often to do
+// the health check.
+func NewWeighted(hc HealthCheck, interval time.Duration) (*Weighted, error) {
+	p, err := NewP2C(hc, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &Weighted{P2C: p, weights: map[string]int32{}}, nil
+}
+
+// Add implements Pool.Add(). The backend is added with DefaultWeight.
+func (w *Weighted) Add(ctx context.Context, b Backend) error {
+	return w.AddWeighted(ctx, b, DefaultWeight)
+}
+
+// AddWeighted implements WeightedPool.AddWeighted().
+func (w *Weighted) AddWeighted(ctx context.Context, b Backend, weight int32) error {
+	if weight < 1 {
+		return fmt.Errorf("weight(%d) must be at least 1", weight)
+	}
+
+	// The weight is only set once the backend is added, so adding a backend that is
+	// already in the pool can't change or remove its weight.
+	if err := w.P2C.Add(ctx, b); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.weights[b.url().String()] = weight
+	w.mu.Unlock()
+	return nil
+}
+
+// Restore implements Pool.Restore(). The backend is restored with DefaultWeight, use
+// SetWeight() to change it.
+func (w *Weighted) Restore(ctx context.Context, b Backend) error {
+	if err := w.P2C.Restore(ctx, b); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.weights[b.url().String()] = DefaultWeight
+	w.mu.Unlock()
+	return nil
+}
+
+// SetWeight implements WeightedPool.SetWeight().
+func (w *Weighted) SetWeight(ctx context.Context, b Backend, weight int32) error {
+	if weight < 1 {
+		return fmt.Errorf("weight(%d) must be at least 1", weight)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.weights[b.url().String()]; !ok {
+		return fmt.Errorf("could not find backend(%s)", b.url().String())
+	}
+	w.weights[b.url().String()] = weight
+	return nil
+}
+
+// Remove implements Pool.Remove().
+func (w *Weighted) Remove(ctx context.Context, b Backend) error {
+	if err := w.P2C.Remove(ctx, b); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	delete(w.weights, b.url().String())
+	w.mu.Unlock()
+	return nil
+}
+
+// Health implements Pool.Health(). Each backend's weight is included.
+func (w *Weighted) Health(ctx context.Context, req *pb.PoolHealthReq) (*pb.PoolHealth, error) {
+	ph, err := w.P2C.Health(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, bh := range ph.Backends {
+		if ip := bh.Backend.GetIpBackend(); ip != nil {
+			b, err := NewIPBackend(net.ParseIP(ip.Ip), ip.Port, ip.UrlPath)
+			if err != nil {
+				return nil, err
+			}
+			bh.Weight = w.weights[b.url().String()]
+		}
+	}
+	return ph, nil
+}
+
+// ServeHTTP implements Pool.ServeHTTP().
+func (w *Weighted) ServeHTTP(wr http.ResponseWriter, r *http.Request) {
+	w.serve(wr, r, w.pick)
+}
+
+// pick implements picker. A backend has a weight of 0 if it was just added and its
+// weight isn't set yet, or was removed while we were picking. It is only picked if all
+// the backends have a weight of 0.
+func (w *Weighted) pick(backs []*weightedBackend, r *http.Request) *weightedBackend {
+	weights := make([]int32, len(backs))
+	var total int32
+	w.mu.Lock()
+	for i, b := range backs {
+		weights[i] = w.weights[b.url().String()]
+		total += weights[i]
+	}
+	w.mu.Unlock()
+
+	if total == 0 {
+		return backs[rand.Intn(len(backs))]
+	}
+
+	n := rand.Int31n(total)
+
+	for i, b := range backs {
+		n -= weights[i]
+		if n < 0 {
+			return b
+		}
+	}
+	return backs[len(backs)-1]
+}
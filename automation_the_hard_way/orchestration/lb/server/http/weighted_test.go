@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// passCheck is a HealthCheck that always passes.
+func passCheck(ctx context.Context, endpoint string) error {
+	return nil
+}
+
+// newBackend returns an IPBackend for 127.0.0.1 at port.
+func newBackend(t *testing.T, port int32) *IPBackend {
+	t.Helper()
+
+	b, err := NewIPBackend(net.ParseIP("127.0.0.1"), port, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// newWeighted returns a Weighted pool that is closed when the test ends.
+func newWeighted(t *testing.T) *Weighted {
+	t.Helper()
+
+	w, err := NewWeighted(passCheck, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func TestWeightedAddExisting(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		desc string
+		add  func(w *Weighted, b Backend) error
+	}{
+		{
+			desc: "AddWeighted",
+			add:  func(w *Weighted, b Backend) error { return w.AddWeighted(ctx, b, 1) },
+		},
+		{
+			desc: "Add",
+			add:  func(w *Weighted, b Backend) error { return w.Add(ctx, b) },
+		},
+		{
+			desc: "Restore",
+			add:  func(w *Weighted, b Backend) error { return w.Restore(ctx, b) },
+		},
+	}
+
+	for _, test := range tests {
+		w := newWeighted(t)
+		if err := w.AddWeighted(ctx, newBackend(t, 80), 50); err != nil {
+			t.Fatalf("TestWeightedAddExisting(%s): AddWeighted(): got err == %s", test.desc, err)
+		}
+
+		// The same backend, as the orchestration would send it again.
+		if err := test.add(w, newBackend(t, 80)); err == nil {
+			t.Errorf("TestWeightedAddExisting(%s): got err == nil, want err != nil", test.desc)
+		}
+
+		if got := w.weights[newBackend(t, 80).url().String()]; got != 50 {
+			t.Errorf("TestWeightedAddExisting(%s): got weight %d, want 50", test.desc, got)
+		}
+		if err := w.SetWeight(ctx, newBackend(t, 80), 100); err != nil {
+			t.Errorf("TestWeightedAddExisting(%s): SetWeight(): got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
+
+func TestWeightedAddDrained(t *testing.T) {
+	ctx := context.Background()
+	w := newWeighted(t)
+	b := newBackend(t, 80)
+
+	if err := w.AddWeighted(ctx, b, 50); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Drain(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding a drained backend puts it back into service with its new weight.
+	if err := w.AddWeighted(ctx, newBackend(t, 80), 10); err != nil {
+		t.Fatalf("TestWeightedAddDrained: got err == %s, want err == nil", err)
+	}
+	if got := w.weights[b.url().String()]; got != 10 {
+		t.Errorf("TestWeightedAddDrained: got weight %d, want 10", got)
+	}
+}
+
+// pickShares calls pick n times with backs and returns the share of the picks each
+// backend got.
+func pickShares(pick picker, backs []*weightedBackend, n int) []float64 {
+	index := map[*weightedBackend]int{}
+	for i, b := range backs {
+		index[b] = i
+	}
+
+	counts := make([]int, len(backs))
+	for i := 0; i < n; i++ {
+		counts[index[pick(backs, httptest.NewRequest("GET", "/", nil))]]++
+	}
+	shares := make([]float64, len(backs))
+	for i, c := range counts {
+		shares[i] = float64(c) / float64(n)
+	}
+	return shares
+}
+
+// closeTo returns true if got is within 0.03 of want.
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) <= 0.03
+}
+
+func TestWeightedPick(t *testing.T) {
+	tests := []struct {
+		desc    string
+		weights []int32
+		want    []float64
+	}{
+		{
+			desc:    "equal weights",
+			weights: []int32{100, 100},
+			want:    []float64{0.5, 0.5},
+		},
+		{
+			desc:    "canary at 10 percent",
+			weights: []int32{10, 45, 45},
+			want:    []float64{0.1, 0.45, 0.45},
+		},
+		{
+			desc:    "backend without a weight",
+			weights: []int32{0, 100},
+			want:    []float64{0, 1},
+		},
+		{
+			desc:    "no backend has a weight",
+			weights: []int32{0, 0},
+			want:    []float64{0.5, 0.5},
+		},
+	}
+
+	for _, test := range tests {
+		w := newWeighted(t)
+		var backs []*weightedBackend
+		for i, weight := range test.weights {
+			b := newBackend(t, int32(8000+i))
+			if weight > 0 {
+				w.weights[b.url().String()] = weight
+			}
+			backs = append(backs, &weightedBackend{Backend: b})
+		}
+
+		got := pickShares(w.pick, backs, 10000)
+		for i := range got {
+			if !closeTo(got[i], test.want[i]) {
+				t.Errorf("TestWeightedPick(%s): backend %d got %.3f of requests, want %.3f", test.desc, i, got[i], test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Package metrics provides setup of metrics that can be used internally to measure the load balancer.
+All metrics for the load balancer are defined here and other packages use this package to grab the metrics
+and use them. This package will also report any metric that is not used in the first 10 seconds after the
+app has started to prevent useless metrics from existing, as all metrics should be grabbed by that time.
+
+In a package you want to set metrics, you can do it as follows:
+
+	var requests metric.Int64Counter
+
+	func init() {
+		requests = metrics.Get.Int64("pool-requests")
+	}
+	...
+
+	func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+		...
+		requests.Add(r.Context(), 1, metric.WithAttributes(attribute.String("pattern", h.pattern)))
+		...
+	}
+
+To cause metrics to be exported, in package main():
+
+	func main() {
+		...
+		stop, err := metrics.Start(ctx, metrics.OTELGRPC{Addr: "ip:port"}, metrics.Prometheus{})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer stop()
+
+		http.Handle("/metrics", metrics.Handler())
+		...
+	}
+*/
+package metrics
+
+import (
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Attribute keys used with our metrics.
+const (
+	// PatternKey is the pattern of the pool.
+	PatternKey = "pattern"
+	// CodeKey is the class of the HTTP status code, such as "2xx".
+	CodeKey = "code"
+	// BackendKey is the URL of the backend.
+	BackendKey = "backend"
+	// StateKey is the health state a backend changed to.
+	StateKey = "state"
+)
+
+type metricType int
+
+const (
+	unknown     = 0
+	mtInt64     = 1
+	mtInt64Hist = 2
+	mtInt64UD   = 3
+)
+
+type metricDef struct {
+	mtype metricType
+	name  string
+	desc  string
+}
+
+var metrics = []metricDef{
+	// Histograms
+	{mtInt64Hist, "pool-latency", "The latency of a request served by a pool in milliseconds"},
+	// Counters
+	{mtInt64, "pool-requests", "The total requests served by a pool"},
+	{mtInt64, "pool-errors", "The total requests served by a pool that had a 5xx status code"},
+	{mtInt64, "backend-transitions", "The total times a backend changed health state"},
+	{mtInt64, "backend-ejections", "The total times a backend was ejected by outlier detection"},
+	{mtInt64, "backend-retries", "The total requests that failed on a backend and were retried on another"},
+
+	// UpDown Counters
+	{mtInt64UD, "backend-inflight", "The amount of requests currently being sent to a backend"},
+}
+
+// Meter is the meter for the load balancer.
+var Meter = otel.GetMeterProvider().Meter("lb")
+
+// Get is used to lookup metrics by name.
+var Get = newLookups()
+
+var unusedMetricsTmpl = template.Must(
+	template.New("").Parse(
+		`
+The following metrics appear to be unused:
+{{- range .}}
+	{{.}}
+{{- end }}
+`,
+	),
+)
+
+// Lookups provides lookups for metrics based on their names.
+type Lookups struct {
+	mtInt64Hist map[string]metric.Int64Histogram
+	mtInt64UD   map[string]metric.Int64UpDownCounter
+	mtInt64     map[string]metric.Int64Counter
+
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+func newLookups() *Lookups {
+	l := &Lookups{
+		mtInt64Hist: map[string]metric.Int64Histogram{},
+		mtInt64:     map[string]metric.Int64Counter{},
+		mtInt64UD:   map[string]metric.Int64UpDownCounter{},
+		used:        map[string]bool{},
+	}
+
+	exists := map[string]bool{}
+	for _, m := range metrics {
+		if m.mtype == unknown {
+			log.Fatalf("metric with type(%v) cannot be added", m.mtype)
+		}
+		if m.name == "" {
+			log.Fatalf("metric cannot be missing a name")
+		}
+		if m.desc == "" {
+			log.Fatalf("metric cannot be missing a desc")
+		}
+		if exists[m.name] {
+			log.Fatalf("cannot have two metrics with same name(%s)", m.name)
+		}
+		exists[m.name] = true
+
+		switch m.mtype {
+		case mtInt64Hist:
+			l.mtInt64Hist[m.name] = Must(Meter.Int64Histogram(m.name, metric.WithDescription(m.desc)))
+		case mtInt64UD:
+			l.mtInt64UD[m.name] = Must(Meter.Int64UpDownCounter(m.name, metric.WithDescription(m.desc)))
+		case mtInt64:
+			l.mtInt64[m.name] = Must(Meter.Int64Counter(m.name, metric.WithDescription(m.desc)))
+		default:
+			log.Fatalf("bug: we defined a metric type(%v) without adding support", m.mtype)
+		}
+	}
+	go func() {
+		time.Sleep(10 * time.Second)
+		unused := l.unused()
+		if len(unused) == 0 {
+			return
+		}
+		s := strings.Builder{}
+		if err := unusedMetricsTmpl.Execute(&s, unused); err != nil {
+			log.Fatalf("unusedMetricTmpl execute error: %s", err)
+		}
+		log.Println(s.String())
+	}()
+	return l
+}
+
+// Int64 grabs the Int64Counter metric named "s". If not found, this exits the program.
+func (l *Lookups) Int64(s string) metric.Int64Counter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m, ok := l.mtInt64[s]
+	if !ok {
+		log.Fatalf("int64 metric(%s) is not defined", s)
+	}
+	l.used[s] = true
+	return m
+}
+
+// Int64UD grabs the Int64UpDownCounter metric named "s". If not found, this exits the program.
+func (l *Lookups) Int64UD(s string) metric.Int64UpDownCounter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m, ok := l.mtInt64UD[s]
+	if !ok {
+		log.Fatalf("int64ud metric(%s) is not defined", s)
+	}
+	l.used[s] = true
+	return m
+}
+
+// Int64Hist grabs the Int64Histogram metric named "s". If not found, this exits the program.
+func (l *Lookups) Int64Hist(s string) metric.Int64Histogram {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m, ok := l.mtInt64Hist[s]
+	if !ok {
+		log.Fatalf("int64 histogram metric(%s) is not defined", s)
+	}
+	l.used[s] = true
+	return m
+}
+
+func (l *Lookups) unused() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unused := []string{}
+	for k := range l.mtInt64Hist {
+		if !l.used[k] {
+			unused = append(unused, k)
+		}
+	}
+	for k := range l.mtInt64 {
+		if !l.used[k] {
+			unused = append(unused, k)
+		}
+	}
+	for k := range l.mtInt64UD {
+		if !l.used[k] {
+			unused = append(unused, k)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// Must returns m or exits the program if err != nil.
+func Must[T any](m T, err error) T {
+	if err != nil {
+		log.Fatalf("failed to create meter: %s", err)
+	}
+	return m
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// registry is where the Prometheus controller registers our metrics.
+var registry = prometheus.NewRegistry()
+
+// Controller represents the controller to send metrics to.
+type Controller interface {
+	isController()
+}
+
+// OTELGRPC represents exporting to an OTEL collector over gRPC.
+type OTELGRPC struct {
+	// Addr is the address of the collector.
+	Addr string
+}
+
+func (o OTELGRPC) isController() {}
+
+// Prometheus represents exposing metrics for a Prometheus server to scrape.
+// Serve Handler() to expose them.
+type Prometheus struct{}
+
+func (p Prometheus) isController() {}
+
+// Handler returns an http.Handler that serves our metrics in the Prometheus format.
+// This only has metrics if Start() was called with Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Stop is used to stop OTEL metric handling.
+type Stop func()
+
+// Start is used to start OTEL metric handling. Metrics are sent to all controllers.
+func Start(ctx context.Context, controllers ...Controller) (Stop, error) {
+	if len(controllers) == 0 {
+		return nil, fmt.Errorf("must provide at least one Controller")
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithProcess(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service", "lb")),
+		resource.WithHost(),
+		resource.WithAttributes(
+			// the service name used to display metrics in backends
+			semconv.ServiceNameKey.String("lb"),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, c := range controllers {
+		r, err := newReader(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdkmetric.WithReader(r))
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(meterProvider)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+
+		// pushes any last exports to the receiver
+		if err := meterProvider.Shutdown(ctx); err != nil {
+			otel.Handle(err)
+		}
+	}, nil
+}
+
+func newReader(ctx context.Context, c Controller) (sdkmetric.Reader, error) {
+	switch v := c.(type) {
+	case OTELGRPC:
+		exp, err := otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithInsecure(),
+			otlpmetricgrpc.WithEndpoint(v.Addr),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exp), nil
+	case Prometheus:
+		return otelprom.New(otelprom.WithRegisterer(registry))
+	}
+	return nil, fmt.Errorf("%T is not a valid Controller", c)
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/events"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/journal"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/client"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/workflow"
 	"github.com/rodaine/table"
@@ -19,6 +24,16 @@ import (
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
+var (
+	journalPath = flag.String("journal", "", "The path to write the rollout journal to. Defaults to rollout-[time].journal in the current directory")
+	resume      = flag.String("resume", "", "The path to a journal from a previous rollout to resume from. New entries are appended to it")
+	eventsPath  = flag.String("events", "", "If set, rollout events are written as JSON lines to this path. Use - for stdout")
+	reportPath  = flag.String("report", "", "If set, the final report is written as JSON to this path. Use - for stdout")
+	retries     = flag.Int("retries", 3, "The number of times to retry failed endpoints after the workflow completes")
+	retryWait   = flag.Duration("retry_wait", 5*time.Minute, "How long to wait before each retry of failed endpoints")
+	insecureKey = flag.Bool("insecure_ignore_host_key", false, "Do not verify the SSH host keys of backends, only for testing. Same as the config's Transport.InsecureIgnoreHostKey")
+)
+
 var (
 	headerFmt = color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt = color.New(color.FgYellow).SprintfFunc()
@@ -27,20 +42,40 @@ var (
 func main() {
 	flag.Parse()
 
+	// If machine readable output is going to stdout, send our human output to stderr.
+	if *eventsPath == "-" || *reportPath == "-" {
+		color.Output = os.Stderr
+	}
+
 	ctx := context.Background()
 
-	wf, lb, err := setup()
+	wf, lb, j, err := setup()
 	if err != nil {
 		color.Red("Setup Error: %s", err)
 		os.Exit(1)
 	}
+	color.Blue("Journaling rollout to: %s", j.Path())
 
-	// If the load balancer doesn't have pool "/", set one up.
+	if *eventsPath != "" {
+		w, err := openOutput(*eventsPath)
+		if err != nil {
+			color.Red("Could not open events output: %s", err)
+			exit(wf, j, 1)
+		}
+		wf.Events = events.JSONLines(w)
+	}
+
+	// If the load balancer doesn't have pool "/", set one up. If our waves ramp traffic,
+	// it must be a weighted pool.
 	if _, err := lb.PoolHealth(ctx, "/", false, false); err != nil {
+		pt := pb.PoolType_PT_P2C
+		if wf.Weighted() {
+			pt = pb.PoolType_PT_WEIGHTED
+		}
 		err := lb.AddPool(
 			ctx,
 			"/",
-			pb.PoolType_PT_P2C,
+			pt,
 			client.HealthChecks{
 				HealthChecks: []client.HealthCheck{
 					client.StatusCheck{
@@ -53,98 +88,247 @@ func main() {
 		)
 		if err != nil {
 			color.Red("LB did not have pool `/` and couldn't create it: %s", err)
-			os.Exit(1)
+			exit(wf, j, 1)
 		}
 		color.Blue("Setup LB with pool `/`")
+	} else if wf.Weighted() {
+		if err := checkWeighted(ctx, lb, "/"); err != nil {
+			color.Red("LB pool `/` can't be used: %s", err)
+			exit(wf, j, 1)
+		}
+	}
+
+	if wf.RollingBack() {
+		color.Red("Journal shows a rollback was in progress, resuming the rollback")
+		rollback(ctx, wf)
+		finish(wf, j, 1)
 	}
 
 	color.Red("Starting Workflow")
 	if err := wf.Run(ctx); err != nil {
-		status := wf.Status()
-		color.Red("Workflow Failed: %s", status.EndState)
-
-		var tbl table.Table
-		if status.EndState == workflow.ESPreconditionFailure {
-			tbl = table.New("Failed State", "Error")
-			tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
-			tbl.AddRow("Precondition", err)
-		} else {
-			tbl = table.New("Endpoint", "Failed State", "Error")
-			tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
-			for _, action := range status.Failures {
-				tbl.AddRow(action.Endpoint, action.Failure(), action.Err())
-			}
+		if wf.ShouldRollback() {
+			rollback(ctx, wf)
 		}
-		tbl.Print()
-		os.Exit(1)
+		finish(wf, j, 1)
 	}
 
-	status := wf.Status()
-	if len(status.Failures) == 0 {
-		color.Blue("Workflow Completed with no failures")
-		os.Exit(0)
+	for i := 0; i < *retries && len(wf.Status().Failures) > 0; i++ {
+		color.Blue("Workflow has %d failed actions", len(wf.Status().Failures))
+		color.Green("Retrying failed actions in %v...", *retryWait)
+		time.Sleep(*retryWait)
+		color.Green("Executing failed actions...")
+
+		wf.RetryFailed(ctx)
 	}
+	finish(wf, j, 0)
+}
 
-	color.Blue("Workflow Completed, but had %d failed actions", len(status.Failures))
-	for i := 0; i < 3; i++ {
-		color.Green("Retrying failed actions in 5 minutes...")
-		time.Sleep(5 * time.Minute)
-		fmt.Println("Executing failed actions...")
+// rollback rolls back all endpoints the workflow touched.
+// checkWeighted returns an error if the load balancer's pool at pattern is not PT_WEIGHTED.
+// Otherwise we would only find out when the first upgraded backend is added with a weight.
+func checkWeighted(ctx context.Context, lb *client.Client, pattern string) error {
+	cfg, err := lb.ExportConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get the LB config: %w", err)
+	}
+	for _, p := range cfg.Pools {
+		if p.Pattern != pattern {
+			continue
+		}
+		if p.PoolType != pb.PoolType_PT_WEIGHTED {
+			return fmt.Errorf("pool(%s) is %s, but the config's waves ramp traffic, which requires %s", pattern, p.PoolType, pb.PoolType_PT_WEIGHTED)
+		}
+		return nil
+	}
+	return fmt.Errorf("pool(%s) is not in the LB config", pattern)
+}
 
-		wf.RetryFailed(ctx)
-		status = wf.Status()
-		if len(status.Failures) == 0 {
-			break
+func rollback(ctx context.Context, wf *workflow.Workflow) {
+	color.Red("Starting Rollback")
+	if err := wf.Rollback(ctx); err != nil {
+		color.Red("Rollback Failed: %s", err)
+		return
+	}
+	color.Blue("Rollback Completed with no failures")
+}
+
+// finish outputs the workflow's report and exits with code.
+func finish(wf *workflow.Workflow, j *journal.Journal, code int) {
+	r := wf.Report()
+	printReport(r)
+
+	if *reportPath != "" {
+		if err := writeReport(r); err != nil {
+			color.Red("Could not write report: %s", err)
+			code = 1
 		}
-		color.Blue("Workflow Failures retry, but had %d failed actions", len(status.Failures))
 	}
-	status = wf.Status()
-	if len(status.Failures) == 0 {
+	exit(wf, j, code)
+}
+
+// exit closes the workflow and journal and exits with code. Deferred calls don't run
+// with os.Exit(), so everything that must be closed is closed here.
+func exit(wf *workflow.Workflow, j *journal.Journal, code int) {
+	if err := wf.Close(); err != nil {
+		color.Red("Could not close workflow: %s", err)
+	}
+	if err := j.Close(); err != nil {
+		color.Red("Could not close journal(%s): %s", j.Path(), err)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+// printReport prints a human readable version of the report.
+func printReport(r workflow.Report) {
+	switch {
+	case r.EndState != workflow.ESSuccess.String():
+		color.Red("Workflow Failed in wave(%s): %s: %s", r.Wave, r.EndState, r.Err)
+	case len(r.Failures) == 0:
 		color.Blue("Workflow Completed with no failures")
-		os.Exit(0)
+	default:
+		color.Blue("Workflow Completed but with %d failures after %d retries", len(r.Failures), r.Retries)
 	}
+	color.Blue("%d of %d endpoints upgraded", r.Done, r.Endpoints)
 
-	color.Blue("Workflow Completed but with %d failures after retries exhausted")
-	tbl := table.New("Endpoint", "Failed State", "Error")
-	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	if len(r.Failures) > 0 {
+		printEndpoints(r.Failures)
+	}
+	if r.CanaryReport != nil {
+		color.Red("Canary analysis report:")
+		fmt.Fprint(color.Output, r.CanaryReport)
+	}
+	if r.RollbackState != workflow.RSNone.String() {
+		color.Yellow("Rollback: %s", r.RollbackState)
+		if len(r.RollbackFailures) > 0 {
+			printEndpoints(r.RollbackFailures)
+		}
+	}
+}
 
-	for _, action := range status.Failures {
-		failure := action.Failure()
-		if failure == "" {
-			failure = "during setup"
+// printEndpoints prints a table of endpoint failures.
+func printEndpoints(reports []workflow.EndpointReport) {
+	tbl := table.New("Endpoint", "Failed State", "Error")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt).WithWriter(color.Output)
+	for _, er := range reports {
+		state := er.State
+		if state == "" {
+			state = "during setup"
 		}
-		tbl.AddRow(action.Endpoint, failure, action.Err)
+		tbl.AddRow(er.Endpoint, state, er.Err)
 	}
 	tbl.Print()
-	os.Exit(0)
 }
 
-func setup() (*workflow.Workflow, *client.Client, error) {
+// writeReport writes the report as JSON to --report.
+func writeReport(r workflow.Report) error {
+	b, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if *reportPath == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(*reportPath, b, 0640)
+}
+
+// openOutput opens p for writing, or returns os.Stdout if p is "-".
+func openOutput(p string) (io.Writer, error) {
+	if p == "-" {
+		return os.Stdout, nil
+	}
+	return os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+}
+
+func setup() (*workflow.Workflow, *client.Client, *journal.Journal, error) {
 	if len(flag.Args()) != 1 {
-		return nil, nil, fmt.Errorf("must have argument to service file")
+		return nil, nil, nil, fmt.Errorf("must have argument to service file")
 	}
 
 	b, err := os.ReadFile(flag.Args()[0])
 	if err != nil {
-		return nil, nil, fmt.Errorf("can't open workflow configuration file: %w", err)
+		return nil, nil, nil, fmt.Errorf("can't open workflow configuration file: %w", err)
 	}
 
 	config := &config.Config{}
 	if err := json.Unmarshal(b, config); err != nil {
-		return nil, nil, fmt.Errorf("%q is misconfigured: %w", flag.Args()[0], err)
+		return nil, nil, nil, fmt.Errorf("%q is misconfigured: %w", flag.Args()[0], err)
+	}
+	if *insecureKey {
+		config.Transport.InsecureIgnoreHostKey = true
 	}
 	if err := config.Validate(); err != nil {
 		log.Println(string(b))
-		return nil, nil, fmt.Errorf("config file didn't validate: %w", err)
+		return nil, nil, nil, fmt.Errorf("config file didn't validate: %w", err)
+	}
+
+	j, last, err := openJournal()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	lb, err := client.New(config.LB)
 	if err != nil {
-		return nil, nil, fmt.Errorf("can't connected to LB(%s): %s", config.LB, err)
+		return nil, nil, nil, fmt.Errorf("can't connected to LB(%s): %s", config.LB, err)
+	}
+	wf, err := workflow.New(config, lb, j)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not create workflow: %w", err)
+	}
+	wf.Approver = approve
+	if last != nil {
+		if err := wf.Resume(last); err != nil {
+			return nil, nil, nil, fmt.Errorf("could not resume from journal(%s): %w", *resume, err)
+		}
+	}
+	return wf, lb, j, nil
+}
+
+// approve asks the user at the terminal to approve a wave before it starts.
+func approve(ctx context.Context, wave string) error {
+	color.Yellow("Wave(%s) requires approval. Type 'yes' to start it: ", wave)
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer <- strings.TrimSpace(line)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case a := <-answer:
+		if a != "yes" {
+			return fmt.Errorf("user answered %q", a)
+		}
+	}
+	return nil
+}
+
+// openJournal opens the journal we are resuming from if --resume was set. Otherwise it
+// creates a new journal. If we are resuming, the last entry for each endpoint is returned.
+func openJournal() (*journal.Journal, map[string]journal.Entry, error) {
+	if *resume != "" {
+		if *journalPath != "" {
+			return nil, nil, fmt.Errorf("cannot set both --journal and --resume")
+		}
+		j, last, err := journal.Open(*resume)
+		if err != nil {
+			return nil, nil, err
+		}
+		return j, last, nil
+	}
+
+	p := *journalPath
+	if p == "" {
+		p = fmt.Sprintf("rollout-%s.journal", time.Now().Format("20060102-150405"))
 	}
-	wf, err := workflow.New(config, lb)
+	j, err := journal.Create(p)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not create workflow: %w", err)
+		return nil, nil, err
 	}
-	return wf, lb, nil
+	return j, nil, nil
 }
@@ -0,0 +1,109 @@
+//go:build ds
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client/auth"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// NewAgent creates a new Agent Transport that connects over SSH to host and then to
+// the agent's domain socket. cfg.Port is the SSH port and defaults to 22.
+func NewAgent(cfg config.Transport, host string) (*Agent, error) {
+	port := cfg.PortOrDefault()
+
+	a, err := auth.FromKeyFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not get SSH auth: %w", err)
+	}
+	hostKey, err := hostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The agent's socket is in the home directory of the user we log in as.
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	c, err := client.NewWithConfig(
+		addr,
+		&ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{a},
+			HostKeyCallback: hostKey,
+			Timeout:         5 * time.Second,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to agent(%s): %w", addr, err)
+	}
+	return &Agent{addr: addr, client: c}, nil
+}
+
+// Agent implements Transport by using the system agent's HTTP API. This uses the
+// agent's exec, writeFile and stat APIs, which the agent only serves on its domain
+// socket when run with -allowRemoteExec.
+type Agent struct {
+	addr   string
+	client *client.Client
+}
+
+// Run implements Transport.Run().
+func (a *Agent) Run(ctx context.Context, cmd string) ([]byte, error) {
+	out, err := a.client.Exec(ctx, cmd)
+	if err != nil {
+		return out, fmt.Errorf("command(%s) on agent(%s) failed: %w", cmd, a.addr, err)
+	}
+	return out, nil
+}
+
+// CopyFile implements Transport.CopyFile(). The agent API sends the whole file in
+// one request, so r is read into memory.
+func (a *Agent) CopyFile(ctx context.Context, r io.Reader, p string, perm fs.FileMode) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read file content for(%s): %w", p, err)
+	}
+	if err := a.client.WriteFile(ctx, p, b, perm); err != nil {
+		return fmt.Errorf("could not copy file(%s) to agent(%s): %w", p, a.addr, err)
+	}
+	return nil
+}
+
+// Stat implements Transport.Stat().
+func (a *Agent) Stat(ctx context.Context, p string) (fs.FileInfo, error) {
+	resp, err := a.client.Stat(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("could not stat file(%s) on agent(%s): %w", p, a.addr, err)
+	}
+	if !resp.Exists {
+		return nil, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+	}
+	return fileInfo{
+		name:    path.Base(p),
+		size:    resp.Size,
+		mode:    fs.FileMode(resp.Mode),
+		modTime: time.Unix(0, resp.ModTime),
+	}, nil
+}
+
+// Close implements Transport.Close().
+func (a *Agent) Close() error {
+	return a.client.Close()
+}
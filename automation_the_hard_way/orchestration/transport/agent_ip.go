@@ -0,0 +1,16 @@
+//go:build !ds
+
+package transport
+
+import (
+	"fmt"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// NewAgent returns an error, as the agent only allows running commands and writing
+// files on its domain socket. Compile with "go build -tags ds" to use the agent
+// Transport, which reaches the socket over SSH.
+func NewAgent(cfg config.Transport, host string) (Transport, error) {
+	return nil, fmt.Errorf("the agent transport requires compiling with -tags ds, the agent does not allow remote exec over IP")
+}
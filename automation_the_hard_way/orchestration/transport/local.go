@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+)
+
+// Local implements Transport by running everything on this machine.
+type Local struct{}
+
+// Run implements Transport.Run().
+func (Local) Run(ctx context.Context, cmd string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "/bin/sh", "-c", cmd).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("command(%s) failed: %w", cmd, err)
+	}
+	return out, nil
+}
+
+// CopyFile implements Transport.CopyFile().
+func (Local) CopyFile(ctx context.Context, r io.Reader, path string, perm fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("could not open file(%s) for writing: %w", path, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("could not copy file(%s): %w", path, err)
+	}
+	return f.Close()
+}
+
+// Stat implements Transport.Stat().
+func (Local) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Close implements Transport.Close().
+func (Local) Close() error {
+	return nil
+}
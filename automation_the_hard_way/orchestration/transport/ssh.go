@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/agent/client/auth"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// SSH implements Transport by running commands over SSH and copying files with SFTP.
+// The connection is not made until it is needed.
+type SSH struct {
+	addr   string
+	config *ssh.ClientConfig
+
+	mu   sync.Mutex
+	conn *ssh.Client
+	sftp *sftp.Client
+}
+
+// NewSSH creates a new SSH Transport to host.
+func NewSSH(cfg config.Transport, host string) (*SSH, error) {
+	a, err := auth.FromKeyFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not get SSH auth: %w", err)
+	}
+
+	hostKey, err := hostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	return &SSH{
+		addr: net.JoinHostPort(host, strconv.Itoa(int(cfg.PortOrDefault()))),
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{a},
+			HostKeyCallback: hostKey,
+			Timeout:         5 * time.Second,
+		},
+	}, nil
+}
+
+// hostKeyCallback returns how to verify the host keys of backends. Keys are checked
+// against cfg.KnownHostsFile, or ~/.ssh/known_hosts if it isn't set, unless
+// cfg.InsecureIgnoreHostKey is set.
+func hostKeyCallback(cfg config.Transport) (ssh.HostKeyCallback, error) {
+	if cfg.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	p := cfg.KnownHostsFile
+	if p == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not find home directory for known_hosts file: %w", err)
+		}
+		p = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKey, err := knownhosts.New(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not read known_hosts file(%s), set KnownHostsFile or InsecureIgnoreHostKey: %w", p, err)
+	}
+	return hostKey, nil
+}
+
+// client returns the SSH and SFTP clients, dialing the host if we haven't yet.
+func (s *SSH) client() (*ssh.Client, *sftp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		return s.conn, s.sftp, nil
+	}
+
+	conn, err := ssh.Dial("tcp", s.addr, s.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not dial host(%s): %w", s.addr, err)
+	}
+	sc, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("could not start SFTP to host(%s): %w", s.addr, err)
+	}
+	s.conn, s.sftp = conn, sc
+	return s.conn, s.sftp, nil
+}
+
+// Run implements Transport.Run(). SSH does not always honor the kill signal we send
+// when ctx is cancelled, so this may still block until the command finishes.
+func (s *SSH) Run(ctx context.Context, cmd string) ([]byte, error) {
+	conn, _, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("could not open SSH session to host(%s): %w", s.addr, err)
+	}
+	defer sess.Close()
+
+	buff := &bytes.Buffer{}
+	sess.Stdout = buff
+	sess.Stderr = buff
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sess.Signal(ssh.SIGKILL)
+		case <-done:
+		}
+	}()
+
+	if err := sess.Run(cmd); err != nil {
+		return buff.Bytes(), fmt.Errorf("command(%s) on host(%s) failed: %w", cmd, s.addr, err)
+	}
+	return buff.Bytes(), nil
+}
+
+// CopyFile implements Transport.CopyFile().
+func (s *SSH) CopyFile(ctx context.Context, r io.Reader, path string, perm fs.FileMode) error {
+	_, sc, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	f, err := sc.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("could not open file(%s) on host(%s) for writing: %w", path, s.addr, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("could not copy file(%s) to host(%s): %w", path, s.addr, err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return fmt.Errorf("could not chmod file(%s) on host(%s): %w", path, s.addr, err)
+	}
+	return f.Close()
+}
+
+// Stat implements Transport.Stat().
+func (s *SSH) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
+	_, sc, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	fi, err := sc.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not stat file(%s) on host(%s): %w", path, s.addr, err)
+	}
+	return fi, nil
+}
+
+// Close implements Transport.Close().
+func (s *SSH) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	s.sftp.Close()
+	err := s.conn.Close()
+	s.conn, s.sftp = nil, nil
+	return err
+}
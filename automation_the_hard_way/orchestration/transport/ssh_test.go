@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	const host = "10.0.0.1:22"
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	known, other := newHostKey(t), newHostKey(t)
+
+	dir := t.TempDir()
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(host)}, known) + "\n"
+	if err := os.WriteFile(knownHosts, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default known_hosts file is in $HOME, which we point at an empty directory.
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		desc    string
+		cfg     config.Transport
+		key     ssh.PublicKey
+		wantErr bool // From hostKeyCallback().
+		wantBad bool // From the callback it returns.
+	}{
+		{
+			desc: "key in known_hosts",
+			cfg:  config.Transport{KnownHostsFile: knownHosts},
+			key:  known,
+		},
+		{
+			desc:    "key does not match known_hosts",
+			cfg:     config.Transport{KnownHostsFile: knownHosts},
+			key:     other,
+			wantBad: true,
+		},
+		{
+			desc:    "default known_hosts does not exist",
+			cfg:     config.Transport{},
+			wantErr: true,
+		},
+		{
+			desc: "host keys are ignored",
+			cfg:  config.Transport{InsecureIgnoreHostKey: true},
+			key:  other,
+		},
+	}
+
+	for _, test := range tests {
+		cb, err := hostKeyCallback(test.cfg)
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestHostKeyCallback(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.wantErr:
+			t.Errorf("TestHostKeyCallback(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+
+		err = cb(host, addr, test.key)
+		switch {
+		case err == nil && test.wantBad:
+			t.Errorf("TestHostKeyCallback(%s): callback got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantBad:
+			t.Errorf("TestHostKeyCallback(%s): callback got err == %s, want err == nil", test.desc, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Package transport provides the ways the orchestration can reach a backend to run
+commands and copy files.
+
+There are three implementations:
+
+  - Local runs everything on this machine. This is what the demo does, where all the
+    backends are on localhost.
+  - SSH runs commands over an SSH session and copies files with SFTP.
+  - Agent uses the system agent's HTTP API on its domain socket, reached over SSH.
+    This is only available when compiled with "-tags ds".
+
+Use New() to get the Transport the config asks for:
+
+	t, err := transport.New(cfg.Transport, "10.0.0.1")
+	if err != nil {
+		// Do something
+	}
+	defer t.Close()
+
+	out, err := t.Run(ctx, "ps -A")
+*/
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+)
+
+// Transport runs commands and copies files on a single backend.
+type Transport interface {
+	// Run runs cmd with /bin/sh on the backend and returns the combined stdout and
+	// stderr. If the command fails, the output is still returned along with the error.
+	Run(ctx context.Context, cmd string) ([]byte, error)
+	// CopyFile copies the content of r to path on the backend. If path exists it is
+	// truncated, otherwise it is created with perm.
+	CopyFile(ctx context.Context, r io.Reader, path string, perm fs.FileMode) error
+	// Stat returns information on the file at path. If the file does not exist,
+	// the error will wrap fs.ErrNotExist.
+	Stat(ctx context.Context, path string) (fs.FileInfo, error)
+	// Close closes any connection to the backend.
+	Close() error
+}
+
+// New creates the Transport for host that is described by cfg.
+func New(cfg config.Transport, host string) (Transport, error) {
+	switch cfg.TypeOrDefault() {
+	case "local":
+		return Local{}, nil
+	case "ssh":
+		return NewSSH(cfg, host)
+	case "agent":
+		return NewAgent(cfg, host)
+	}
+	return nil, fmt.Errorf("unknown transport type(%s)", cfg.Type)
+}
+
+// Quote quotes s so that it is passed as a single argument to /bin/sh.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fileInfo implements fs.FileInfo for transports that don't return one.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (f fileInfo) Name() string       { return f.name }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() fs.FileMode  { return f.mode }
+func (f fileInfo) ModTime() time.Time { return f.modTime }
+func (f fileInfo) IsDir() bool        { return f.mode.IsDir() }
+func (f fileInfo) Sys() any           { return nil }
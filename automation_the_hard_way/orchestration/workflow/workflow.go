@@ -13,15 +13,17 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/actions"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/analysis"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/events"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/journal"
 	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/client"
+	"github.com/johnsiilver/gofordevopsclass/design_for_chaos/es"
 
 	pb "github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/lb/proto"
 )
 
 // EndStates are the final states after a run of a workflow.
-//
-//go:generate stringer -type=endState
 type EndState int8
 
 const (
@@ -37,24 +39,135 @@ const (
 	// ESMaxFailures indicates that the workflow passed the canary phase, but failed
 	// at a later phase.
 	ESMaxFailures EndState = 4
+	// ESApprovalDenied indicates that a wave requiring approval was not approved.
+	ESApprovalDenied EndState = 5
+	// ESEmergencyStop indicates that the emergency stop for the workflow was not
+	// in the Go state when we started or changed to Stop while we were running.
+	ESEmergencyStop EndState = 6
+	// ESCancelled indicates that the Context passed to Run() was cancelled between waves.
+	ESCancelled EndState = 7
 )
 
+func (e EndState) String() string {
+	switch e {
+	case ESUnknown:
+		return "Unknown"
+	case ESSuccess:
+		return "Success"
+	case ESPreconditionFailure:
+		return "PreconditionFailure"
+	case ESCanaryFailure:
+		return "CanaryFailure"
+	case ESMaxFailures:
+		return "MaxFailures"
+	case ESApprovalDenied:
+		return "ApprovalDenied"
+	case ESEmergencyStop:
+		return "EmergencyStop"
+	case ESCancelled:
+		return "Cancelled"
+	}
+	return fmt.Sprintf("EndState(%d)", e)
+}
+
+// Approver is called before a wave that requires approval starts. If it returns
+// an error, the wave is not approved and the workflow stops.
+type Approver func(ctx context.Context, wave string) error
+
+// wave is a config.Wave with the actions assigned to it.
+type wave struct {
+	config.Wave
+
+	actions []*actions.Actions
+}
+
+// done returns true if all the actions in the wave are done.
+func (w *wave) done() bool {
+	for _, a := range w.actions {
+		if !a.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// RollbackState is the final state of a rollback.
+type RollbackState int8
+
+const (
+	// RSNone indicates that no rollback was done.
+	RSNone RollbackState = 0
+	// RSSuccess indicates that every endpoint that was touched was rolled back.
+	RSSuccess RollbackState = 1
+	// RSFailure indicates one or more endpoints could not be rolled back.
+	RSFailure RollbackState = 2
+)
+
+func (r RollbackState) String() string {
+	switch r {
+	case RSNone:
+		return "None"
+	case RSSuccess:
+		return "Success"
+	case RSFailure:
+		return "Failure"
+	}
+	return fmt.Sprintf("RollbackState(%d)", r)
+}
+
 // Workflow represents our rollout Workflow.
 type Workflow struct {
-	config *config.Config
-	lb     *client.Client
+	// Approver approves waves that require approval. This must be set if
+	// any wave requires approval.
+	Approver Approver
+	// Analyzer analyzes canary waves during their soak time. If the config has
+	// CanaryAnalysis set, New() sets this to an analysis.Metrics.
+	Analyzer analysis.Analyzer
+	// Events, if set, receives an Event for everything that happens in the workflow.
+	Events events.Handler
+
+	config  *config.Config
+	lb      *client.Client
+	journal *journal.Journal
+	// es is where we read the emergency stop named in the config from.
+	es *es.Reader
 
 	failures int32
 	endState EndState
+	err      error
+	retries  int
+	rbState  RollbackState
+	report   *analysis.Report
+
+	// esStopped is set when the emergency stop changes to Stop during Run().
+	esStopped atomic.Bool
 
 	actions []*actions.Actions
+	waves   []*wave
+	// wave is the name of the current wave.
+	wave atomic.Value // string
+	// inFlight are endpoints that a resumed journal shows were in the middle of
+	// being upgraded. These may not be in the load balancer.
+	inFlight map[string]bool
 }
 
-// New creates a new workflow.
-func New(config *config.Config, lb *client.Client) (*Workflow, error) {
+// New creates a new workflow. If j is not nil, all state transitions will be
+// recorded in the journal.
+func New(config *config.Config, lb *client.Client, j *journal.Journal) (*Workflow, error) {
 	wf := &Workflow{
-		config: config,
-		lb:     lb,
+		config:   config,
+		lb:       lb,
+		journal:  j,
+		es:       es.Data,
+		inFlight: map[string]bool{},
+	}
+	wf.wave.Store("")
+	if config.CanaryAnalysis != nil {
+		a, err := analysis.NewMetrics(*config.CanaryAnalysis)
+		if err != nil {
+			return nil, err
+		}
+		wf.Analyzer = a
 	}
 	if err := wf.buildActions(); err != nil {
 		return nil, err
@@ -62,10 +175,42 @@ func New(config *config.Config, lb *client.Client) (*Workflow, error) {
 	return wf, nil
 }
 
-// Runs runs our workflow on the supplied "actions" doing "canaryNum" canaries,
-// then running "concurrency" number of actions that will stop at "maxFailures" number of
-// failurea.
+// Resume sets up the workflow to continue from the last journal entry for each
+// endpoint, as returned by journal.Open(). Endpoints that completed are not run again
+// and endpoints that were in the middle of an upgrade restart at their last state.
+// This must be called before Run().
+func (w *Workflow) Resume(last map[string]journal.Entry) error {
+	for _, a := range w.actions {
+		e, ok := last[a.Endpoint()]
+		if !ok {
+			continue
+		}
+		if err := a.Resume(e); err != nil {
+			return err
+		}
+		if !a.Done() {
+			w.inFlight[a.Endpoint()] = true
+		}
+	}
+	return nil
+}
+
+// Runs runs our workflow by executing each wave in the config's Plan() in order. Each
+// wave runs up to its Concurrency number of actions at a time and stops the workflow if it
+// has more than its MaxFailures. Waves that require approval wait on the Approver and each
+// wave soaks for its soak time before the next wave starts.
 func (w *Workflow) Run(ctx context.Context) error {
+	w.err = w.run(ctx)
+
+	e := events.Event{Type: events.EndState, Wave: w.wave.Load().(string), EndState: w.endState.String()}
+	if w.err != nil {
+		e.Err = w.err.Error()
+	}
+	w.emit(e)
+	return w.err
+}
+
+func (w *Workflow) run(ctx context.Context) error {
 	// Run a local precondition to make sure our load balancer is in a healthy state.
 	preCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	err := w.checkLBState(preCtx)
@@ -74,29 +219,273 @@ func (w *Workflow) Run(ctx context.Context) error {
 		w.endState = ESPreconditionFailure
 		return fmt.Errorf("checkLBState precondition fail: %s", err)
 	}
+	for _, wv := range w.waves {
+		if wv.Approval && w.Approver == nil {
+			w.endState = ESPreconditionFailure
+			return fmt.Errorf("wave(%s) requires approval, but no Approver was set", wv.Name)
+		}
+	}
+
+	// stopCtx is cancelled if the emergency stop changes to Stop. We don't use this for
+	// actions, as we want those to finish instead of leaving an endpoint half upgraded.
+	stopCtx, stopCancel := context.WithCancel(ctx)
+	defer stopCancel()
+	if err := w.watchES(stopCtx, stopCancel); err != nil {
+		w.endState = ESEmergencyStop
+		return err
+	}
+
+	for i, wv := range w.waves {
+		if wv.done() {
+			// This only happens when resuming. The journal doesn't record how far the
+			// wave's ramp got before we stopped, so finish it.
+			if err := w.finishRamp(stopCtx, wv); err != nil {
+				return w.rampFailed(wv, err)
+			}
+			continue
+		}
+		w.wave.Store(wv.Name)
+
+		if wv.Approval {
+			if err := w.Approver(stopCtx, wv.Name); err != nil {
+				if w.esStopped.Load() {
+					return w.emergencyStop()
+				}
+				w.endState = ESApprovalDenied
+				return fmt.Errorf("wave(%s) was not approved: %w", wv.Name, err)
+			}
+		}
+
+		color.Green("Starting wave(%s) with %d endpoints", wv.Name, len(wv.actions))
+		w.emit(events.Event{Type: events.WaveStarted, Wave: wv.Name})
+		failures := w.runWave(ctx, wv)
+		if w.esStopped.Load() {
+			return w.emergencyStop()
+		}
+		if failures > wv.MaxFailures {
+			if wv.Canary {
+				w.endState = ESCanaryFailure
+				return fmt.Errorf("canary wave(%s) had %d failures", wv.Name, failures)
+			}
+			w.endState = ESMaxFailures
+			return fmt.Errorf("wave(%s) exceeded max failures", wv.Name)
+		}
+
+		if err := w.ramp(stopCtx, wv); err != nil {
+			return w.rampFailed(wv, err)
+		}
+		w.emit(events.Event{Type: events.WaveCompleted, Wave: wv.Name})
+
+		if wv.Canary && w.Analyzer != nil {
+			if err := w.analyze(stopCtx, i); err != nil {
+				if w.esStopped.Load() {
+					return w.emergencyStop()
+				}
+				w.endState = ESCanaryFailure
+				return fmt.Errorf("canary wave(%s) failed analysis: %w", wv.Name, err)
+			}
+			continue
+		}
+
+		if i < len(w.waves)-1 && wv.SoakSecs > 0 {
+			if err := w.soak(stopCtx, wv); err != nil {
+				return err
+			}
+		}
+	}
 
-	// Run our canaries one at a time. Any problem stops the workflow.
-	for i := 0; i < len(w.actions) && int32(i) < w.config.CanaryNum; i++ {
-		color.Green("Running canary on: %s", w.actions[i].Endpoint())
-		ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-		err := w.actions[i].Run(ctx)
+	w.endState = ESSuccess
+	return nil
+}
+
+// soak waits for the wave's soak time. If ctx is done first, the end state is set to why
+// and an error is returned.
+func (w *Workflow) soak(ctx context.Context, wv *wave) error {
+	color.Yellow("Soaking after wave(%s) for %v", wv.Name, wv.Soak())
+	select {
+	case <-ctx.Done():
+		if w.esStopped.Load() {
+			return w.emergencyStop()
+		}
+		w.endState = ESCancelled
+		return fmt.Errorf("cancelled while soaking after wave(%s): %w", wv.Name, ctx.Err())
+	case <-time.After(wv.Soak()):
+	}
+	return nil
+}
+
+// watchES subscribes to the emergency stop named in the config. If it is not in the Go
+// state, this returns an error. If it changes to Stop, esStopped is set and stop is called.
+// If the config doesn't name an emergency stop, this does nothing.
+func (w *Workflow) watchES(ctx context.Context, stop context.CancelFunc) error {
+	if w.config.ES == "" {
+		return nil
+	}
+
+	ch, cancel := w.es.Subscribe(w.config.ES)
+	if status := <-ch; status != es.Go {
 		cancel()
-		if err != nil {
-			w.endState = ESCanaryFailure
-			return fmt.Errorf("canary failure on endpoint(%s): %w", w.actions[i].Endpoint(), err)
+		return fmt.Errorf("emergency stop(%s) is in effect, will not start", w.config.ES)
+	}
+
+	go func() {
+		defer cancel()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			// After the initial Go, the only status we can receive is Stop.
+			color.Red("Emergency stop(%s) received, finishing in-flight actions", w.config.ES)
+			w.esStopped.Store(true)
+			stop()
+		}
+	}()
+	return nil
+}
+
+// emit sends e to our Events handler, if set.
+func (w *Workflow) emit(e events.Event) {
+	if w.Events == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	w.Events(e)
+}
+
+// emergencyStop sets our end state to ESEmergencyStop and returns the error for Run().
+func (w *Workflow) emergencyStop() error {
+	w.endState = ESEmergencyStop
+	return fmt.Errorf("emergency stop(%s) went into effect", w.config.ES)
+}
+
+// analyze runs the Analyzer against wave i during its soak time, using endpoints from
+// later waves as the controls.
+func (w *Workflow) analyze(ctx context.Context, i int) error {
+	wv := w.waves[i]
+
+	maxControls := 3
+	if w.config.CanaryAnalysis != nil && w.config.CanaryAnalysis.Controls > 0 {
+		maxControls = int(w.config.CanaryAnalysis.Controls)
+	}
+
+	var canaries, controls []string
+	for _, a := range wv.actions {
+		canaries = append(canaries, a.Endpoint())
+	}
+	for _, later := range w.waves[i+1:] {
+		for _, a := range later.actions {
+			if len(controls) == maxControls {
+				break
+			}
+			if !a.Touched() {
+				controls = append(controls, a.Endpoint())
+			}
 		}
-		color.Yellow("Sleeping after canary for 1 minutes")
-		time.Sleep(1 * time.Minute)
 	}
 
-	limit := make(chan struct{}, w.config.Concurrency)
+	color.Yellow("Analyzing wave(%s) against %d controls for %v", wv.Name, len(controls), wv.Soak())
+	report, err := w.Analyzer.Analyze(ctx, canaries, controls, wv.Soak())
+	if err != nil {
+		return fmt.Errorf("analysis could not be completed: %w", err)
+	}
+	w.report = &report
+	if !report.Passed() {
+		return fmt.Errorf("canaries did not pass analysis:\n%s", report)
+	}
+	return nil
+}
+
+// ramp steps the load balancer weights of the wave's upgraded backends through the rest
+// of the wave's Ramp, waiting RampStep() before each step.
+func (w *Workflow) ramp(ctx context.Context, wv *wave) error {
+	if len(wv.Ramp) < 2 {
+		return nil
+	}
+
+	for _, weight := range wv.Ramp[1:] {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wv.RampStep()):
+		}
+
+		if err := w.setWeights(ctx, wv, weight); err != nil {
+			return err
+		}
+	}
+	w.rampDone(wv)
+	return nil
+}
+
+// finishRamp sets the weight of the wave's upgraded backends to the last weight in the
+// wave's Ramp without stepping through the others.
+func (w *Workflow) finishRamp(ctx context.Context, wv *wave) error {
+	if len(wv.Ramp) < 2 {
+		// Backends were added at their final weight.
+		return nil
+	}
+	if err := w.setWeights(ctx, wv, wv.Ramp[len(wv.Ramp)-1]); err != nil {
+		return err
+	}
+	w.rampDone(wv)
+	return nil
+}
+
+// rampDone has the wave's backends that haven't been upgraded, such as ones that RetryFailed()
+// will retry, added to the load balancer at the last weight in the wave's Ramp instead of the
+// first, as the ramp is over.
+func (w *Workflow) rampDone(wv *wave) {
+	weight := wv.Ramp[len(wv.Ramp)-1]
+	for _, a := range wv.actions {
+		if !a.Done() {
+			a.Weight = weight
+		}
+	}
+}
+
+// setWeights sets the weight of the wave's upgraded backends in the load balancer.
+func (w *Workflow) setWeights(ctx context.Context, wv *wave, weight int32) error {
+	color.Yellow("Setting weight of wave(%s) backends to %d", wv.Name, weight)
+	for _, a := range wv.actions {
+		if !a.Done() {
+			continue
+		}
+		if err := a.SetWeight(ctx, weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rampFailed sets the end state for a wave that could not ramp traffic and returns the error
+// Run() should return.
+func (w *Workflow) rampFailed(wv *wave, err error) error {
+	if w.esStopped.Load() {
+		return w.emergencyStop()
+	}
+	if wv.Canary {
+		w.endState = ESCanaryFailure
+	} else {
+		w.endState = ESMaxFailures
+	}
+	return fmt.Errorf("wave(%s) could not ramp traffic: %w", wv.Name, err)
+}
+
+// runWave runs the actions in a wave and returns the number of failures.
+func (w *Workflow) runWave(ctx context.Context, wv *wave) int32 {
+	limit := make(chan struct{}, wv.Concurrency)
 	wg := sync.WaitGroup{}
 
-	// Run the rest of the actions, with a limit to our concurrency.
-	for i := w.config.CanaryNum; int(i) < len(w.actions); i++ {
-		i := i
+	var failures int32
+	for _, a := range wv.actions {
+		a := a
+		if a.Done() {
+			continue
+		}
 		limit <- struct{}{}
-		if atomic.LoadInt32(&w.failures) > w.config.MaxFailures {
+		if atomic.LoadInt32(&failures) > wv.MaxFailures || w.esStopped.Load() {
 			break
 		}
 		wg.Add(1)
@@ -104,42 +493,46 @@ func (w *Workflow) Run(ctx context.Context) error {
 			defer func() { <-limit }()
 			defer wg.Done()
 			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+			defer cancel()
 
-			color.Green("Upgrading endpoint: %s", w.actions[i].Endpoint())
-			err := w.actions[i].Run(ctx)
-			cancel()
-			if err != nil {
-				color.Red("Endpoint(%s) had upgrade error: %s", w.actions[i].Endpoint(), err)
+			color.Green("Upgrading endpoint(%s) in wave(%s)", a.Endpoint(), wv.Name)
+			if err := a.Run(ctx); err != nil {
+				color.Red("Endpoint(%s) had upgrade error: %s", a.Endpoint(), err)
+				atomic.AddInt32(&failures, 1)
 				atomic.AddInt32(&w.failures, 1)
 			}
 		}()
 	}
 	wg.Wait()
 
-	if atomic.LoadInt32(&w.failures) > w.config.MaxFailures {
-		w.endState = ESMaxFailures
-		return errors.New("exceeded max failures")
-	}
-	w.endState = ESSuccess
-	return nil
+	return atomic.LoadInt32(&failures)
 }
 
-// RetryFailed retries all failed actiona. This is only used if
+// RetryFailed retries all failed actions. This can only be called after Run()
+// ends in ESSuccess.
 func (w *Workflow) RetryFailed(ctx context.Context) {
 	if w.endState != ESSuccess {
 		panic("retrlyFailed cannot be called unless the workflow was a success")
 	}
+	if w.config.ES != "" && w.es.Status(w.config.ES) != es.Go {
+		color.Red("Emergency stop(%s) is in effect, not retrying failed actions", w.config.ES)
+		return
+	}
 
 	ws := w.Status()
+	w.retries++
 
 	wg := sync.WaitGroup{}
 
 	for i := 0; i < len(ws.Failures); i++ {
+		i := i
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 
+			w.emit(events.Event{Type: events.Retry, Endpoint: ws.Failures[i].Endpoint(), Attempt: w.retries})
+
 			err := ws.Failures[i].Run(ctx)
 			cancel()
 			if err == nil {
@@ -150,6 +543,97 @@ func (w *Workflow) RetryFailed(ctx context.Context) {
 	wg.Wait()
 }
 
+// Close closes the connections to all the endpoints.
+func (w *Workflow) Close() error {
+	var errs []error
+	for _, a := range w.actions {
+		if err := a.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("endpoint(%s): %w", a.Endpoint(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Weighted returns true if any wave ramps traffic to its backends, which requires
+// the pool to be PT_WEIGHTED.
+func (w *Workflow) Weighted() bool {
+	return w.config.Weighted()
+}
+
+// RollingBack returns true if Resume() found that a rollback was in progress. In that
+// case, Rollback() should be called instead of Run().
+func (w *Workflow) RollingBack() bool {
+	for _, a := range w.actions {
+		if a.RollingBack() {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRollback returns true if the config asks for a rollback on failure and Run()
+// ended in ESCanaryFailure or ESMaxFailures.
+func (w *Workflow) ShouldRollback() bool {
+	if !w.config.RollbackOnFailure {
+		return false
+	}
+	switch w.endState {
+	case ESCanaryFailure, ESMaxFailures:
+		return true
+	}
+	return false
+}
+
+// Rollback returns every endpoint that the workflow touched to its previous binary and
+// puts it back into the load balancer. This is used after Run() fails with ESCanaryFailure
+// or ESMaxFailures. Up to config.Concurrency endpoints are rolled back at a time and a
+// failure does not stop the rollback of other endpoints.
+func (w *Workflow) Rollback(ctx context.Context) error {
+	concurrency := w.config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limit := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	var failures int32
+	for _, a := range w.actions {
+		a := a
+		if !a.Touched() {
+			continue
+		}
+		limit <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-limit }()
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+			defer cancel()
+
+			color.Yellow("Rolling back endpoint: %s", a.Endpoint())
+			if err := a.Rollback(ctx); err != nil {
+				color.Red("Endpoint(%s) had rollback error: %s", a.Endpoint(), err)
+				atomic.AddInt32(&failures, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	w.rbState = RSSuccess
+	if failures > 0 {
+		w.rbState = RSFailure
+		err = fmt.Errorf("%d endpoints failed to rollback", failures)
+	}
+
+	e := events.Event{Type: events.RollbackEnd, RollbackState: w.rbState.String()}
+	if err != nil {
+		e.Err = err.Error()
+	}
+	w.emit(e)
+	return err
+}
+
 // checkLBState checks the load balancer pool for "pattern" contains all "endpoints"
 // in a healthy state.
 func (w *Workflow) checkLBState(ctx context.Context) error {
@@ -166,17 +650,14 @@ func (w *Workflow) checkLBState(ctx context.Context) error {
 		}
 		return nil
 	// We do some extra checks to make sure that while all the nodes in the pool are working,
-	// we should have the backends that the config tells us are there.
+	// we should have the backends that the config tells us are there. If we are resuming,
+	// backends that were in flight may have been removed from the pool.
 	case pb.PoolStatus_PS_FULL:
-		if len(w.config.Backends) != len(ph.Backends) {
-			log.Println(w.config.Backends)
-			log.Println(ph.Backends)
-			return fmt.Errorf("expected backends(%d) != found backends(%d)", len(w.config.Backends), len(ph.Backends))
-		}
 		m := map[string]bool{}
 		for _, e := range w.config.Backends {
 			m[e] = true
 		}
+		found := map[string]bool{}
 		for _, hb := range ph.Backends {
 			switch {
 			case hb.Backend.GetIpBackend() != nil:
@@ -184,10 +665,18 @@ func (w *Workflow) checkLBState(ctx context.Context) error {
 				if !m[backendToString(b)] {
 					return fmt.Errorf("configured backend %q not in config file", b.Ip)
 				}
+				found[backendToString(b)] = true
 			default:
 				return fmt.Errorf("we only support IPBackend, got %T", hb.Backend)
 			}
 		}
+		for _, e := range w.config.Backends {
+			if !found[e] && !w.inFlight[e] {
+				log.Println(w.config.Backends)
+				log.Println(ph.Backends)
+				return fmt.Errorf("expected backends(%d) != found backends(%d)", len(w.config.Backends), len(ph.Backends))
+			}
+		}
 	default:
 		return fmt.Errorf("pool was not at full health, was %s", ph.Status)
 	}
@@ -198,14 +687,32 @@ func backendToString(back *pb.IPBackend) string {
 	return net.JoinHostPort(back.Ip, strconv.Itoa(int(back.Port)))
 }
 
-// buildActions builds actions from our configuration file.
+// buildActions builds actions from our configuration file and assigns them to waves.
 func (w *Workflow) buildActions() error {
-	for _, b := range w.config.Backends {
-		a, err := actions.New(b, w.config, w.lb)
-		if err != nil {
-			return err
+	assigned, err := w.config.Assign()
+	if err != nil {
+		return err
+	}
+
+	for i, wc := range w.config.Plan() {
+		wv := &wave{Wave: wc}
+		for _, b := range assigned[i] {
+			a, err := actions.New(b, w.config, w.lb, w.journal)
+			if err != nil {
+				return err
+			}
+			if len(wv.Ramp) > 0 {
+				a.Weight = wv.Ramp[0]
+			}
+			name := wv.Name
+			a.Events = func(e events.Event) {
+				e.Wave = name
+				w.emit(e)
+			}
+			w.actions = append(w.actions, a)
+			wv.actions = append(wv.actions, a)
 		}
-		w.actions = append(w.actions, a)
+		w.waves = append(w.waves, wv)
 	}
 	return nil
 }
@@ -216,15 +723,99 @@ type Status struct {
 	Failures []*actions.Actions
 	// EndState is the EndState of the workflow.
 	EndState EndState
+	// Wave is the name of the wave that is executing. If the workflow has
+	// ended, this is the last wave that was executed.
+	Wave string
+	// CanaryReport is the report from the last canary analysis, if one was done.
+	CanaryReport *analysis.Report
+	// RollbackFailures is a list of actions that failed to rollback.
+	RollbackFailures []*actions.Actions
+	// RollbackState is the state of the rollback, if one was done.
+	RollbackState RollbackState
 }
 
 // Status will return the workflow's status after run() has completed
 func (w *Workflow) Status() Status {
-	ws := Status{EndState: w.endState}
+	ws := Status{
+		EndState:      w.endState,
+		Wave:          w.wave.Load().(string),
+		CanaryReport:  w.report,
+		RollbackState: w.rbState,
+	}
 	for _, a := range w.actions {
 		if a.Err() != nil {
 			ws.Failures = append(ws.Failures, a)
 		}
+		if a.RollbackErr() != nil {
+			ws.RollbackFailures = append(ws.RollbackFailures, a)
+		}
 	}
 	return ws
 }
+
+// Report is a machine readable report of the workflow.
+type Report struct {
+	// EndState is the EndState of the workflow.
+	EndState string
+	// Err is the error Run() returned, if any.
+	Err string `json:",omitempty"`
+	// Wave is the last wave that was executed.
+	Wave string
+	// Endpoints is the number of endpoints in the workflow.
+	Endpoints int
+	// Done is the number of endpoints that were upgraded.
+	Done int
+	// Retries is the number of times RetryFailed() was called.
+	Retries int
+	// Failures are the endpoints that failed to upgrade.
+	Failures []EndpointReport `json:",omitempty"`
+	// CanaryReport is the report from the last canary analysis, if one was done.
+	CanaryReport *analysis.Report `json:",omitempty"`
+	// RollbackState is the state of the rollback, if one was done.
+	RollbackState string
+	// RollbackFailures are the endpoints that failed to rollback.
+	RollbackFailures []EndpointReport `json:",omitempty"`
+}
+
+// EndpointReport is the report for an endpoint that failed.
+type EndpointReport struct {
+	// Endpoint is the endpoint that failed.
+	Endpoint string
+	// State is the state the endpoint failed in. This is empty if the endpoint failed
+	// before entering a state.
+	State string
+	// Err is the error the endpoint failed with.
+	Err string
+}
+
+// Report returns a Report of the workflow.
+func (w *Workflow) Report() Report {
+	ws := w.Status()
+
+	r := Report{
+		EndState:      ws.EndState.String(),
+		Wave:          ws.Wave,
+		Endpoints:     len(w.actions),
+		Retries:       w.retries,
+		CanaryReport:  ws.CanaryReport,
+		RollbackState: ws.RollbackState.String(),
+	}
+	if w.err != nil {
+		r.Err = w.err.Error()
+	}
+	for _, a := range w.actions {
+		if a.Done() {
+			r.Done++
+		}
+	}
+	for _, a := range ws.Failures {
+		r.Failures = append(r.Failures, EndpointReport{Endpoint: a.Endpoint(), State: a.Failure(), Err: a.Err().Error()})
+	}
+	for _, a := range ws.RollbackFailures {
+		r.RollbackFailures = append(
+			r.RollbackFailures,
+			EndpointReport{Endpoint: a.Endpoint(), State: a.RollbackFailure(), Err: a.RollbackErr().Error()},
+		)
+	}
+	return r
+}
@@ -0,0 +1,265 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/config"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/events"
+	"github.com/johnsiilver/gofordevopsclass/automation_the_hard_way/orchestration/journal"
+	"github.com/johnsiilver/gofordevopsclass/design_for_chaos/es"
+)
+
+// writeES writes configs/es.json in the current directory with the rollout entry set to status.
+func writeES(t *testing.T, status es.Status) {
+	t.Helper()
+
+	if err := os.MkdirAll("configs", 0700); err != nil {
+		t.Fatal(err)
+	}
+	b := []byte(`{"Name": "rollout", "Status": "` + string(status) + `"}`)
+	if err := os.WriteFile(filepath.Join("configs", "es.json"), b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chdirTemp changes to a temporary directory for the rest of the test, as an es.Reader reads
+// configs/es.json from the current directory.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestWatchESNotGo(t *testing.T) {
+	w := &Workflow{config: &config.Config{ES: "doesNotExist"}, es: es.Data}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := w.watchES(ctx, cancel); err == nil {
+		t.Errorf("TestWatchESNotGo: got err == nil, want err != nil")
+	}
+}
+
+// TestWatchESStop sends a Stop to a subscribed workflow.
+func TestWatchESStop(t *testing.T) {
+	chdirTemp(t)
+	writeES(t, es.Go)
+
+	r, err := es.NewReader(10 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status("rollout") != es.Go {
+		t.Fatalf("TestWatchESStop: emergency stop is not Go")
+	}
+
+	w := &Workflow{config: &config.Config{ES: "rollout"}, es: r}
+	stopCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	if err := w.watchES(stopCtx, stop); err != nil {
+		t.Fatalf("TestWatchESStop: watchES(): got err == %s, want err == nil", err)
+	}
+	if w.esStopped.Load() {
+		t.Fatalf("TestWatchESStop: workflow was stopped before the emergency stop changed")
+	}
+
+	writeES(t, es.Stop)
+	select {
+	case <-stopCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("TestWatchESStop: workflow was not stopped")
+	}
+	if !w.esStopped.Load() {
+		t.Errorf("TestWatchESStop: esStopped was not set")
+	}
+
+	// The watcher cancels its subscription after the Stop. Give it time to do so, as
+	// that used to panic.
+	time.Sleep(100 * time.Millisecond)
+
+	// Once stopped, a new workflow must refuse to start.
+	w = &Workflow{config: &config.Config{ES: "rollout"}, es: r}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := w.watchES(ctx, cancel); err == nil {
+		t.Errorf("TestWatchESStop: watchES() after Stop: got err == nil, want err != nil")
+	}
+}
+
+// recorder records the events a workflow emits.
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) handle(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// find returns the events of type et.
+func (r *recorder) find(et events.Type) []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []events.Event
+	for _, e := range r.events {
+		if e.Type == et {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func TestReport(t *testing.T) {
+	b := []string{"127.0.0.1:8000", "127.0.0.1:8001", "127.0.0.1:8002"}
+	cfg := &config.Config{
+		// The binary doesn't exist, so any endpoint that is run fails before it is touched.
+		Src:      filepath.Join(t.TempDir(), "missing"),
+		LB:       "127.0.0.1:8081",
+		Pattern:  "/",
+		Backends: b,
+		Waves: []config.Wave{
+			{Name: "canary", Count: 1, Concurrency: 1, Canary: true},
+			{Name: "rest", Concurrency: 2, MaxFailures: 2},
+		},
+	}
+	w, err := New(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	w.Events = rec.handle
+
+	// The canary was upgraded before we stopped and the rest failed.
+	if err := w.Resume(map[string]journal.Entry{b[0]: {Endpoint: b[0], State: journal.StateDone, Dst: "/app/svc"}}); err != nil {
+		t.Fatal(err)
+	}
+	w.wave.Store("rest")
+	w.runWave(context.Background(), w.waves[1])
+	w.endState = ESSuccess
+	w.RetryFailed(context.Background())
+
+	got := w.Report()
+	if got.EndState != "Success" || got.Wave != "rest" || got.Endpoints != 3 || got.Done != 1 || got.Retries != 1 {
+		t.Errorf("TestReport: got %+v, want EndState Success, Wave rest, 3 Endpoints, 1 Done and 1 Retry", got)
+	}
+	if got.RollbackState != "None" || len(got.RollbackFailures) != 0 {
+		t.Errorf("TestReport: got rollback %s with %d failures, want None with 0", got.RollbackState, len(got.RollbackFailures))
+	}
+	if len(got.Failures) != 2 {
+		t.Fatalf("TestReport: got %d failures, want 2", len(got.Failures))
+	}
+	for _, f := range got.Failures {
+		if f.Endpoint != b[1] && f.Endpoint != b[2] {
+			t.Errorf("TestReport: got failure for endpoint %s, which was not run", f.Endpoint)
+		}
+		if !strings.Contains(f.Err, "cannot open binary") {
+			t.Errorf("TestReport: endpoint %s: got err %q, want it to say the binary could not be opened", f.Endpoint, f.Err)
+		}
+	}
+
+	// Each failure is an event in its wave, once for the run and once for the retry.
+	failed := rec.find(events.EndpointFailed)
+	if len(failed) != 4 {
+		t.Errorf("TestReport: got %d %s events, want 4", len(failed), events.EndpointFailed)
+	}
+	for _, e := range failed {
+		if e.Wave != "rest" || e.Err == "" || e.Time.IsZero() {
+			t.Errorf("TestReport: got event %+v, want it in wave rest with an Err and Time", e)
+		}
+	}
+	for _, e := range rec.find(events.Retry) {
+		if e.Attempt != 1 {
+			t.Errorf("TestReport: got %s event with Attempt %d, want 1", events.Retry, e.Attempt)
+		}
+	}
+	if n := len(rec.find(events.Retry)); n != 2 {
+		t.Errorf("TestReport: got %d %s events, want 2", n, events.Retry)
+	}
+
+	// The JSON report leaves out what didn't happen.
+	j, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(j, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"Err", "CanaryReport", "RollbackFailures"} {
+		if _, ok := fields[field]; ok {
+			t.Errorf("TestReport: JSON report has %s, want it left out: %s", field, j)
+		}
+	}
+}
+
+func TestSoak(t *testing.T) {
+	tests := []struct {
+		desc string
+		// cancel cancels the soak before it ends. If esStop is set, it is because of the
+		// emergency stop.
+		cancel  bool
+		esStop  bool
+		want    EndState
+		wantErr bool
+	}{
+		{
+			desc: "soak completes",
+			want: ESUnknown,
+		},
+		{
+			desc:    "cancelled",
+			cancel:  true,
+			want:    ESCancelled,
+			wantErr: true,
+		},
+		{
+			desc:    "emergency stop",
+			cancel:  true,
+			esStop:  true,
+			want:    ESEmergencyStop,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		w := &Workflow{config: &config.Config{ES: "rollout"}}
+		wv := &wave{Wave: config.Wave{Name: "canary", SoakSecs: 1}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if test.cancel {
+			w.esStopped.Store(test.esStop)
+			cancel()
+		}
+		err := w.soak(ctx, wv)
+		cancel()
+
+		switch {
+		case err == nil && test.wantErr:
+			t.Errorf("TestSoak(%s): got err == nil, want err != nil", test.desc)
+		case err != nil && !test.wantErr:
+			t.Errorf("TestSoak(%s): got err == %s, want err == nil", test.desc, err)
+		}
+		if w.endState != test.want {
+			t.Errorf("TestSoak(%s): got end state %s, want %s", test.desc, w.endState, test.want)
+		}
+	}
+}
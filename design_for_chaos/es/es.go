@@ -33,7 +33,7 @@ import (
 var Data *Reader
 
 func init() {
-	d, err := newReader()
+	d, err := NewReader(10 * time.Second)
 	if err != nil {
 		panic(err)
 	}
@@ -77,18 +77,27 @@ func (i Info) validate() error {
 // Reader reads the es.json file at intervals and makes the data
 // and changes to the data available.
 type Reader struct {
-	entries atomic.Value // map[string]Info
+	interval time.Duration
+	entries  atomic.Value // map[string]Info
 
 	mu          sync.Mutex
 	subscribers map[string][]chan Status
 }
 
-func newReader() (*Reader, error) {
-	r := &Reader{subscribers: map[string][]chan Status{}}
+// NewReader creates a Reader that reads the es.json file every interval. Most programs
+// should use Data instead. This is for programs, such as tests, that need to see changes
+// sooner.
+func NewReader(interval time.Duration) (*Reader, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval(%v) must be positive", interval)
+	}
+	r := &Reader{interval: interval, subscribers: map[string][]chan Status{}}
 
+	// If we can't load the file, everything is in the Stop state until we can.
+	// This keeps programs that import us but never use the ES from dying.
 	m, err := r.load()
 	if err != nil {
-		return nil, err
+		log.Printf("emergency stop is in effect for everything: %s", err)
 	}
 	r.entries.Store(m)
 
@@ -124,16 +133,25 @@ func (r *Reader) Subscribe(name string) (chan Status, Cancel) {
 	r.subscribers[name] = l
 
 	// This removes the channel when it is no longer needed because no
-	// one is listening.
+	// one is listening. If a Stop was sent, sendStop() has already removed
+	// it, so there may be nothing to do.
 	cancel := func() {
 		r.mu.Lock()
 		defer r.mu.Unlock()
-		l := make([]chan Status, 0, len(r.subscribers[name])-1)
-		for _, stored := range r.subscribers[name] {
-			if stored == ch {
+		stored := r.subscribers[name]
+		if len(stored) == 0 {
+			return
+		}
+		l := make([]chan Status, 0, len(stored))
+		for _, s := range stored {
+			if s == ch {
 				continue
 			}
-			l = append(l, stored)
+			l = append(l, s)
+		}
+		if len(l) == 0 {
+			delete(r.subscribers, name)
+			return
 		}
 		r.subscribers[name] = l
 	}
@@ -152,19 +170,24 @@ func (r *Reader) Status(name string) Status {
 	return Stop
 }
 
-// loop reads the es.json file in every 10 seconds and updates subscribers of changes
+// loop reads the es.json file in every interval and updates subscribers of changes
 // from Go status to Stop status.
 func (r *Reader) loop() {
-	for _ = range time.Tick(10 * time.Second) {
+	for _ = range time.Tick(r.interval) {
 		newInfos, err := r.load()
 		if err != nil {
 			// This means the file was malformed or missing. In these
 			// cases we stop all work.
 			r.mu.Lock()
+			names := make([]string, 0, len(r.subscribers))
 			for name := range r.subscribers {
-				r.sendStop(name)
+				names = append(names, name)
 			}
 			r.mu.Unlock()
+			for _, name := range names {
+				r.sendStop(name)
+			}
+			r.entries.Store(map[string]Info{})
 			continue
 		}
 		for name, info := range r.entries.Load().(map[string]Info) {
@@ -206,8 +229,8 @@ func (r *Reader) sendStop(name string) {
 			break
 		}
 		close(ch)
-		delete(r.subscribers, name)
 	}
+	delete(r.subscribers, name)
 }
 
 // load loads the current es.json values and returns them. Any error is an indication
@@ -0,0 +1,76 @@
+package es
+
+import (
+	"testing"
+)
+
+// testReader returns a Reader with entries, without the loop that reads es.json.
+func testReader(entries map[string]Info) *Reader {
+	r := &Reader{subscribers: map[string][]chan Status{}}
+	r.entries.Store(entries)
+	return r
+}
+
+func TestSubscribeStop(t *testing.T) {
+	r := testReader(map[string]Info{"rollout": {Name: "rollout", Status: Go}})
+
+	ch1, cancel1 := r.Subscribe("rollout")
+	ch2, cancel2 := r.Subscribe("rollout")
+	for i, ch := range []chan Status{ch1, ch2} {
+		if got := <-ch; got != Go {
+			t.Fatalf("TestSubscribeStop: subscriber %d: got initial status %q, want %q", i, got, Go)
+		}
+	}
+
+	r.sendStop("rollout")
+	for i, ch := range []chan Status{ch1, ch2} {
+		if got := <-ch; got != Stop {
+			t.Errorf("TestSubscribeStop: subscriber %d: got status %q, want %q", i, got, Stop)
+		}
+		if _, ok := <-ch; ok {
+			t.Errorf("TestSubscribeStop: subscriber %d: channel was not closed after Stop", i)
+		}
+	}
+
+	// Cancelling after a Stop is what callers are told to do and must not panic.
+	cancel1()
+	cancel2()
+	if _, ok := r.subscribers["rollout"]; ok {
+		t.Errorf("TestSubscribeStop: subscribers for rollout were not removed")
+	}
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	r := testReader(map[string]Info{"rollout": {Name: "rollout", Status: Go}})
+
+	ch1, cancel1 := r.Subscribe("rollout")
+	_, cancel2 := r.Subscribe("rollout")
+	<-ch1
+
+	cancel2()
+	cancel2() // A second cancel does nothing.
+	if got := len(r.subscribers["rollout"]); got != 1 {
+		t.Fatalf("TestSubscribeCancel: got %d subscribers, want 1", got)
+	}
+
+	r.sendStop("rollout")
+	if got := <-ch1; got != Stop {
+		t.Errorf("TestSubscribeCancel: got status %q, want %q", got, Stop)
+	}
+	cancel1()
+}
+
+func TestSubscribeNotGo(t *testing.T) {
+	r := testReader(map[string]Info{"rollout": {Name: "rollout", Status: Stop}})
+
+	for _, name := range []string{"rollout", "missing"} {
+		ch, cancel := r.Subscribe(name)
+		if got := <-ch; got != Stop {
+			t.Errorf("TestSubscribeNotGo(%s): got status %q, want %q", name, got, Stop)
+		}
+		if _, ok := <-ch; ok {
+			t.Errorf("TestSubscribeNotGo(%s): channel was not closed", name)
+		}
+		cancel()
+	}
+}